@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"user-service/pkg/dto"
+	service "user-service/pkg/services"
+)
+
+// ImportPatients registers the patients in a CSV file and lists the rows that
+// were rejected; it exits with status 1 if any were.
+// Usage: app import-patients [--dry-run] [--batch-size N] FILE
+func ImportPatients(ctx context.Context, importService *service.ImportService, args []string) {
+	fs := flag.NewFlagSet("import-patients", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "check every row and report without importing anything")
+	batchSize := fs.Int("batch-size", 100, "rows written per transaction")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: app import-patients [--dry-run] [--batch-size N] FILE")
+		os.Exit(2)
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		slog.Error("cannot open import file", "error", err)
+		os.Exit(1)
+	}
+	defer f.Close()
+
+	res, err := importService.ImportPatients(ctx, f, &dto.PatientImportQueryDto{DryRun: *dryRun, BatchSize: *batchSize})
+	if err != nil {
+		slog.Error("patient import failed", "error", err)
+		os.Exit(1)
+	}
+
+	for _, row := range res.Errors {
+		reasons := make([]string, 0, len(row.Errors))
+		for _, fe := range row.Errors {
+			reasons = append(reasons, fe.Message.EN)
+		}
+		fmt.Printf("line %d (%s): %s\n", row.Line, row.HospitalID, strings.Join(reasons, "; "))
+	}
+	verb := "imported"
+	if *dryRun {
+		verb = "would import"
+	}
+	fmt.Printf(">>> %s %d of %d patients, %d rejected\n", verb, res.Imported, res.Total, res.Failed)
+	if res.Failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+
+	dbpkg "user-service/pkg/db"
+)
+
+// Migrate applies or rolls back the migrations embedded in the binary, so no
+// goose install is needed. Usage: app migrate up|down|redo|status
+func Migrate(ctx context.Context, sqlDB *sql.DB, args []string) {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: app migrate up|down|redo|status")
+		os.Exit(2)
+	}
+
+	var err error
+	switch args[0] {
+	case "up":
+		err = dbpkg.MigrateUp(ctx, sqlDB)
+	case "down":
+		err = dbpkg.MigrateDown(ctx, sqlDB)
+	case "redo":
+		err = dbpkg.MigrateRedo(ctx, sqlDB)
+	case "status":
+		err = printStatus(ctx, sqlDB)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown migrate command %q\n", args[0])
+		os.Exit(2)
+	}
+	if err != nil {
+		slog.Error("migrate "+args[0]+" failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+// MigrateCreate writes a new migration skeleton into the source tree. It does
+// not touch the database. Usage: app migrate create [--dir DIR] NAME [sql|go]
+func MigrateCreate(args []string) {
+	fs := flag.NewFlagSet("migrate create", flag.ExitOnError)
+	dir := fs.String("dir", "pkg/db/migrations", "migrations package directory")
+	_ = fs.Parse(args)
+
+	kind := "sql"
+	switch fs.NArg() {
+	case 1:
+	case 2:
+		kind = fs.Arg(1)
+	default:
+		fmt.Fprintln(os.Stderr, "usage: app migrate create [--dir DIR] NAME [sql|go]")
+		os.Exit(2)
+	}
+
+	if err := dbpkg.CreateMigration(*dir, fs.Arg(0), kind); err != nil {
+		slog.Error("migrate create failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+func printStatus(ctx context.Context, sqlDB *sql.DB) error {
+	statuses, err := dbpkg.Status(ctx, sqlDB)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "APPLIED AT\tMIGRATION")
+	for _, st := range statuses {
+		appliedAt := "pending"
+		if st.Applied {
+			appliedAt = st.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%s\t%s\n", appliedAt, st.Name)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	service "user-service/pkg/services"
+)
+
+// Purge hard-deletes soft-deleted rows older than the configured retention
+// window. Usage: app purge [--dry-run]
+func Purge(ctx context.Context, retentionService *service.RetentionService, args []string) {
+	fs := flag.NewFlagSet("purge", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "report what would be deleted without deleting it")
+	_ = fs.Parse(args)
+
+	res, err := retentionService.Purge(ctx, *dryRun)
+	if err != nil {
+		slog.Error("purge failed", "error", err)
+		os.Exit(1)
+	}
+
+	verb := "purged"
+	if *dryRun {
+		verb = "would purge"
+	}
+	fmt.Printf(">>> %s %d users, %d patients, %d doctors\n", verb, res.Users, res.Patients, res.Doctors)
+}
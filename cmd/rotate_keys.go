@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	service "user-service/pkg/services"
+)
+
+// RotateKeys re-encrypts patient data and profile history with the active
+// key-encryption key and encrypts rows written before encryption was enabled.
+// Usage: app rotate-keys [--batch-size N]
+func RotateKeys(ctx context.Context, keyRotationService *service.KeyRotationService, args []string) {
+	fs := flag.NewFlagSet("rotate-keys", flag.ExitOnError)
+	batchSize := fs.Int("batch-size", 500, "number of rows read per query")
+	_ = fs.Parse(args)
+
+	res, err := keyRotationService.Rotate(ctx, *batchSize)
+	if err != nil {
+		slog.Error("key rotation failed", "scanned", res.Scanned, "error", err)
+		os.Exit(1)
+	}
+	fmt.Printf(">>> scanned %d rows, re-encrypted %d\n", res.Scanned, res.Rotated)
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	service "user-service/pkg/services"
+)
+
+// Seed loads a data profile; rows that already exist are skipped, so it is
+// safe to run repeatedly. The demo profile needs DEMO_MODE.
+// Usage: app seed [--profile reference|demo]
+func Seed(ctx context.Context, seedService *service.SeedService, args []string) {
+	fs := flag.NewFlagSet("seed", flag.ExitOnError)
+	profile := fs.String("profile", service.SeedProfileReference, "data profile to load: reference or demo")
+	_ = fs.Parse(args)
+
+	res, err := seedService.Seed(ctx, *profile)
+	if err != nil {
+		slog.Error("seed failed", "profile", *profile, "error", err)
+		os.Exit(1)
+	}
+	fmt.Printf(">>> seeded %q: added %d healthcare entitlements, %d patients, %d doctors, %d admins\n",
+		*profile, res.Entitlements, res.Patients, res.Doctors, res.Admins)
+}
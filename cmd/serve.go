@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"user-service/pkg/app"
+)
+
+// Serve runs the HTTP server and the account erasure worker until ctx is
+// cancelled, then gives in-flight requests up to shutdownTimeout to finish.
+// Usage: app serve
+func Serve(ctx context.Context, application *app.App, port int, shutdownTimeout time.Duration) {
+	// Anonymize closed accounts once their retention period has passed
+	workerDone := make(chan struct{})
+	go func() {
+		defer close(workerDone)
+		application.AccountService.RunErasureWorker(ctx, time.Hour)
+	}()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("server listening", "port", port)
+		// listen on all interfaces for containerized envs
+		serverErr <- application.Fiber.Listen(fmt.Sprintf(":%d", port))
+	}()
+
+	select {
+	case err := <-serverErr:
+		slog.Error("server stopped", "error", err)
+		os.Exit(1)
+	case <-ctx.Done():
+	}
+
+	slog.Info("shutting down", "timeout", shutdownTimeout)
+	if err := application.Fiber.ShutdownWithTimeout(shutdownTimeout); err != nil {
+		slog.Error("server shutdown failed", "error", err)
+	}
+	<-workerDone
+}
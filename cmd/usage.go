@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+)
+
+// Usage lists the subcommands of the service binary.
+func Usage(w io.Writer) {
+	fmt.Fprint(w, `Usage: app [command] [flags]
+
+Commands:
+  serve                             run the HTTP server (the default)
+  migrate up|down|redo|status       apply, roll back or list the embedded migrations
+  migrate create NAME [sql|go]      write a new migration into pkg/db/migrations
+  seed [--profile reference|demo]   load reference data, or demo accounts with DEMO_MODE on
+  import-patients [--dry-run] FILE  register the patients in a CSV file
+  purge [--dry-run]                 hard-delete rows past the retention window
+  rotate-keys [--batch-size N]      re-encrypt patient data with the active key
+  config                            print the effective configuration, secrets redacted
+`)
+}
@@ -15,9 +15,14 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/user/v1/doctor/login": {
-            "post": {
-                "description": "Authenticate a doctor and return access token with cookie",
+        "/api/user/v1/admin/audit-log": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List audit log entries, newest first. Admins only.",
                 "consumes": [
                     "application/json"
                 ],
@@ -25,35 +30,152 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "doctors"
+                    "admin"
                 ],
-                "summary": "Login a doctor",
+                "summary": "Query the audit log",
                 "parameters": [
                     {
-                        "description": "Doctor login credentials",
-                        "name": "doctor",
-                        "in": "body",
-                        "required": true,
+                        "type": "string",
+                        "description": "Only entries by this user",
+                        "name": "actor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entries targeting this patient",
+                        "name": "patient_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entries for this action, e.g. patient.read",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entries at or after this RFC 3339 time",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entries before this RFC 3339 time",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 20, max 100)",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Audit log entries",
                         "schema": {
-                            "$ref": "#/definitions/dto.DoctorLoginRequestDto"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/dto.AuditEntryResponseDto"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
+                    },
+                    "400": {
+                        "description": "Invalid filter",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient permissions",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/v1/admin/audit-log/export": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Download matching audit log entries, oldest first, as JSON lines including their hashes. Admins only.",
+                "produces": [
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Export the audit log",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only entries by this user",
+                        "name": "actor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entries targeting this patient",
+                        "name": "patient_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entries for this action, e.g. patient.read",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entries at or after this RFC 3339 time",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entries before this RFC 3339 time",
+                        "name": "to",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Doctor logged in successfully",
+                        "description": "One dto.AuditEntryResponseDto per line",
                         "schema": {
-                            "$ref": "#/definitions/dto.DoctorLoginResponseDto"
+                            "type": "string"
                         }
                     },
                     "400": {
-                        "description": "Invalid request body",
+                        "description": "Invalid filter",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Invalid credentials",
+                    "403": {
+                        "description": "Insufficient permissions",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -61,9 +183,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/v1/doctors": {
+        "/api/user/v1/admin/audit-log/verify": {
             "get": {
-                "description": "Get all doctor profiles from the system",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Recompute the hash chain of the whole audit log and report the first entry that does not match. Admins only.",
                 "consumes": [
                     "application/json"
                 ],
@@ -71,29 +198,45 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "doctors"
+                    "admin"
                 ],
-                "summary": "Get all doctors",
+                "summary": "Verify the audit log",
                 "responses": {
                     "200": {
-                        "description": "Doctor profiles retrieved successfully",
+                        "description": "Verification result",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/user-service_pkg_dto.GetDoctorProfileResponseDto"
-                            }
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.AuditVerifyResponseDto"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
-                    "500": {
-                        "description": "Failed to get doctor profiles",
+                    "403": {
+                        "description": "Insufficient permissions",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Get multiple doctor profiles by their IDs",
+            }
+        },
+        "/api/user/v1/admin/deletion-requests": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List account deletion requests, newest first. Admins only.",
                 "consumes": [
                     "application/json"
                 ],
@@ -101,38 +244,59 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "doctors"
+                    "admin"
                 ],
-                "summary": "Get doctors by IDs",
+                "summary": "List account deletion requests",
                 "parameters": [
                     {
-                        "description": "Doctor IDs",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.GetDoctorsByIDsRequestDto"
-                        }
+                        "type": "string",
+                        "description": "Filter by status (pending, approved, rejected)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 20, max 100)",
+                        "name": "page_size",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Doctor profiles retrieved successfully",
+                        "description": "Deletion requests",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/user-service_pkg_dto.GetDoctorProfileResponseDto"
-                            }
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/dto.AccountDeletionRequestResponseDto"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
-                    "404": {
-                        "description": "Doctors not found",
+                    "400": {
+                        "description": "Invalid status filter",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Failed to get doctor profiles",
+                    "403": {
+                        "description": "Insufficient permissions",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -140,14 +304,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/v1/patient": {
-            "patch": {
+        "/api/user/v1/admin/deletion-requests/anonymize": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Update the profile information of the authenticated patient",
+                "description": "Run the erasure job now instead of waiting for the background worker. Admins only.",
                 "consumes": [
                     "application/json"
                 ],
@@ -155,41 +319,36 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "patients"
-                ],
-                "summary": "Update patient profile",
-                "parameters": [
-                    {
-                        "description": "Patient profile update data",
-                        "name": "patient",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.UpdatePatientProfileRequestDto"
-                        }
-                    }
+                    "admin"
                 ],
+                "summary": "Anonymize closed accounts past retention",
                 "responses": {
                     "200": {
-                        "description": "Profile updated successfully",
-                        "schema": {
-                            "$ref": "#/definitions/dto.UpdatePatientProfileResponseDto"
-                        }
-                    },
-                    "400": {
-                        "description": "Invalid request body or user not found",
+                        "description": "Number of accounts anonymized",
                         "schema": {
-                            "$ref": "#/definitions/response.ErrorResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.AnonymizeAccountsResponseDto"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized - Invalid or missing token",
+                    "403": {
+                        "description": "Insufficient permissions",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Failed to update user profile",
+                        "description": "Anonymization failed",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -197,9 +356,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/v1/patient/login": {
+        "/api/user/v1/admin/deletion-requests/{id}/approve": {
             "post": {
-                "description": "Authenticate a patient and return access token",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Approve a pending request and soft-delete the patient's account. Admins only.",
                 "consumes": [
                     "application/json"
                 ],
@@ -207,35 +371,54 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "patients"
+                    "admin"
                 ],
-                "summary": "Login a patient",
+                "summary": "Approve an account deletion request",
                 "parameters": [
                     {
-                        "description": "Patient login credentials",
-                        "name": "patient",
+                        "type": "string",
+                        "description": "Deletion request ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Optional review note",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.PatientLoginRequestDto"
+                            "$ref": "#/definitions/dto.ReviewAccountDeletionRequestDto"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Patient logged in successfully",
+                        "description": "Request approved",
                         "schema": {
-                            "$ref": "#/definitions/dto.PatientLoginResponseDto"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.AccountDeletionRequestResponseDto"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
-                    "400": {
-                        "description": "Invalid request body",
+                    "404": {
+                        "description": "Deletion request not found",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Invalid credentials",
+                    "409": {
+                        "description": "Request already reviewed",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -243,14 +426,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/v1/patient/me": {
-            "get": {
+        "/api/user/v1/admin/deletion-requests/{id}/reject": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Get the profile information of the authenticated patient",
+                "description": "Reject a pending request; the account stays active. Admins only.",
                 "consumes": [
                     "application/json"
                 ],
@@ -258,24 +441,54 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "patients"
+                    "admin"
+                ],
+                "summary": "Reject an account deletion request",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Deletion request ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Optional review note",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReviewAccountDeletionRequestDto"
+                        }
+                    }
                 ],
-                "summary": "Get patient profile",
                 "responses": {
                     "200": {
-                        "description": "Profile retrieved successfully",
+                        "description": "Request rejected",
                         "schema": {
-                            "$ref": "#/definitions/dto.GetProfileResponseDto"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.AccountDeletionRequestResponseDto"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized - Invalid or missing token",
+                    "404": {
+                        "description": "Deletion request not found",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Failed to get user profile",
+                    "409": {
+                        "description": "Request already reviewed",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -283,9 +496,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/v1/patient/register": {
+        "/api/user/v1/admin/login": {
             "post": {
-                "description": "Register a new patient in the system",
+                "description": "Authenticate an admin and return access token with cookie",
                 "consumes": [
                     "application/json"
                 ],
@@ -293,25 +506,37 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "patients"
+                    "admin"
                 ],
-                "summary": "Register a new patient",
+                "summary": "Login an admin",
                 "parameters": [
                     {
-                        "description": "Patient registration data",
-                        "name": "patient",
+                        "description": "Admin login credentials",
+                        "name": "admin",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.PatientRegisterPatientRequestDto"
+                            "$ref": "#/definitions/dto.AdminLoginRequestDto"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Patient registered successfully",
+                    "200": {
+                        "description": "Admin logged in successfully",
                         "schema": {
-                            "$ref": "#/definitions/dto.PatientRegisterResponseDto"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.AdminLoginResponseDto"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
@@ -320,8 +545,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Failed to register user",
+                    "401": {
+                        "description": "Invalid credentials",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
@@ -329,9 +554,87 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/v1/patients": {
+        "/api/user/v1/admin/patients/import": {
             "post": {
-                "description": "Get multiple patient profiles by their IDs",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Register patients in bulk from a CSV file, sent as the \"file\" field of a multipart form or as a text/csv body. The header names the columns: hospital_id, first_name, last_name and gender are required; phone_number, birth_date (YYYY-MM-DD), id_card_number, blood_type, healthcare_entitlements (separated by \";\"), address, allergies, emergency_contact and password are optional. Rows are checked like a registration; rows that fail are reported by line and skipped. Patients imported without a password cannot sign in until an admin sets one with PUT /api/user/v1/admin/patients/{id}/password. Admins only.",
+                "consumes": [
+                    "multipart/form-data",
+                    "text/csv"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Import patients from CSV",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "CSV file",
+                        "name": "file",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Check every row and report the outcome without importing anything",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Rows written per transaction (default 100, max 1000)",
+                        "name": "batch_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Import report",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.PatientImportResponseDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Unreadable file, unknown or missing column, or invalid batch size",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient permissions",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/v1/admin/patients/{id}/password": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Set or reset a patient's password, for patients imported without one or who have lost theirs. Admins only.",
                 "consumes": [
                     "application/json"
                 ],
@@ -339,258 +642,2519 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "patients"
+                    "admin"
                 ],
-                "summary": "Get patients by IDs",
+                "summary": "Set a patient's password",
                 "parameters": [
                     {
-                        "description": "Patient IDs",
+                        "type": "string",
+                        "description": "Patient ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New password",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.GetPatientsByIDsRequestDto"
+                            "$ref": "#/definitions/dto.SetPatientPasswordRequestDto"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Patient profiles retrieved successfully",
+                        "description": "Password set",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/dto.GetProfileResponseDto"
-                            }
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.SetPatientPasswordResponseDto"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
-                    "404": {
-                        "description": "Patients not found",
+                    "400": {
+                        "description": "Invalid patient ID or password",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Failed to get patient profiles",
+                    "403": {
+                        "description": "Insufficient permissions",
                         "schema": {
                             "$ref": "#/definitions/response.ErrorResponse"
                         }
-                    }
+                    },
+                    "404": {
+                        "description": "Patient not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/v1/admin/users/deleted": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List soft-deleted users, most recently deleted first. Admins only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List soft-deleted users",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by role (patient, doctor, admin)",
+                        "name": "role",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 20, max 100)",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Soft-deleted users",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/dto.DeletedUserResponseDto"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid role filter",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient permissions",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/v1/admin/users/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Restore a user and their patient or doctor row in one transaction. Admins only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Restore a soft-deleted user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User restored successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.RestoreUserResponseDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "User is not deleted or was closed at the patient's request",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/v1/doctor/login": {
+            "post": {
+                "description": "Authenticate a doctor and return access token with cookie",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "doctors"
+                ],
+                "summary": "Login a doctor",
+                "parameters": [
+                    {
+                        "description": "Doctor login credentials",
+                        "name": "doctor",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.DoctorLoginRequestDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Doctor logged in successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.DoctorLoginResponseDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/v1/doctors": {
+            "get": {
+                "description": "Get all doctor profiles from the system",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "doctors"
+                ],
+                "summary": "Get all doctors",
+                "responses": {
+                    "200": {
+                        "description": "Doctor profiles retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/user-service_pkg_dto.GetDoctorProfileResponseDto"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get doctor profiles",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Get multiple doctor profiles by their IDs",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "doctors"
+                ],
+                "summary": "Get doctors by IDs",
+                "parameters": [
+                    {
+                        "description": "Doctor IDs",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.GetDoctorsByIDsRequestDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Doctor profiles retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/user-service_pkg_dto.GetDoctorProfileResponseDto"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Doctors not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get doctor profiles",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/v1/doctors/search": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Fuzzy search doctors by name, username, specialty or bio, best match first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "doctors"
+                ],
+                "summary": "Search doctors",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search term (at least 2 characters)",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of results (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Matching doctor profiles",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/user-service_pkg_dto.GetDoctorProfileResponseDto"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid search query",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing token",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to search doctors",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/v1/fhir/Patient": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Search patients, returning a FHIR R4 searchset Bundle. identifier is [system|]value, where the system is the Thai national ID system or the configured hospital number system; name matches the start of a first or last name. Doctors and admins only.",
+                "produces": [
+                    "application/fhir+json"
+                ],
+                "tags": [
+                    "fhir"
+                ],
+                "summary": "Search FHIR Patients",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Patient IDs, comma-separated",
+                        "name": "_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Hospital number or national ID, as [system|]value",
+                        "name": "identifier",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of a first or last name",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 20, max 100)",
+                        "name": "_count",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of matches to skip",
+                        "name": "_offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Matching patients",
+                        "schema": {
+                            "$ref": "#/definitions/fhir.Bundle"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid search parameters",
+                        "schema": {
+                            "$ref": "#/definitions/fhir.OperationOutcome"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/fhir.OperationOutcome"
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient permissions",
+                        "schema": {
+                            "$ref": "#/definitions/fhir.OperationOutcome"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/v1/fhir/Patient/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a patient as a FHIR R4 Patient, identified by hospital number and Thai national ID, with the emergency contact as a contact. Doctors and admins only.",
+                "produces": [
+                    "application/fhir+json"
+                ],
+                "tags": [
+                    "fhir"
+                ],
+                "summary": "Read a FHIR Patient",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Patient ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The patient",
+                        "schema": {
+                            "$ref": "#/definitions/fhir.Patient"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/fhir.OperationOutcome"
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient permissions",
+                        "schema": {
+                            "$ref": "#/definitions/fhir.OperationOutcome"
+                        }
+                    },
+                    "404": {
+                        "description": "Patient not found",
+                        "schema": {
+                            "$ref": "#/definitions/fhir.OperationOutcome"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/v1/fhir/Practitioner": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Search doctors, returning a FHIR R4 searchset Bundle. identifier is [system|]username, where the system is the configured practitioner system; name matches the start of a first or last name.",
+                "produces": [
+                    "application/fhir+json"
+                ],
+                "tags": [
+                    "fhir"
+                ],
+                "summary": "Search FHIR Practitioners",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Doctor IDs, comma-separated",
+                        "name": "_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Username, as [system|]value",
+                        "name": "identifier",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of a first or last name",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 20, max 100)",
+                        "name": "_count",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of matches to skip",
+                        "name": "_offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Matching doctors",
+                        "schema": {
+                            "$ref": "#/definitions/fhir.Bundle"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid search parameters",
+                        "schema": {
+                            "$ref": "#/definitions/fhir.OperationOutcome"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/fhir.OperationOutcome"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/v1/fhir/Practitioner/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a doctor as a FHIR R4 Practitioner, identified by username.",
+                "produces": [
+                    "application/fhir+json"
+                ],
+                "tags": [
+                    "fhir"
+                ],
+                "summary": "Read a FHIR Practitioner",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Doctor ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The doctor",
+                        "schema": {
+                            "$ref": "#/definitions/fhir.Practitioner"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/fhir.OperationOutcome"
+                        }
+                    },
+                    "404": {
+                        "description": "Doctor not found",
+                        "schema": {
+                            "$ref": "#/definitions/fhir.OperationOutcome"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/v1/fhir/PractitionerRole": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Search doctors' roles, returning a FHIR R4 searchset Bundle. Roles have no identifier or name of their own, so those parameters are rejected.",
+                "produces": [
+                    "application/fhir+json"
+                ],
+                "tags": [
+                    "fhir"
+                ],
+                "summary": "Search FHIR PractitionerRoles",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Role IDs, comma-separated",
+                        "name": "_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Practitioner references or IDs, comma-separated",
+                        "name": "practitioner",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 20, max 100)",
+                        "name": "_count",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of matches to skip",
+                        "name": "_offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Matching roles",
+                        "schema": {
+                            "$ref": "#/definitions/fhir.Bundle"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid search parameters, or identifier or name",
+                        "schema": {
+                            "$ref": "#/definitions/fhir.OperationOutcome"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/fhir.OperationOutcome"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/v1/fhir/PractitionerRole/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a doctor's role and specialty as a FHIR R4 PractitionerRole. It has the ID of the doctor's Practitioner.",
+                "produces": [
+                    "application/fhir+json"
+                ],
+                "tags": [
+                    "fhir"
+                ],
+                "summary": "Read a FHIR PractitionerRole",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Doctor ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The doctor's role",
+                        "schema": {
+                            "$ref": "#/definitions/fhir.PractitionerRole"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/fhir.OperationOutcome"
+                        }
+                    },
+                    "404": {
+                        "description": "Doctor not found",
+                        "schema": {
+                            "$ref": "#/definitions/fhir.OperationOutcome"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/v1/patient": {
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Update the profile information of the authenticated patient",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "patients"
+                ],
+                "summary": "Update patient profile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ETag of the profile as last read",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Patient profile update data",
+                        "name": "patient",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdatePatientProfileRequestDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Profile updated successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.UpdatePatientProfileResponseDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the updated profile"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body or user not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing token",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Profile was modified since the If-Match ETag was read",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header is missing",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update user profile",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/v1/patient/deletion-request": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Ask for the authenticated patient's account to be closed. An admin must approve the request; personal data is anonymized after the retention period.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "patients"
+                ],
+                "summary": "Request account deletion",
+                "parameters": [
+                    {
+                        "description": "Optional reason",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RequestAccountDeletionRequestDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Deletion requested",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.AccountDeletionRequestResponseDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing token",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Deletion already requested",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/v1/patient/login": {
+            "post": {
+                "description": "Authenticate a patient and return access token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "patients"
+                ],
+                "summary": "Login a patient",
+                "parameters": [
+                    {
+                        "description": "Patient login credentials",
+                        "name": "patient",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.PatientLoginRequestDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Patient logged in successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.PatientLoginResponseDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/v1/patient/me": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the profile information of the authenticated patient",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "patients"
+                ],
+                "summary": "Get patient profile",
+                "responses": {
+                    "200": {
+                        "description": "Profile retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.GetProfileResponseDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the profile, to send as If-Match when updating it"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing token",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get user profile",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/v1/patient/me/export": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a copy of everything the service holds about the authenticated patient: account, patient record, healthcare entitlements, profile changes, deletion requests and the audit entries about them. With format=zip the export is a download holding data.json and a readable summary.txt. The service keeps no consent records or sessions.",
+                "produces": [
+                    "application/json",
+                    "application/zip"
+                ],
+                "tags": [
+                    "patients"
+                ],
+                "summary": "Export my data",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "json (default) or zip",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The patient's data",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.DataExportDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid format",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing token",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient permissions",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/v1/patient/register": {
+            "post": {
+                "description": "Register a new patient in the system",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "patients"
+                ],
+                "summary": "Register a new patient",
+                "parameters": [
+                    {
+                        "description": "Patient registration data",
+                        "name": "patient",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.PatientRegisterPatientRequestDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Patient registered successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.PatientRegisterResponseDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to register user",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/v1/patients": {
+            "post": {
+                "description": "Get multiple patient profiles by their IDs",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "patients"
+                ],
+                "summary": "Get patients by IDs",
+                "parameters": [
+                    {
+                        "description": "Patient IDs",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.GetPatientsByIDsRequestDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Patient profiles retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/dto.GetProfileResponseDto"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Patients not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get patient profiles",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/v1/patients/search": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Fuzzy search patients by first/last name or hospital ID, best match first. Doctors and admins only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "patients"
+                ],
+                "summary": "Search patients",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search term (at least 2 characters)",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of results (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Matching patient profiles",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/dto.GetProfileResponseDto"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid search query",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing token",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient permissions",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to search patients",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/v1/patients/{id}/history": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List the changes made to a patient's profile, one entry per field, newest first. Doctors and admins may read any patient's history, patients only their own.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "patients"
+                ],
+                "summary": "Get patient profile history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Patient ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 20, max 100)",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Profile changes",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/dto.ProfileChangeResponseDto"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid patient ID",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing token",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "History of another patient",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Patient not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "description": "Reports that the process is up. It checks no dependencies.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Liveness probe",
+                "responses": {
+                    "200": {
+                        "description": "Process is up",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.HealthResponseDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Reports whether the service can serve traffic: the database answers, every migration is applied and the encryption keys work.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Readiness probe",
+                "responses": {
+                    "200": {
+                        "description": "Ready",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.ReadinessResponseDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "503": {
+                        "description": "Not ready; details name the failed checks",
+                        "schema": {
+                            "$ref": "#/definitions/response.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/version": {
+            "get": {
+                "description": "Reports the version and commit of the running binary and the migration version of the database.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Build and schema version",
+                "responses": {
+                    "200": {
+                        "description": "Version information",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.BaseResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.VersionResponseDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "dto.AccountDeletionRequestResponseDto": {
+            "type": "object",
+            "properties": {
+                "anonymized_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "requested_at": {
+                    "type": "string"
+                },
+                "review_note": {
+                    "type": "string"
+                },
+                "reviewed_at": {
+                    "type": "string"
+                },
+                "reviewed_by": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AdminLoginRequestDto": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AdminLoginResponseDto": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AnonymizeAccountsResponseDto": {
+            "type": "object",
+            "properties": {
+                "anonymized": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.AuditEntryResponseDto": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "actor_id": {
+                    "type": "string"
+                },
+                "actor_role": {
+                    "type": "string"
+                },
+                "hash": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "occurred_at": {
+                    "type": "string"
+                },
+                "outcome": {
+                    "type": "string"
+                },
+                "prev_hash": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "target_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dto.AuditVerifyResponseDto": {
+            "type": "object",
+            "properties": {
+                "broken_at": {
+                    "description": "BrokenAt is the ID of the first entry whose hash does not match.",
+                    "type": "integer"
+                },
+                "checked": {
+                    "type": "integer"
+                },
+                "valid": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "dto.DataExportAuditEntryDto": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "actor_role": {
+                    "type": "string"
+                },
+                "occurred_at": {
+                    "type": "string"
+                },
+                "outcome": {
+                    "type": "string"
+                },
+                "target_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dto.DataExportDto": {
+            "type": "object",
+            "properties": {
+                "audit_entries": {
+                    "description": "AuditEntries are the recorded requests made by the patient or that\nread or changed their data, oldest first.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.DataExportAuditEntryDto"
+                    }
+                },
+                "deletion_requests": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.AccountDeletionRequestResponseDto"
+                    }
+                },
+                "generated_at": {
+                    "type": "string"
+                },
+                "healthcare_entitlements": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "patient": {
+                    "$ref": "#/definitions/dto.DataExportPatientDto"
+                },
+                "profile_changes": {
+                    "description": "ProfileChanges and DeletionRequests name staff by role only: actor_id\nis set only for the patient's own changes, and reviewed_by never.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ProfileChangeResponseDto"
+                    }
+                },
+                "user": {
+                    "$ref": "#/definitions/dto.DataExportUserDto"
+                }
+            }
+        },
+        "dto.DataExportPatientDto": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "allergies": {
+                    "type": "string"
+                },
+                "birth_date": {
+                    "type": "string"
+                },
+                "blood_type": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "emergency_contact": {
+                    "type": "string"
+                },
+                "hospital_id": {
+                    "type": "string"
+                },
+                "id_card_number": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.DataExportUserDto": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "gender": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "phone_number": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.DeletedUserResponseDto": {
+            "type": "object",
+            "properties": {
+                "deleted_at": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.DoctorLoginRequestDto": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "minLength": 8
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.DoctorLoginResponseDto": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.GetDoctorsByIDsRequestDto": {
+            "type": "object",
+            "required": [
+                "doctor_ids"
+            ],
+            "properties": {
+                "doctor_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dto.GetPatientsByIDsRequestDto": {
+            "type": "object",
+            "required": [
+                "patient_ids"
+            ],
+            "properties": {
+                "patient_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dto.GetProfileResponseDto": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "allergies": {
+                    "type": "string"
+                },
+                "birth_date": {
+                    "type": "string"
+                },
+                "blood_type": {
+                    "type": "string"
+                },
+                "emergency_contact": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "gender": {
+                    "type": "string"
+                },
+                "hospital_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "id_card_number": {
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "phone_number": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.HealthResponseDto": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.PatientImportResponseDto": {
+            "type": "object",
+            "properties": {
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.PatientImportRowErrorDto"
+                    }
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "imported": {
+                    "description": "Imported counts the patients created, or that would be created in a\ndry run.",
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.PatientImportRowErrorDto": {
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/validation.FieldError"
+                    }
+                },
+                "hospital_id": {
+                    "type": "string"
+                },
+                "line": {
+                    "description": "Line is the row's line number in the file; the header is line 1.",
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.PatientLoginRequestDto": {
+            "type": "object",
+            "required": [
+                "hospital_id",
+                "password"
+            ],
+            "properties": {
+                "hospital_id": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.PatientLoginResponseDto": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.PatientRegisterPatientRequestDto": {
+            "type": "object",
+            "required": [
+                "first_name",
+                "gender",
+                "hospital_id",
+                "last_name",
+                "password"
+            ],
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "allergies": {
+                    "type": "string"
+                },
+                "birth_date": {
+                    "type": "string"
+                },
+                "blood_type": {
+                    "type": "string"
+                },
+                "emergency_contact": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "gender": {
+                    "type": "string",
+                    "enum": [
+                        "male",
+                        "female",
+                        "other"
+                    ]
+                },
+                "hospital_id": {
+                    "description": "Patient specific fields",
+                    "type": "string"
+                },
+                "id_card_number": {
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string",
+                    "minLength": 6
+                },
+                "phone_number": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.PatientRegisterResponseDto": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ProfileChangeResponseDto": {
+            "type": "object",
+            "properties": {
+                "actor_id": {
+                    "type": "string"
+                },
+                "actor_role": {
+                    "type": "string"
+                },
+                "changed_at": {
+                    "type": "string"
+                },
+                "field": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "new_value": {
+                    "type": "string"
+                },
+                "old_value": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ReadinessResponseDto": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.RequestAccountDeletionRequestDto": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "maxLength": 1000
+                }
+            }
+        },
+        "dto.RestoreUserResponseDto": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ReviewAccountDeletionRequestDto": {
+            "type": "object",
+            "properties": {
+                "note": {
+                    "type": "string",
+                    "maxLength": 1000
+                }
+            }
+        },
+        "dto.SetPatientPasswordRequestDto": {
+            "type": "object",
+            "required": [
+                "password"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "minLength": 6
+                }
+            }
+        },
+        "dto.SetPatientPasswordResponseDto": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.UpdatePatientProfileRequestDto": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "allergies": {
+                    "type": "string"
+                },
+                "birth_date": {
+                    "description": "Patient specific fields",
+                    "type": "string"
+                },
+                "blood_type": {
+                    "type": "string"
+                },
+                "emergency_contact": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "id_card_number": {
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "phone_number": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.UpdatePatientProfileResponseDto": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.VersionResponseDto": {
+            "type": "object",
+            "properties": {
+                "build_time": {
+                    "type": "string"
+                },
+                "commit": {
+                    "type": "string"
+                },
+                "go_version": {
+                    "type": "string"
+                },
+                "schema_version": {
+                    "type": "integer"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "fhir.Address": {
+            "type": "object",
+            "properties": {
+                "text": {
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string"
                 }
             }
-        }
-    },
-    "definitions": {
-        "dto.DoctorLoginRequestDto": {
+        },
+        "fhir.Bundle": {
             "type": "object",
-            "required": [
-                "password",
-                "username"
-            ],
             "properties": {
-                "password": {
-                    "type": "string",
-                    "minLength": 8
+                "entry": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/fhir.BundleEntry"
+                    }
                 },
-                "username": {
+                "link": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/fhir.BundleLink"
+                    }
+                },
+                "resourceType": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "type": {
                     "type": "string"
                 }
             }
         },
-        "dto.DoctorLoginResponseDto": {
+        "fhir.BundleEntry": {
             "type": "object",
             "properties": {
-                "access_token": {
+                "fullUrl": {
                     "type": "string"
+                },
+                "resource": {},
+                "search": {
+                    "$ref": "#/definitions/fhir.BundleEntrySearch"
                 }
             }
         },
-        "dto.GetDoctorsByIDsRequestDto": {
+        "fhir.BundleEntrySearch": {
             "type": "object",
-            "required": [
-                "doctor_ids"
-            ],
             "properties": {
-                "doctor_ids": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "mode": {
+                    "type": "string"
                 }
             }
         },
-        "dto.GetPatientsByIDsRequestDto": {
+        "fhir.BundleLink": {
             "type": "object",
-            "required": [
-                "patient_ids"
-            ],
             "properties": {
-                "patient_ids": {
+                "relation": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "fhir.CodeableConcept": {
+            "type": "object",
+            "properties": {
+                "coding": {
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/fhir.Coding"
                     }
+                },
+                "text": {
+                    "type": "string"
                 }
             }
         },
-        "dto.GetProfileResponseDto": {
+        "fhir.Coding": {
             "type": "object",
             "properties": {
-                "address": {
+                "code": {
                     "type": "string"
                 },
-                "allergies": {
+                "display": {
                     "type": "string"
                 },
-                "birth_date": {
+                "system": {
                     "type": "string"
-                },
-                "blood_type": {
+                }
+            }
+        },
+        "fhir.ContactPoint": {
+            "type": "object",
+            "properties": {
+                "system": {
                     "type": "string"
                 },
-                "emergency_contact": {
+                "use": {
                     "type": "string"
                 },
-                "first_name": {
+                "value": {
                     "type": "string"
-                },
-                "gender": {
+                }
+            }
+        },
+        "fhir.HumanName": {
+            "type": "object",
+            "properties": {
+                "family": {
                     "type": "string"
                 },
-                "hospital_id": {
-                    "type": "string"
+                "given": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "id": {
+                "text": {
                     "type": "string"
                 },
-                "id_card_number": {
+                "use": {
+                    "type": "string"
+                }
+            }
+        },
+        "fhir.Identifier": {
+            "type": "object",
+            "properties": {
+                "system": {
                     "type": "string"
                 },
-                "last_name": {
+                "use": {
                     "type": "string"
                 },
-                "phone_number": {
+                "value": {
                     "type": "string"
                 }
             }
         },
-        "dto.PatientLoginRequestDto": {
+        "fhir.Meta": {
             "type": "object",
             "properties": {
-                "hospital_id": {
+                "lastUpdated": {
                     "type": "string"
                 },
-                "password": {
+                "versionId": {
                     "type": "string"
                 }
             }
         },
-        "dto.PatientLoginResponseDto": {
+        "fhir.OperationOutcome": {
             "type": "object",
             "properties": {
-                "access_token": {
+                "issue": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/fhir.OperationOutcomeIssue"
+                    }
+                },
+                "resourceType": {
                     "type": "string"
                 }
             }
         },
-        "dto.PatientRegisterPatientRequestDto": {
+        "fhir.OperationOutcomeIssue": {
             "type": "object",
-            "required": [
-                "first_name",
-                "gender",
-                "hospital_id",
-                "last_name",
-                "password"
-            ],
             "properties": {
-                "address": {
+                "code": {
                     "type": "string"
                 },
-                "allergies": {
+                "diagnostics": {
                     "type": "string"
                 },
-                "birth_date": {
+                "severity": {
                     "type": "string"
+                }
+            }
+        },
+        "fhir.Patient": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
                 },
-                "blood_type": {
-                    "type": "string"
+                "address": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/fhir.Address"
+                    }
                 },
-                "emergency_contact": {
+                "birthDate": {
                     "type": "string"
                 },
-                "first_name": {
-                    "type": "string"
+                "contact": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/fhir.PatientContact"
+                    }
                 },
                 "gender": {
-                    "type": "string",
-                    "enum": [
-                        "male",
-                        "female",
-                        "other"
-                    ]
-                },
-                "hospital_id": {
-                    "description": "Patient specific fields",
                     "type": "string"
                 },
-                "id_card_number": {
+                "id": {
                     "type": "string"
                 },
-                "last_name": {
-                    "type": "string"
+                "identifier": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/fhir.Identifier"
+                    }
                 },
-                "password": {
-                    "type": "string",
-                    "minLength": 6
+                "meta": {
+                    "$ref": "#/definitions/fhir.Meta"
                 },
-                "phone_number": {
+                "name": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/fhir.HumanName"
+                    }
+                },
+                "resourceType": {
                     "type": "string"
+                },
+                "telecom": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/fhir.ContactPoint"
+                    }
                 }
             }
         },
-        "dto.PatientRegisterResponseDto": {
+        "fhir.PatientContact": {
             "type": "object",
             "properties": {
-                "message": {
-                    "type": "string"
+                "name": {
+                    "$ref": "#/definitions/fhir.HumanName"
+                },
+                "relationship": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/fhir.CodeableConcept"
+                    }
                 }
             }
         },
-        "dto.UpdatePatientProfileRequestDto": {
+        "fhir.Practitioner": {
             "type": "object",
             "properties": {
-                "address": {
-                    "type": "string"
+                "active": {
+                    "type": "boolean"
                 },
-                "allergies": {
+                "gender": {
                     "type": "string"
                 },
-                "birth_date": {
-                    "description": "Patient specific fields",
+                "id": {
                     "type": "string"
                 },
-                "blood_type": {
-                    "type": "string"
+                "identifier": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/fhir.Identifier"
+                    }
                 },
-                "emergency_contact": {
+                "meta": {
+                    "$ref": "#/definitions/fhir.Meta"
+                },
+                "name": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/fhir.HumanName"
+                    }
+                },
+                "resourceType": {
                     "type": "string"
                 },
-                "first_name": {
+                "telecom": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/fhir.ContactPoint"
+                    }
+                }
+            }
+        },
+        "fhir.PractitionerRole": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "code": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/fhir.CodeableConcept"
+                    }
+                },
+                "id": {
                     "type": "string"
                 },
-                "id_card_number": {
+                "meta": {
+                    "$ref": "#/definitions/fhir.Meta"
+                },
+                "practitioner": {
+                    "$ref": "#/definitions/fhir.Reference"
+                },
+                "resourceType": {
                     "type": "string"
                 },
-                "last_name": {
+                "specialty": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/fhir.CodeableConcept"
+                    }
+                }
+            }
+        },
+        "fhir.Reference": {
+            "type": "object",
+            "properties": {
+                "display": {
                     "type": "string"
                 },
-                "phone_number": {
+                "reference": {
                     "type": "string"
                 }
             }
         },
-        "dto.UpdatePatientProfileResponseDto": {
+        "response.BaseResponse": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "error": {
+                    "$ref": "#/definitions/response.ErrorBody"
+                },
+                "meta": {
+                    "$ref": "#/definitions/response.Meta"
+                }
+            }
+        },
+        "response.ErrorBody": {
             "type": "object",
             "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "details": {},
                 "message": {
                     "type": "string"
                 }
@@ -600,10 +3164,38 @@ const docTemplate = `{
             "type": "object",
             "properties": {
                 "error": {
+                    "$ref": "#/definitions/response.ErrorBody"
+                },
+                "meta": {
+                    "$ref": "#/definitions/response.Meta"
+                }
+            }
+        },
+        "response.Meta": {
+            "type": "object",
+            "properties": {
+                "pagination": {
+                    "$ref": "#/definitions/response.Pagination"
+                },
+                "request_id": {
                     "type": "string"
                 }
             }
         },
+        "response.Pagination": {
+            "type": "object",
+            "properties": {
+                "page": {
+                    "type": "integer"
+                },
+                "page_size": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
         "user-service_pkg_dto.GetDoctorProfileResponseDto": {
             "type": "object",
             "properties": {
@@ -635,6 +3227,34 @@ const docTemplate = `{
                     "type": "integer"
                 }
             }
+        },
+        "validation.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "$ref": "#/definitions/validation.Message"
+                },
+                "param": {
+                    "type": "string"
+                },
+                "rule": {
+                    "type": "string"
+                }
+            }
+        },
+        "validation.Message": {
+            "type": "object",
+            "properties": {
+                "en": {
+                    "type": "string"
+                },
+                "th": {
+                    "type": "string"
+                }
+            }
         }
     },
     "securityDefinitions": {
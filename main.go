@@ -1,49 +1,27 @@
 package main
 
 import (
-	"bytes"
-	"database/sql"
-	"embed"
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
-	"log"
+	"log/slog"
 	"os"
-	"reflect"
+	"os/signal"
+	"syscall"
+	"time"
 
-	// "user-service/cmd"
-	"user-service/pkg/clients"
+	"user-service/cmd"
+	"user-service/pkg/app"
 	"user-service/pkg/config"
 	dbpkg "user-service/pkg/db"
-	"user-service/pkg/handlers"
-	"user-service/pkg/jwt"
+	"user-service/pkg/db/migrations"
+	"user-service/pkg/encryption"
+	"user-service/pkg/logging"
+	"user-service/pkg/metrics"
 	"user-service/pkg/repository"
-	"user-service/pkg/routes"
 	service "user-service/pkg/services"
-
-	"github.com/go-playground/validator/v10"
-	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/pressly/goose/v3"
+	"user-service/pkg/tracing"
 )
 
-//go:embed pkg/db/migrations/*.sql
-var migrationsFS embed.FS
-
-// migrateUp applies all up migrations using goose with embedded FS.
-// Set env MIGRATE_ON_START=true (default) to run on startup.
-func migrateUp(sqlDB *sql.DB) error {
-	goose.SetBaseFS(migrationsFS)
-	if err := goose.SetDialect("postgres"); err != nil {
-		return fmt.Errorf("goose set dialect: %w", err)
-	}
-	// directory path is relative to the root of the embedded FS
-	if err := goose.Up(sqlDB, "pkg/db/migrations"); err != nil {
-		return fmt.Errorf("goose up: %w", err)
-	}
-	return nil
-}
-
 // @title User API
 // @description This is a sample server for a user API.
 // @version 1.0
@@ -56,80 +34,175 @@ func migrateUp(sqlDB *sql.DB) error {
 // @description Type "Bearer" followed by a space and JWT token.
 func main() {
 	os.Setenv("TZ", "Asia/Bangkok")
-	config.LoadConfig()
-
-	gormDB := dbpkg.Open(dbpkg.Config{
-		Host:     config.Get("DB_HOST", "localhost"),
-		Port:     config.GetInt("DB_PORT", 5432),
-		User:     config.Get("DB_USER", "user"),
-		Password: config.Get("DB_PASSWORD", "password"),
-		Dbname:   config.Get("DB_NAME", "userdb"),
-		Sslmode:  config.Get("DB_SSLMODE", "disable"),
-	})
 
-	sqlDB, err := gormDB.DB()
+	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("cannot get *sql.DB from gorm: %v", err)
+		fatal("cannot load configuration", err)
+	}
+
+	command, args := "serve", []string(nil)
+	if len(os.Args) > 1 {
+		command, args = os.Args[1], os.Args[2:]
 	}
-	// cmd.InitCmd()
+	switch command {
+	case "config":
+		fmt.Print(cfg.Redacted())
+		return
+	case "help", "-h", "--help":
+		cmd.Usage(os.Stdout)
+		return
+	case "serve", "migrate", "seed", "import-patients", "purge", "rotate-keys":
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", command)
+		cmd.Usage(os.Stderr)
+		os.Exit(2)
+	}
+
+	// SIGTERM (from the orchestrator) or Ctrl-C cancels ctx: startup stops
+	// retrying, and a running server drains in-flight requests and exits. A
+	// second signal kills the process.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	context.AfterFunc(ctx, stop)
 
-	// Run migrations on start if enabled
-	if config.Get("MIGRATE_ON_START", "true") == "true" {
-		if err := migrateUp(sqlDB); err != nil {
-			log.Fatalf("migration failed: %v", err)
+	logger, err := logging.New(os.Stdout, logging.Config{
+		Level:  cfg.Log.Level,
+		Format: cfg.Log.Format,
+	})
+	if err != nil {
+		fatal("invalid logging configuration", err)
+	}
+	slog.SetDefault(logger)
+	slog.Info("configuration loaded", "env", cfg.Env)
+
+	if command == "migrate" && len(args) > 0 && args[0] == "create" {
+		cmd.MigrateCreate(args[1:])
+		return
+	}
+
+	shutdownTracing, err := tracing.Setup(ctx, tracing.Config{
+		Exporter:    cfg.Tracing.Exporter,
+		ServiceName: cfg.Tracing.ServiceName,
+	})
+	if err != nil {
+		fatal("invalid tracing configuration", err)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Error("tracing shutdown failed", "error", err)
 		}
+	}()
+
+	dbLogLevel, err := dbpkg.ParseLogLevel(cfg.Database.LogLevel)
+	if err != nil {
+		fatal("invalid DB_LOG_LEVEL", err)
 	}
 
-	userServiceUrl := config.Get("USER_SERVICE_URL", "http://localhost:8000")
-	userClient := clients.New(userServiceUrl)
-	userRepository := repository.NewUserRepository(gormDB)
-	patientRepository := repository.NewPatientRepository(gormDB)
-	doctorRepository := repository.NewDoctorRepository(gormDB)
-	jwtService := jwt.NewJwtService(
-		config.Get("JWT_SECRET", "secret"),
-		config.GetInt("JWT_TTL", 3600),
-	)
-	userService := service.NewUserService(gormDB, userRepository, patientRepository, doctorRepository, userClient, jwtService)
-	userHandler := handlers.NewUserHandler(userService)
-	validate := validator.New()
-
-	app := fiber.New(fiber.Config{
-		JSONDecoder: func(b []byte, v any) error {
-			dec := json.NewDecoder(bytes.NewReader(b))
-			dec.DisallowUnknownFields()
-			if err := dec.Decode(v); err != nil {
-				return fmt.Errorf("decode: %w", err)
-			}
-			if err := dec.Decode(new(struct{})); err != io.EOF {
-				return fmt.Errorf("decode: trailing data")
-			}
+	encryptionKeys, err := encryption.ParseKeys(cfg.Encryption.Keys)
+	if err != nil {
+		fatal("invalid ENCRYPTION_KEYS", err)
+	}
+	keyring, err := encryption.NewKeyring(encryptionKeys, cfg.Encryption.ActiveKey, []byte(cfg.Encryption.BlindIndexKey))
+	if err != nil {
+		fatal("invalid encryption configuration", err)
+	}
+	encryption.Init(keyring)
+
+	gormDB, err := dbpkg.Open(ctx, dbpkg.Config{
+		Host:            cfg.Database.Host,
+		Port:            cfg.Database.Port,
+		User:            cfg.Database.User,
+		Password:        cfg.Database.Password,
+		Dbname:          cfg.Database.Name,
+		Sslmode:         cfg.Database.SSLMode,
+		Logger:          dbpkg.NewLogger(logger, dbLogLevel, time.Second),
+		MaxOpenConns:    cfg.Database.MaxOpenConns,
+		MaxIdleConns:    cfg.Database.MaxIdleConns,
+		ConnMaxLifetime: cfg.Database.ConnMaxLifetime,
+		ConnMaxIdleTime: cfg.Database.ConnMaxIdleTime,
+		ConnectAttempts: cfg.Database.ConnectAttempts,
+	})
+	if err != nil {
+		fatal("cannot connect to database", err)
+	}
 
-			rv := reflect.ValueOf(v)
-			for rv.Kind() == reflect.Pointer {
-				rv = rv.Elem()
-			}
+	sqlDB, err := gormDB.DB()
+	if err != nil {
+		fatal("cannot get *sql.DB from gorm", err)
+	}
+	if err := metrics.RegisterDB(sqlDB, cfg.Database.Name); err != nil {
+		fatal("cannot export DB pool metrics", err)
+	}
 
-			if rv.Kind() == reflect.Struct {
-				if err := validate.Struct(v); err != nil {
-					return err
-				}
-			}
-			return nil
+	application := app.New(repository.NewUnitOfWork(gormDB), keyring, app.Config{
+		JWTSecret:                cfg.Auth.JWTSecret,
+		JWTTTL:                   cfg.Auth.JWTTTL,
+		UserServiceURL:           cfg.Clients.UserServiceURL,
+		CORSAllowOrigins:         cfg.Server.CORSAllowOrigins,
+		AccountErasureRetention:  time.Duration(cfg.Retention.AccountErasureDays) * 24 * time.Hour,
+		PurgeRetention:           time.Duration(cfg.Retention.PurgeDays) * 24 * time.Hour,
+		DemoMode:                 cfg.Demo,
+		FHIRHospitalNumberSystem: cfg.FHIR.HospitalNumberSystem,
+		FHIRPractitionerSystem:   cfg.FHIR.PractitionerSystem,
+		ReadinessChecks: []service.HealthCheck{
+			{Name: "database", Check: sqlDB.PingContext},
+			{Name: "migrations", Check: func(ctx context.Context) error { return dbpkg.CheckSchemaVersion(ctx, sqlDB) }},
 		},
+		SchemaVersion: func(ctx context.Context) (int64, error) { return dbpkg.SchemaVersion(ctx, sqlDB) },
 	})
 
-	app.Use(cors.New(cors.Config{
-		AllowOrigins:     "http://localhost:3000",
-		AllowHeaders:     "Origin, Content-Type, Accept, Authorization",
-		AllowCredentials: true,
-	}))
+	// Migrations keep the demo accounts only in demo mode.
+	migrateCtx := migrations.WithDemoMode(ctx, cfg.Demo)
 
-	routes.SetupRoutes(app, userHandler, jwtService)
+	switch command {
+	case "serve":
+		// Run migrations on start if enabled
+		if cfg.Server.MigrateOnStart {
+			if err := dbpkg.MigrateUp(migrateCtx, sqlDB); err != nil {
+				fatal("migration failed", err)
+			}
+		}
+		// Demo accounts share a published password, so outside demo mode
+		// they are removed however they got into the database
+		if !cfg.Demo {
+			removed, err := migrations.RemoveDemoAccounts(ctx, sqlDB)
+			if err != nil {
+				fatal("demo account removal failed", err)
+			}
+			if removed > 0 {
+				slog.Warn("removed demo accounts", "count", removed)
+			}
+		}
+		// Index ID card numbers stored before the blind index existed
+		indexed, err := application.KeyRotationService.BackfillBlindIndexes(ctx, 500)
+		if err != nil {
+			fatal("blind index backfill failed", err)
+		}
+		if indexed > 0 {
+			slog.Info("indexed patient ID card numbers", "count", indexed)
+		}
+		cmd.Serve(ctx, application, cfg.Server.Port, cfg.Server.ShutdownTimeout)
+	case "migrate":
+		cmd.Migrate(migrateCtx, sqlDB, args)
+	case "seed":
+		cmd.Seed(ctx, application.SeedService, args)
+	case "import-patients":
+		cmd.ImportPatients(ctx, application.ImportService, args)
+	case "purge":
+		cmd.Purge(ctx, application.RetentionService, args)
+	case "rotate-keys":
+		cmd.RotateKeys(ctx, application.KeyRotationService, args)
+	}
 
-	port := config.Get("APP_PORT", "8000")
-	fmt.Println("Server is running on port " + port)
-	// listen on all interfaces for containerized envs; change back to "localhost:"+port if desired
-	if err := app.Listen(":" + port); err != nil {
-		log.Fatal(err)
+	if err := sqlDB.Close(); err != nil {
+		slog.Error("closing database failed", "error", err)
 	}
+	if command == "serve" {
+		slog.Info("shutdown complete")
+	}
+}
+
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
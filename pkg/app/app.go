@@ -0,0 +1,157 @@
+// Package app wires repositories, services, handlers and routes into the
+// Fiber application, for the server binary and the integration tests alike.
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+
+	"user-service/pkg/apperr"
+	"user-service/pkg/clients"
+	"user-service/pkg/encryption"
+	"user-service/pkg/handlers"
+	"user-service/pkg/jwt"
+	"user-service/pkg/middleware"
+	"user-service/pkg/repository"
+	"user-service/pkg/routes"
+	service "user-service/pkg/services"
+	"user-service/pkg/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+type Config struct {
+	JWTSecret               string
+	JWTTTL                  int
+	UserServiceURL          string
+	CORSAllowOrigins        string
+	AccountErasureRetention time.Duration
+	PurgeRetention          time.Duration
+	// DemoMode allows the demo seed profile.
+	DemoMode bool
+	// FHIRHospitalNumberSystem and FHIRPractitionerSystem are the identifier
+	// systems of hospital numbers and doctor usernames in the FHIR API.
+	FHIRHospitalNumberSystem string
+	FHIRPractitionerSystem   string
+	// ReadinessChecks are run by /readyz along with a check of the keyring.
+	ReadinessChecks []service.HealthCheck
+	// SchemaVersion reports the database's migration version for /version.
+	SchemaVersion func(ctx context.Context) (int64, error)
+}
+
+// App is the wired application: the HTTP server plus the services that the
+// background workers and CLI commands call directly.
+type App struct {
+	Fiber              *fiber.App
+	JwtService         *jwt.JwtService
+	UserService        *service.UserService
+	AccountService     *service.AccountService
+	RetentionService   *service.RetentionService
+	KeyRotationService *service.KeyRotationService
+	AuditService       *service.AuditService
+	SeedService        *service.SeedService
+	ImportService      *service.ImportService
+	ExportService      *service.ExportService
+}
+
+func New(uow repository.UnitOfWork, keyring *encryption.Keyring, cfg Config) *App {
+	userClient := clients.New(cfg.UserServiceURL)
+	jwtService := jwt.NewJwtService(cfg.JWTSecret, cfg.JWTTTL)
+
+	userService := service.NewUserService(uow, uow.Users(), uow.Patients(), uow.Doctors(), uow.Admins(), uow.ProfileChanges(), userClient, jwtService)
+	accountService := service.NewAccountService(uow, uow.AccountDeletions(), cfg.AccountErasureRetention)
+	retentionService := service.NewRetentionService(uow, uow.Users(), cfg.PurgeRetention)
+	keyRotationService := service.NewKeyRotationService(uow.Patients(), uow.ProfileChanges(), keyring)
+	auditService := service.NewAuditService(uow.AuditLog())
+	seedService := service.NewSeedService(uow, cfg.DemoMode)
+	importService := service.NewImportService(uow)
+	exportService := service.NewExportService(uow)
+	fhirService := service.NewFHIRService(uow, cfg.FHIRHospitalNumberSystem, cfg.FHIRPractitionerSystem)
+	healthChecks := append([]service.HealthCheck{keyringCheck(keyring)}, cfg.ReadinessChecks...)
+	healthService := service.NewHealthService(cfg.SchemaVersion, healthChecks...)
+
+	userHandler := handlers.NewUserHandler(userService)
+	accountHandler := handlers.NewAccountHandler(accountService)
+	retentionHandler := handlers.NewRetentionHandler(retentionService)
+	auditHandler := handlers.NewAuditHandler(auditService)
+	healthHandler := handlers.NewHealthHandler(healthService)
+	importHandler := handlers.NewImportHandler(importService)
+	exportHandler := handlers.NewExportHandler(exportService)
+	fhirHandler := handlers.NewFHIRHandler(fhirService)
+
+	validate := validation.New()
+
+	app := fiber.New(fiber.Config{
+		ErrorHandler: apperr.ErrorHandler,
+		JSONDecoder: func(b []byte, v any) error {
+			dec := json.NewDecoder(bytes.NewReader(b))
+			dec.DisallowUnknownFields()
+			if err := dec.Decode(v); err != nil {
+				return fmt.Errorf("decode: %w", err)
+			}
+			if err := dec.Decode(new(struct{})); err != io.EOF {
+				return fmt.Errorf("decode: trailing data")
+			}
+
+			rv := reflect.ValueOf(v)
+			for rv.Kind() == reflect.Pointer {
+				rv = rv.Elem()
+			}
+
+			if rv.Kind() == reflect.Struct {
+				if err := validate.Struct(v); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+
+	app.Use(requestid.New())
+	app.Use(middleware.Tracing())
+	app.Use(middleware.RequestLogger())
+	app.Use(middleware.Metrics())
+	app.Use(cors.New(cors.Config{
+		AllowOrigins:     cfg.CORSAllowOrigins,
+		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, If-Match",
+		ExposeHeaders:    "ETag",
+		AllowCredentials: true,
+	}))
+
+	app.Get("/metrics", adaptor.HTTPHandler(promhttp.Handler()))
+
+	routes.SetupRoutes(app, userHandler, accountHandler, retentionHandler, auditHandler, importHandler, exportHandler, fhirHandler, healthHandler, auditService, jwtService)
+
+	return &App{
+		Fiber:              app,
+		JwtService:         jwtService,
+		UserService:        userService,
+		AccountService:     accountService,
+		RetentionService:   retentionService,
+		KeyRotationService: keyRotationService,
+		AuditService:       auditService,
+		SeedService:        seedService,
+		ImportService:      importService,
+		ExportService:      exportService,
+	}
+}
+
+// keyringCheck reports whether the encryption keys are loaded and usable.
+func keyringCheck(keyring *encryption.Keyring) service.HealthCheck {
+	return service.HealthCheck{Name: "encryption_keys", Check: func(context.Context) error {
+		if encryption.Default() == nil {
+			return errors.New("encryption.Init has not been called")
+		}
+		return keyring.SelfTest()
+	}}
+}
@@ -0,0 +1,76 @@
+//go:build integration
+
+package app_test
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestAuditLog(t *testing.T) {
+	h := newTest(t)
+	patientToken, patientID := h.loginAsPatient(t, "HN-IT-0001")
+	doctorToken, doctorID := h.loginAsDoctor(t, "dr.integration")
+	adminToken, adminID := h.loginAsAdmin(t, "admin.integration")
+
+	h.request(t, http.MethodGet, "/api/user/v1/patient/me", patientToken, nil).expect(t, http.StatusOK)
+	h.request(t, http.MethodPost, "/api/user/v1/patients", doctorToken, map[string]any{
+		"patient_ids": []string{patientID},
+	}).expect(t, http.StatusOK)
+	h.request(t, http.MethodGet, "/api/user/v1/patients/search?q=Somchai", patientToken, nil).expect(t, http.StatusForbidden)
+
+	var entries []struct {
+		ActorID   string   `json:"actor_id"`
+		Action    string   `json:"action"`
+		TargetIDs []string `json:"target_ids"`
+		RequestID string   `json:"request_id"`
+		Outcome   string   `json:"outcome"`
+	}
+	h.request(t, http.MethodGet, "/api/user/v1/admin/audit-log?patient_id="+patientID, adminToken, nil).
+		expect(t, http.StatusOK).
+		data(t, &entries)
+	if len(entries) != 3 {
+		t.Fatalf("entries for patient = %+v, want register, read and read_batch", entries)
+	}
+	if e := entries[0]; e.Action != "patient.read_batch" || e.ActorID != doctorID || e.Outcome != "success" || e.RequestID != testRequestID {
+		t.Errorf("newest entry = %+v, want the doctor's batch read", e)
+	}
+
+	var denied []struct {
+		Outcome string `json:"outcome"`
+	}
+	h.request(t, http.MethodGet, "/api/user/v1/admin/audit-log?action=patient.search&actor_id="+patientID, adminToken, nil).
+		expect(t, http.StatusOK).
+		data(t, &denied)
+	if len(denied) != 1 || denied[0].Outcome != "denied" {
+		t.Errorf("patient search entries = %+v, want one denied", denied)
+	}
+
+	var reads []struct {
+		ActorID   string   `json:"actor_id"`
+		TargetIDs []string `json:"target_ids"`
+	}
+	h.request(t, http.MethodGet, "/api/user/v1/admin/audit-log?action=audit_log.list", adminToken, nil).
+		expect(t, http.StatusOK).
+		data(t, &reads)
+	if len(reads) != 2 || reads[1].ActorID != adminID || len(reads[1].TargetIDs) != 1 || reads[1].TargetIDs[0] != patientID {
+		t.Errorf("audit log reads = %+v, want both of the admin's queries, the first targeting the patient", reads)
+	}
+
+	if err := h.db.Exec("UPDATE audit_log SET outcome = 'success'").Error; err == nil {
+		t.Error("audit_log accepted an UPDATE")
+	}
+	if err := h.db.Exec("DELETE FROM audit_log").Error; err == nil {
+		t.Error("audit_log accepted a DELETE")
+	}
+
+	var verify struct {
+		Valid bool `json:"valid"`
+	}
+	h.request(t, http.MethodGet, "/api/user/v1/admin/audit-log/verify", adminToken, nil).
+		expect(t, http.StatusOK).
+		data(t, &verify)
+	if !verify.Valid {
+		t.Error("audit chain does not verify")
+	}
+}
@@ -0,0 +1,115 @@
+//go:build integration
+
+package app_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"user-service/pkg/models"
+	"user-service/pkg/repository"
+)
+
+const (
+	testIDCard  = "1101700203450"
+	otherIDCard = "3101001234565"
+)
+
+// registerWithIDCard registers a patient with a national ID card number and
+// returns their ID.
+func (h *harness) registerWithIDCard(t *testing.T, hospitalID, idCard string) string {
+	t.Helper()
+	h.request(t, http.MethodPost, "/api/user/v1/patient/register", "", map[string]any{
+		"password":       testPassword,
+		"first_name":     "Somchai",
+		"last_name":      "Jaidee",
+		"gender":         models.Male,
+		"phone_number":   "0812345678",
+		"hospital_id":    hospitalID,
+		"id_card_number": idCard,
+	}).expect(t, http.StatusCreated)
+	var ids []string
+	if err := h.db.Table("patients").Where("hospital_id = ?", hospitalID).Pluck("user_id", &ids).Error; err != nil || len(ids) != 1 {
+		t.Fatalf("find registered patient: %v", err)
+	}
+	return ids[0]
+}
+
+func TestEncryptedValuesAreBoundToTheirRow(t *testing.T) {
+	h := newTest(t)
+	firstID := h.registerWithIDCard(t, "HN-IT-0001", testIDCard)
+	secondID := h.registerWithIDCard(t, "HN-IT-0002", otherIDCard)
+	patients := repository.NewPatientRepository(h.db)
+
+	var stored string
+	if err := h.db.Table("patients").Where("user_id = ?", firstID).Pluck("id_card_number", &stored).Error; err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(stored, "enc:v2:") || strings.Contains(stored, testIDCard) {
+		t.Fatalf("stored ID card number = %q, want a v2 envelope", stored)
+	}
+
+	// A ciphertext copied into another patient's row, or another column of
+	// the same row, no longer decrypts.
+	if err := h.db.Exec("UPDATE patients SET id_card_number = ? WHERE user_id = ?", stored, secondID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := patients.FindByUserID(context.Background(), secondID); err == nil {
+		t.Error("read of a patient holding another patient's ciphertext succeeded, want an error")
+	}
+	// Columns are bound to the primary key whatever order they are read in.
+	var selected models.Patient
+	if err := h.db.Select("id_card_number", "user_id").Where("user_id = ?", firstID).First(&selected).Error; err != nil {
+		t.Fatalf("read with the primary key selected last: %v", err)
+	}
+	if selected.IDCardNumber == nil || *selected.IDCardNumber != testIDCard {
+		t.Errorf("ID card number = %v, want %s", selected.IDCardNumber, testIDCard)
+	}
+
+	if err := h.db.Exec("UPDATE patients SET address = id_card_number WHERE user_id = ?", firstID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := patients.FindByUserID(context.Background(), firstID); err == nil {
+		t.Error("read of a ciphertext moved to another column succeeded, want an error")
+	}
+}
+
+func TestBlindIndexBackfill(t *testing.T) {
+	h := newTest(t)
+	ctx := context.Background()
+	userID := h.registerWithIDCard(t, "HN-IT-0001", testIDCard)
+	// As written before the blind index and encryption existed.
+	if err := h.db.Exec("UPDATE patients SET id_card_number = ?, id_card_blind_index = NULL WHERE user_id = ?", testIDCard, userID).Error; err != nil {
+		t.Fatal(err)
+	}
+	patients := repository.NewPatientRepository(h.db)
+	if _, err := patients.FindByIDCardNumber(ctx, testIDCard); err == nil {
+		t.Fatal("found a patient without a blind index")
+	}
+
+	count, err := h.app.KeyRotationService.BackfillBlindIndexes(ctx, 10)
+	if err != nil || count != 1 {
+		t.Fatalf("backfill = %d (%v), want 1 patient", count, err)
+	}
+	patient, err := patients.FindByIDCardNumber(ctx, testIDCard)
+	if err != nil || patient.UserID.String() != userID {
+		t.Errorf("lookup after backfill = %+v (%v), want %s", patient, err, userID)
+	}
+	if count, err := h.app.KeyRotationService.BackfillBlindIndexes(ctx, 10); err != nil || count != 0 {
+		t.Errorf("second backfill = %d (%v), want nothing left", count, err)
+	}
+
+	// A legacy duplicate of an indexed ID card number is skipped, not fatal.
+	duplicateID := h.registerWithIDCard(t, "HN-IT-0002", otherIDCard)
+	if err := h.db.Exec("UPDATE patients SET id_card_number = ?, id_card_blind_index = NULL WHERE user_id = ?", testIDCard, duplicateID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count, err := h.app.KeyRotationService.BackfillBlindIndexes(ctx, 10); err != nil || count != 0 {
+		t.Errorf("backfill of a duplicate = %d (%v), want it skipped", count, err)
+	}
+	var indexes []*string
+	if err := h.db.Table("patients").Where("user_id = ?", duplicateID).Pluck("id_card_blind_index", &indexes).Error; err != nil || len(indexes) != 1 || indexes[0] != nil {
+		t.Errorf("duplicate's blind index = %v (%v), want none", indexes, err)
+	}
+}
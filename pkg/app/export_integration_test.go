@@ -0,0 +1,53 @@
+//go:build integration
+
+package app_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"net/http"
+	"testing"
+
+	"user-service/pkg/dto"
+)
+
+func TestExportPatientData(t *testing.T) {
+	h := newTest(t)
+	patientToken, patientID := h.loginAsPatient(t, "HN-IT-0001")
+	doctorToken, _ := h.loginAsDoctor(t, "dr.integration")
+
+	h.request(t, http.MethodGet, "/api/user/v1/patient/me", patientToken, nil).expect(t, http.StatusOK)
+	h.request(t, http.MethodPost, "/api/user/v1/patient/deletion-request", patientToken, nil).expect(t, http.StatusCreated)
+	h.request(t, http.MethodPost, "/api/user/v1/patients", doctorToken, map[string]any{
+		"patient_ids": []string{patientID},
+	}).expect(t, http.StatusOK)
+
+	var export dto.DataExportDto
+	h.request(t, http.MethodGet, "/api/user/v1/patient/me/export", patientToken, nil).
+		expect(t, http.StatusOK).
+		data(t, &export)
+	if export.User.ID != patientID || export.Patient.HospitalID != "HN-IT-0001" || len(export.DeletionRequests) != 1 {
+		t.Errorf("export = %+v, want the patient's record and deletion request", export)
+	}
+	var actions []string
+	for _, entry := range export.AuditEntries {
+		actions = append(actions, entry.Action)
+	}
+	if len(actions) != 4 || actions[0] != "patient.register" || actions[3] != "patient.read_batch" {
+		t.Errorf("audit actions = %v, want register, read, deletion_request and read_batch", actions)
+	}
+
+	res := h.request(t, http.MethodGet, "/api/user/v1/patient/me/export?format=zip", patientToken, nil).expect(t, http.StatusOK)
+	if ct := res.header.Get("Content-Type"); ct != "application/zip" {
+		t.Errorf("Content-Type = %q, want application/zip", ct)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(res.body), int64(len(res.body)))
+	if err != nil {
+		t.Fatalf("read bundle: %v", err)
+	}
+	if len(archive.File) != 2 || archive.File[0].Name != "data.json" || archive.File[1].Name != "summary.txt" {
+		t.Errorf("bundle files = %v, want data.json and summary.txt", archive.File)
+	}
+
+	h.request(t, http.MethodGet, "/api/user/v1/patient/me/export", doctorToken, nil).expect(t, http.StatusForbidden)
+}
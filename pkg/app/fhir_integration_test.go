@@ -0,0 +1,142 @@
+//go:build integration
+
+package app_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"user-service/pkg/fhir"
+	"user-service/pkg/models"
+)
+
+func (r *response) fhir(t *testing.T, v any) {
+	t.Helper()
+	if ct := r.header.Get("Content-Type"); ct != fhir.MIMEType {
+		t.Errorf("Content-Type = %q, want %s", ct, fhir.MIMEType)
+	}
+	if err := json.Unmarshal(r.body, v); err != nil {
+		t.Fatalf("decode %s: %v", r.body, err)
+	}
+}
+
+func TestFHIRPatientSearch(t *testing.T) {
+	h := newTest(t)
+	doctorToken, _ := h.loginAsDoctor(t, "dr.integration")
+	h.registerPatient(t, "HN-IT-0001", "Somchai")
+	h.registerPatient(t, "HN-IT-0002", "Sukanya")
+	h.request(t, http.MethodPost, "/api/user/v1/patient/register", "", map[string]any{
+		"password":       testPassword,
+		"first_name":     "Somsak",
+		"last_name":      "Wongsawat",
+		"gender":         models.Male,
+		"phone_number":   "0812345678",
+		"hospital_id":    "HN-IT-0003",
+		"id_card_number": "1101700203450",
+	}).expect(t, http.StatusCreated)
+
+	search := func(params url.Values) *fhir.Bundle {
+		t.Helper()
+		var bundle fhir.Bundle
+		h.request(t, http.MethodGet, "/api/user/v1/fhir/Patient?"+params.Encode(), doctorToken, nil).
+			expect(t, http.StatusOK).
+			fhir(t, &bundle)
+		return &bundle
+	}
+	for name, tc := range map[string]struct {
+		params url.Values
+		total  int64
+	}{
+		"name prefix":     {url.Values{"name": {"som"}}, 2},
+		"family name":     {url.Values{"name": {"Wong"}}, 1},
+		"hospital number": {url.Values{"identifier": {testHospitalNumberSystem + "|HN-IT-0002"}}, 1},
+		"national ID":     {url.Values{"identifier": {fhir.ThaiNationalIDSystem + "|1101700203450"}}, 1},
+		"any system":      {url.Values{"identifier": {"HN-IT-0001"}}, 1},
+		"name and ID":     {url.Values{"name": {"Sukanya"}, "identifier": {"HN-IT-0001"}}, 0},
+	} {
+		if bundle := search(tc.params); bundle.Total != tc.total {
+			t.Errorf("%s: total = %d, want %d", name, bundle.Total, tc.total)
+		}
+	}
+
+	first := search(url.Values{"_count": {"2"}})
+	if first.Total != 3 || len(first.Entry) != 2 {
+		t.Fatalf("first page = %d of %d, want 2 of 3", len(first.Entry), first.Total)
+	}
+	var next string
+	for _, link := range first.Link {
+		if link.Relation == "next" {
+			next = link.URL
+		}
+	}
+	nextURL, err := url.Parse(next)
+	if err != nil || next == "" {
+		t.Fatalf("next link = %q", next)
+	}
+	second := search(nextURL.Query())
+	if len(second.Entry) != 1 {
+		t.Fatalf("second page has %d entries, want 1", len(second.Entry))
+	}
+
+	var patient fhir.Patient
+	id := second.Entry[0].Resource.(map[string]any)["id"].(string)
+	h.request(t, http.MethodGet, "/api/user/v1/fhir/Patient/"+id, doctorToken, nil).
+		expect(t, http.StatusOK).
+		fhir(t, &patient)
+	if patient.ID != id || patient.Identifier[0].System != testHospitalNumberSystem {
+		t.Errorf("patient = %+v, want %s with its hospital number", patient, id)
+	}
+}
+
+func TestFHIRPractitioner(t *testing.T) {
+	h := newTest(t)
+	patientToken, _ := h.loginAsPatient(t, "HN-IT-0001")
+	_, doctorID := h.loginAsDoctor(t, "dr.integration")
+
+	var bundle fhir.Bundle
+	h.request(t, http.MethodGet, "/api/user/v1/fhir/Practitioner?identifier="+url.QueryEscape(testPractitionerSystem+"|dr.integration"), patientToken, nil).
+		expect(t, http.StatusOK).
+		fhir(t, &bundle)
+	if bundle.Total != 1 {
+		t.Fatalf("practitioners = %+v, want the doctor", bundle)
+	}
+
+	var role fhir.PractitionerRole
+	h.request(t, http.MethodGet, "/api/user/v1/fhir/PractitionerRole/"+doctorID, patientToken, nil).
+		expect(t, http.StatusOK).
+		fhir(t, &role)
+	if role.Practitioner.Reference != "Practitioner/"+doctorID || len(role.Specialty) != 1 || role.Specialty[0].Text != "Cardiology" {
+		t.Errorf("role = %+v, want the doctor's specialty", role)
+	}
+
+	var outcome fhir.OperationOutcome
+	h.request(t, http.MethodGet, "/api/user/v1/fhir/Practitioner/"+"01920e5a-0000-7000-8000-000000000000", patientToken, nil).
+		expect(t, http.StatusNotFound).
+		fhir(t, &outcome)
+	if outcome.Issue[0].Code != "not-found" {
+		t.Errorf("outcome = %+v, want not-found", outcome)
+	}
+	h.request(t, http.MethodGet, "/api/user/v1/fhir/PractitionerRole?name=Ploy", patientToken, nil).
+		expect(t, http.StatusBadRequest).
+		fhir(t, &outcome)
+	if outcome.Issue[0].Code != "invalid" {
+		t.Errorf("role search by name = %+v, want invalid", outcome)
+	}
+
+	// Refused requests get an OperationOutcome like any other FHIR error.
+	for _, refused := range []struct {
+		token  string
+		status int
+		issue  string
+	}{{"", http.StatusUnauthorized, "login"}, {patientToken, http.StatusForbidden, "forbidden"}} {
+		var outcome fhir.OperationOutcome
+		h.request(t, http.MethodGet, "/api/user/v1/fhir/Patient", refused.token, nil).
+			expect(t, refused.status).
+			fhir(t, &outcome)
+		if len(outcome.Issue) != 1 || outcome.Issue[0].Code != refused.issue {
+			t.Errorf("%d outcome = %+v, want a %s issue", refused.status, outcome, refused.issue)
+		}
+	}
+}
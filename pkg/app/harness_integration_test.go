@@ -0,0 +1,338 @@
+//go:build integration
+
+// The integration tests boot the real application against a disposable
+// Postgres started from the embedded-postgres binaries, with every goose
+// migration applied. Run them with:
+//
+//	go test -tags integration ./pkg/app/...
+//
+// The Postgres archive is downloaded on first use and cached; point
+// EMBEDDED_POSTGRES_CACHE at a directory holding the archive to run offline.
+// Pass -update to rewrite the golden files from the actual responses.
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"user-service/pkg/app"
+	dbpkg "user-service/pkg/db"
+	"user-service/pkg/encryption"
+	"user-service/pkg/models"
+	"user-service/pkg/repository"
+	service "user-service/pkg/services"
+	"user-service/pkg/utils"
+
+	embeddedpostgres "github.com/fergusstrange/embedded-postgres"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const (
+	testPassword  = "integration-password"
+	testRequestID = "integration-request-id"
+
+	testHospitalNumberSystem = "urn:oid:1.2.3.4.1"
+	testPractitionerSystem   = "urn:oid:1.2.3.4.2"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files with the actual responses")
+
+// suite is shared by all tests; each test starts from empty user tables.
+var suite *harness
+
+type harness struct {
+	app *app.App
+	db  *gorm.DB
+}
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	code, err := run(m)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(code)
+}
+
+func run(m *testing.M) (int, error) {
+	dir, err := os.MkdirTemp("", "user-service-integration-")
+	if err != nil {
+		return 0, err
+	}
+	defer os.RemoveAll(dir)
+
+	port, err := freePort()
+	if err != nil {
+		return 0, err
+	}
+	config := embeddedpostgres.DefaultConfig().
+		Port(port).
+		Database("userdb").
+		Username("user").
+		Password("password").
+		RuntimePath(filepath.Join(dir, "runtime")).
+		Logger(io.Discard)
+	if cache := os.Getenv("EMBEDDED_POSTGRES_CACHE"); cache != "" {
+		config = config.CachePath(cache)
+	}
+	pg := embeddedpostgres.NewDatabase(config)
+	if err := pg.Start(); err != nil {
+		return 0, fmt.Errorf("start postgres: %w", err)
+	}
+	defer pg.Stop()
+
+	db, err := gorm.Open(postgres.Open(config.GetConnectionURL()+"?sslmode=disable"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("connect: %w", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return 0, err
+	}
+	defer sqlDB.Close()
+	if err := dbpkg.MigrateUp(context.Background(), sqlDB); err != nil {
+		return 0, err
+	}
+
+	keyring, err := encryption.NewKeyring(map[int][]byte{1: bytes.Repeat([]byte{1}, 32)}, 0, []byte("integration-blind-index-key"))
+	if err != nil {
+		return 0, err
+	}
+	encryption.Init(keyring)
+
+	suite = &harness{
+		app: app.New(repository.NewUnitOfWork(db), keyring, app.Config{
+			JWTSecret:                "integration-secret",
+			JWTTTL:                   3600,
+			CORSAllowOrigins:         "http://localhost:3000",
+			FHIRHospitalNumberSystem: testHospitalNumberSystem,
+			FHIRPractitionerSystem:   testPractitionerSystem,
+			ReadinessChecks: []service.HealthCheck{
+				{Name: "database", Check: sqlDB.PingContext},
+				{Name: "migrations", Check: func(ctx context.Context) error { return dbpkg.CheckSchemaVersion(ctx, sqlDB) }},
+			},
+			SchemaVersion: func(ctx context.Context) (int64, error) { return dbpkg.SchemaVersion(ctx, sqlDB) },
+		}),
+		db: db,
+	}
+	return m.Run(), nil
+}
+
+func freePort() (uint32, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return uint32(l.Addr().(*net.TCPAddr).Port), nil
+}
+
+// newTest empties every table that references users, leaving master data
+// such as healthcare entitlements in place.
+func newTest(t *testing.T) *harness {
+	t.Helper()
+	if err := suite.db.Exec("TRUNCATE users CASCADE").Error; err != nil {
+		t.Fatalf("reset database: %v", err)
+	}
+	return suite
+}
+
+type response struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// request sends a JSON request through the Fiber app. body may be nil, and
+// token is sent as the access_token cookie when not empty.
+func (h *harness) request(t *testing.T, method, path, token string, body any) *response {
+	t.Helper()
+	return h.requestWithHeader(t, method, path, token, nil, body)
+}
+
+// requestWithHeader is request with additional request headers.
+func (h *harness) requestWithHeader(t *testing.T, method, path, token string, header http.Header, body any) *response {
+	t.Helper()
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = bytes.NewReader(b)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Request-ID", testRequestID)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if token != "" {
+		req.AddCookie(&http.Cookie{Name: "access_token", Value: token})
+	}
+	res, err := h.app.Fiber.Test(req, -1)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer res.Body.Close()
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &response{status: res.StatusCode, header: res.Header, body: b}
+}
+
+func (r *response) expect(t *testing.T, status int) *response {
+	t.Helper()
+	if r.status != status {
+		t.Fatalf("status = %d, want %d; body: %s", r.status, status, r.body)
+	}
+	return r
+}
+
+// data decodes the data member of the response envelope into v.
+func (r *response) data(t *testing.T, v any) {
+	t.Helper()
+	envelope := struct {
+		Data json.RawMessage `json:"data"`
+	}{}
+	if err := json.Unmarshal(r.body, &envelope); err != nil {
+		t.Fatalf("decode %s: %v", r.body, err)
+	}
+	if err := json.Unmarshal(envelope.Data, v); err != nil {
+		t.Fatalf("decode data %s: %v", envelope.Data, err)
+	}
+}
+
+// registerPatient registers a patient through the API.
+func (h *harness) registerPatient(t *testing.T, hospitalID, firstName string) {
+	t.Helper()
+	h.request(t, http.MethodPost, "/api/user/v1/patient/register", "", map[string]any{
+		"password":     testPassword,
+		"first_name":   firstName,
+		"last_name":    "Jaidee",
+		"gender":       models.Male,
+		"phone_number": "0812345678",
+		"hospital_id":  hospitalID,
+	}).expect(t, http.StatusCreated)
+}
+
+// loginAsPatient registers a patient and returns their access token and ID.
+func (h *harness) loginAsPatient(t *testing.T, hospitalID string) (token, userID string) {
+	t.Helper()
+	h.registerPatient(t, hospitalID, "Somchai")
+	return h.login(t, "/api/user/v1/patient/login", map[string]any{"hospital_id": hospitalID, "password": testPassword})
+}
+
+// loginAsDoctor creates a doctor and returns their access token and ID.
+func (h *harness) loginAsDoctor(t *testing.T, username string) (token, userID string) {
+	t.Helper()
+	user := h.createUser(t, models.DoctorRole, "Ploy")
+	specialty := "Cardiology"
+	if err := h.db.Create(&models.Doctor{UserID: user.ID, Username: username, Specialty: &specialty}).Error; err != nil {
+		t.Fatalf("create doctor: %v", err)
+	}
+	return h.login(t, "/api/user/v1/doctor/login", map[string]any{"username": username, "password": testPassword})
+}
+
+// loginAsAdmin creates an admin and returns their access token and ID.
+func (h *harness) loginAsAdmin(t *testing.T, username string) (token, userID string) {
+	t.Helper()
+	user := h.createUser(t, models.AdminRole, "Admin")
+	if err := h.db.Create(&models.Admin{UserID: user.ID, Username: username}).Error; err != nil {
+		t.Fatalf("create admin: %v", err)
+	}
+	return h.login(t, "/api/user/v1/admin/login", map[string]any{"username": username, "password": testPassword})
+}
+
+func (h *harness) createUser(t *testing.T, role models.Role, firstName string) *models.User {
+	t.Helper()
+	hash, err := utils.HashPassword(testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &models.User{
+		ID:          utils.GenerateUUIDv7(),
+		Password:    hash,
+		FirstName:   firstName,
+		LastName:    "Srisuk",
+		Gender:      models.Female,
+		PhoneNumber: "0899999999",
+		Role:        role,
+	}
+	if err := h.db.Create(user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return user
+}
+
+func (h *harness) login(t *testing.T, path string, body map[string]any) (token, userID string) {
+	t.Helper()
+	var res struct {
+		AccessToken string `json:"access_token"`
+	}
+	h.request(t, http.MethodPost, path, "", body).expect(t, http.StatusOK).data(t, &res)
+	claims, err := h.app.JwtService.Parse(res.AccessToken)
+	if err != nil {
+		t.Fatalf("parse token: %v", err)
+	}
+	return res.AccessToken, claims.UserID
+}
+
+var volatile = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`), "<uuid>"},
+	{regexp.MustCompile(`\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})`), "<timestamp>"},
+	{regexp.MustCompile(`eyJ[\w-]*\.[\w-]*\.[\w-]*`), "<jwt>"},
+}
+
+// assertGolden compares the response body, with IDs, timestamps and tokens
+// masked and the JSON indented, to testdata/golden/<name>.json.
+func (r *response) assertGolden(t *testing.T, name string) {
+	t.Helper()
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, r.body, "", "  "); err != nil {
+		t.Fatalf("response is not JSON: %s", r.body)
+	}
+	got := indented.Bytes()
+	for _, v := range volatile {
+		got = v.pattern.ReplaceAll(got, []byte(v.replacement))
+	}
+	got = append(got, '\n')
+
+	path := filepath.Join("testdata", "golden", name+".json")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden file (run with -update to create it): %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s mismatch (run with -update to accept)\n--- got\n%s\n--- want\n%s", path, got, want)
+	}
+}
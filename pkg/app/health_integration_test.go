@@ -0,0 +1,59 @@
+//go:build integration
+
+package app_test
+
+import (
+	"net/http"
+	"testing"
+
+	dbpkg "user-service/pkg/db"
+	"user-service/pkg/dto"
+)
+
+func TestHealthEndpoints(t *testing.T) {
+	h := newTest(t)
+
+	h.request(t, http.MethodGet, "/healthz", "", nil).
+		expect(t, http.StatusOK).
+		assertGolden(t, "healthz")
+	h.request(t, http.MethodGet, "/readyz", "", nil).
+		expect(t, http.StatusOK).
+		assertGolden(t, "readyz")
+
+	var version dto.VersionResponseDto
+	h.request(t, http.MethodGet, "/version", "", nil).expect(t, http.StatusOK).data(t, &version)
+	sqlDB, err := h.db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest, err := dbpkg.LatestSchemaVersion(sqlDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version.SchemaVersion == nil || *version.SchemaVersion != latest {
+		t.Errorf("schema_version = %v, want %d", version.SchemaVersion, latest)
+	}
+}
+
+func TestReadinessFailsWhenSchemaIsNotCurrent(t *testing.T) {
+	h := newTest(t)
+	sqlDB, err := h.db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest, err := dbpkg.LatestSchemaVersion(sqlDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Pretend a newer binary has migrated the database further.
+	if err := h.db.Exec("INSERT INTO goose_db_version (version_id, is_applied) VALUES (?, true)", latest+1).Error; err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		h.db.Exec("DELETE FROM goose_db_version WHERE version_id = ?", latest+1)
+	})
+
+	h.request(t, http.MethodGet, "/readyz", "", nil).
+		expect(t, http.StatusServiceUnavailable).
+		assertGolden(t, "readyz_schema_mismatch")
+}
@@ -0,0 +1,72 @@
+//go:build integration
+
+package app_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"user-service/pkg/dto"
+)
+
+func TestImportPatients(t *testing.T) {
+	h := newTest(t)
+	adminToken, _ := h.loginAsAdmin(t, "admin")
+	h.registerPatient(t, "HN-IT-0001", "Somchai")
+
+	csv := "hospital_id,first_name,last_name,gender,birth_date,id_card_number,healthcare_entitlements\n" +
+		"HN-IT-0001,Somchai,Jaidee,male,1985-03-15,,Social Security\n" +
+		"HN-IT-0002,Sukanya,Sriprasert,female,1990-07-22,1101700203450,Universal Coverage;Social Security\n" +
+		"HN-IT-0003,Nattapong,Wongsawat,male,,,\n"
+	importCSV := func(query string) *dto.PatientImportResponseDto {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/api/user/v1/admin/patients/import"+query, strings.NewReader(csv))
+		req.Header.Set("Content-Type", "text/csv")
+		req.AddCookie(&http.Cookie{Name: "access_token", Value: adminToken})
+		res, err := h.app.Fiber.Test(req, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		body, _ := io.ReadAll(res.Body)
+		var report dto.PatientImportResponseDto
+		(&response{status: res.StatusCode, body: body}).expect(t, http.StatusOK).data(t, &report)
+		return &report
+	}
+
+	// The existing hospital ID is only caught by the database, inside the
+	// batch's transaction; the other rows must still go through.
+	report := importCSV("?dry_run=true&batch_size=10")
+	if report.Imported != 2 || report.Failed != 1 || report.Errors[0].Line != 2 {
+		t.Fatalf("dry run report = %+v, want 2 imported and line 2 rejected", report)
+	}
+	var count int64
+	h.db.Table("patients").Count(&count)
+	if count != 1 {
+		t.Fatalf("dry run left %d patients, want 1", count)
+	}
+
+	report = importCSV("?batch_size=10")
+	if report.Imported != 2 || report.Failed != 1 {
+		t.Fatalf("report = %+v, want 2 imported and 1 rejected", report)
+	}
+	h.db.Table("user_healthcare_entitlement").Count(&count)
+	if count != 2 {
+		t.Errorf("%d entitlements assigned, want 2", count)
+	}
+	h.request(t, http.MethodPost, "/api/user/v1/patient/login", "", map[string]any{"hospital_id": "HN-IT-0003", "password": testPassword}).
+		expect(t, http.StatusUnauthorized)
+
+	// An admin gives the imported patient a password.
+	var importedID string
+	if err := h.db.Table("patients").Where("hospital_id = ?", "HN-IT-0003").Pluck("user_id", &importedID).Error; err != nil {
+		t.Fatal(err)
+	}
+	h.request(t, http.MethodPut, "/api/user/v1/admin/patients/"+importedID+"/password", adminToken, map[string]any{"password": testPassword}).
+		expect(t, http.StatusOK)
+	h.request(t, http.MethodPost, "/api/user/v1/patient/login", "", map[string]any{"hospital_id": "HN-IT-0003", "password": testPassword}).
+		expect(t, http.StatusOK)
+}
@@ -0,0 +1,186 @@
+//go:build integration
+
+package app_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+	"user-service/pkg/dto"
+)
+
+func TestPatientProfile(t *testing.T) {
+	h := newTest(t)
+	token, _ := h.loginAsPatient(t, "HN-IT-0001")
+
+	res := h.request(t, http.MethodGet, "/api/user/v1/patient/me", token, nil).expect(t, http.StatusOK)
+	res.assertGolden(t, "patient_profile")
+
+	res = h.requestWithHeader(t, http.MethodPatch, "/api/user/v1/patient", token, ifMatch(res.header.Get("ETag")), map[string]any{
+		"first_name": "Somying",
+		"address":    "99 Rama IV Road, Bangkok",
+		"blood_type": "O+",
+	}).expect(t, http.StatusOK)
+	res.assertGolden(t, "patient_profile_update")
+	updated := res.header.Get("ETag")
+
+	res = h.request(t, http.MethodGet, "/api/user/v1/patient/me", token, nil).expect(t, http.StatusOK)
+	res.assertGolden(t, "patient_profile_updated")
+	if etag := res.header.Get("ETag"); etag != updated {
+		t.Errorf("ETag = %s, want %s from the update", etag, updated)
+	}
+}
+
+func TestPatientProfilePreconditions(t *testing.T) {
+	h := newTest(t)
+	token, _ := h.loginAsPatient(t, "HN-IT-0001")
+	stale := h.request(t, http.MethodGet, "/api/user/v1/patient/me", token, nil).expect(t, http.StatusOK).header.Get("ETag")
+
+	h.request(t, http.MethodPatch, "/api/user/v1/patient", token, map[string]any{"first_name": "Somying"}).
+		expect(t, http.StatusPreconditionRequired).
+		assertGolden(t, "patient_profile_if_match_missing")
+
+	h.requestWithHeader(t, http.MethodPatch, "/api/user/v1/patient", token, ifMatch(stale), map[string]any{"first_name": "Somying"}).
+		expect(t, http.StatusOK)
+	h.requestWithHeader(t, http.MethodPatch, "/api/user/v1/patient", token, ifMatch(stale), map[string]any{"last_name": "Overwritten"}).
+		expect(t, http.StatusPreconditionFailed).
+		assertGolden(t, "patient_profile_stale")
+}
+
+// TestPatientProfileLocksBothRows changes only a users column on a profile
+// whose patient row is being written concurrently, and expects the update to
+// wait for that write and then fail on the stale tag.
+func TestPatientProfileLocksBothRows(t *testing.T) {
+	h := newTest(t)
+	token, userID := h.loginAsPatient(t, "HN-IT-0001")
+	etag := h.request(t, http.MethodGet, "/api/user/v1/patient/me", token, nil).expect(t, http.StatusOK).header.Get("ETag")
+
+	tx := h.db.Begin()
+	defer tx.Rollback()
+	if err := tx.Exec("UPDATE patients SET blood_type = 'AB-', version = version + 1 WHERE user_id = ?", userID).Error; err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan *response, 1)
+	go func() {
+		done <- h.requestWithHeader(t, http.MethodPatch, "/api/user/v1/patient", token, ifMatch(etag), map[string]any{"first_name": "Somying"})
+	}()
+	select {
+	case res := <-done:
+		t.Fatalf("update finished while the patient row was locked: %d %s", res.status, res.body)
+	case <-time.After(200 * time.Millisecond):
+	}
+	if err := tx.Commit().Error; err != nil {
+		t.Fatal(err)
+	}
+	(<-done).expect(t, http.StatusPreconditionFailed)
+}
+
+func TestPatientProfileHistory(t *testing.T) {
+	h := newTest(t)
+	patientToken, patientID := h.loginAsPatient(t, "HN-IT-0001")
+	_, otherID := h.loginAsPatient(t, "HN-IT-0002")
+	doctorToken, _ := h.loginAsDoctor(t, "dr.integration")
+
+	etag := h.request(t, http.MethodGet, "/api/user/v1/patient/me", patientToken, nil).expect(t, http.StatusOK).header.Get("ETag")
+	h.requestWithHeader(t, http.MethodPatch, "/api/user/v1/patient", patientToken, ifMatch(etag), map[string]any{
+		"allergies":  "Penicillin",
+		"blood_type": "O+",
+	}).expect(t, http.StatusOK)
+
+	h.request(t, http.MethodGet, "/api/user/v1/patients/"+patientID+"/history", doctorToken, nil).
+		expect(t, http.StatusOK).
+		assertGolden(t, "patient_profile_history")
+
+	h.request(t, http.MethodGet, "/api/user/v1/patients/"+otherID+"/history", patientToken, nil).
+		expect(t, http.StatusForbidden)
+}
+
+func ifMatch(etag string) http.Header {
+	return http.Header{"If-Match": {etag}}
+}
+
+func TestRegisterDuplicateHospitalID(t *testing.T) {
+	h := newTest(t)
+	h.registerPatient(t, "HN-IT-0001", "Somchai")
+
+	h.request(t, http.MethodPost, "/api/user/v1/patient/register", "", map[string]any{
+		"password":    testPassword,
+		"first_name":  "Somsri",
+		"last_name":   "Jaidee",
+		"gender":      "female",
+		"hospital_id": "HN-IT-0001",
+	}).expect(t, http.StatusConflict).assertGolden(t, "register_duplicate_hospital_id")
+}
+
+func TestRegisterValidation(t *testing.T) {
+	h := newTest(t)
+
+	h.request(t, http.MethodPost, "/api/user/v1/patient/register", "", map[string]any{
+		"password":       "short",
+		"gender":         "unknown",
+		"id_card_number": "1234567890123",
+	}).expect(t, http.StatusBadRequest).assertGolden(t, "register_validation")
+}
+
+func TestBatchLookups(t *testing.T) {
+	h := newTest(t)
+	_, patientID := h.loginAsPatient(t, "HN-IT-0001")
+	token, doctorID := h.loginAsDoctor(t, "dr.integration")
+
+	h.request(t, http.MethodPost, "/api/user/v1/doctors", token, map[string]any{
+		"doctor_ids": []string{doctorID, patientID},
+	}).expect(t, http.StatusOK).assertGolden(t, "doctors_by_ids")
+
+	h.request(t, http.MethodPost, "/api/user/v1/patients", token, map[string]any{
+		"patient_ids": []string{patientID, doctorID},
+	}).expect(t, http.StatusOK).assertGolden(t, "patients_by_ids")
+}
+
+func TestDoctorSearch(t *testing.T) {
+	h := newTest(t)
+	token, _ := h.loginAsDoctor(t, "dr.integration")
+
+	h.request(t, http.MethodGet, "/api/user/v1/doctors/search?q=cardio", token, nil).
+		expect(t, http.StatusOK).
+		assertGolden(t, "doctors_search")
+}
+
+func TestPatientSearch(t *testing.T) {
+	h := newTest(t)
+	token, _ := h.loginAsDoctor(t, "dr.integration")
+	h.registerPatient(t, "HN20240001", "Somchai")
+	h.registerPatient(t, "QX99887766", "Kanya")
+
+	for q, want := range map[string]string{
+		"HN20240001": "HN20240001", // exact
+		"2024":       "HN20240001", // substring
+		"HN2024001":  "HN20240001", // a digit short: trigram similarity only
+		"Kanyaa":     "QX99887766", // misspelt first name
+	} {
+		var patients []dto.GetProfileResponseDto
+		h.request(t, http.MethodGet, "/api/user/v1/patients/search?q="+q, token, nil).
+			expect(t, http.StatusOK).
+			data(t, &patients)
+		if len(patients) != 1 || patients[0].HospitalID != want {
+			t.Errorf("search %q = %+v, want only %s", q, patients, want)
+		}
+	}
+}
+
+func TestRoleGuards(t *testing.T) {
+	h := newTest(t)
+	patientToken, _ := h.loginAsPatient(t, "HN-IT-0001")
+	adminToken, _ := h.loginAsAdmin(t, "admin.integration")
+
+	h.request(t, http.MethodGet, "/api/user/v1/patient/me", "", nil).
+		expect(t, http.StatusUnauthorized).
+		assertGolden(t, "missing_token")
+
+	h.request(t, http.MethodGet, "/api/user/v1/patients/search?q=Somchai", patientToken, nil).
+		expect(t, http.StatusForbidden).
+		assertGolden(t, "patients_search_forbidden")
+
+	h.request(t, http.MethodGet, "/api/user/v1/admin/users/deleted", adminToken, nil).
+		expect(t, http.StatusOK).
+		assertGolden(t, "admin_deleted_users")
+}
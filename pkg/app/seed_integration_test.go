@@ -0,0 +1,127 @@
+//go:build integration
+
+package app_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	dbpkg "user-service/pkg/db"
+	"user-service/pkg/db/migrations"
+	"user-service/pkg/models"
+	"user-service/pkg/repository"
+	service "user-service/pkg/services"
+
+	"github.com/pressly/goose/v3"
+)
+
+const removeDemoAccountsVersion = 20261019101000
+
+func TestSeedDemoProfile(t *testing.T) {
+	h := newTest(t)
+	ctx := context.Background()
+	uow := repository.NewUnitOfWork(h.db)
+
+	if _, err := service.NewSeedService(uow, false).Seed(ctx, service.SeedProfileDemo); err == nil {
+		t.Fatal("demo profile was seeded without demo mode")
+	}
+
+	seeder := service.NewSeedService(uow, true)
+	res, err := seeder.Seed(ctx, service.SeedProfileDemo)
+	if err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	if res.Patients != 3 || res.Doctors != 3 || res.Admins != 1 {
+		t.Errorf("seeded %+v, want 3 patients, 3 doctors and 1 admin", res)
+	}
+	if res, err := seeder.Seed(ctx, service.SeedProfileDemo); err != nil || *res != (service.SeedResult{}) {
+		t.Errorf("second seed = %+v, %v; want nothing added", res, err)
+	}
+
+	h.request(t, http.MethodPost, "/api/user/v1/patient/login", "", map[string]any{"hospital_id": "HN001234", "password": "demo-password"}).
+		expect(t, http.StatusOK)
+	h.request(t, http.MethodPost, "/api/user/v1/doctor/login", "", map[string]any{"username": "dr.prasit", "password": "demo-password"}).
+		expect(t, http.StatusOK)
+}
+
+func TestMigrationRemovesDemoAccounts(t *testing.T) {
+	h := newTest(t)
+	ctx := context.Background()
+	if _, err := service.NewSeedService(repository.NewUnitOfWork(h.db), true).Seed(ctx, service.SeedProfileDemo); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	h.createUser(t, models.PatientRole, "Ploy")
+	sqlDB, err := h.db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	countUsers := func() int64 {
+		t.Helper()
+		var n int64
+		if err := h.db.Model(&models.User{}).Count(&n).Error; err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	// rerun rolls back to just before remove_demo_accounts and migrates up
+	// again; the harness has already pointed goose at the embedded files.
+	rerun := func(ctx context.Context) {
+		t.Helper()
+		if err := goose.DownToContext(ctx, sqlDB, ".", removeDemoAccountsVersion-1); err != nil {
+			t.Fatalf("migrate down: %v", err)
+		}
+		if err := dbpkg.MigrateUp(ctx, sqlDB); err != nil {
+			t.Fatalf("migrate up: %v", err)
+		}
+	}
+
+	// Migrating in demo mode keeps the accounts loaded from the fixture but
+	// not one as the master data migration inserted it...
+	masterDataHash := "$argon2id$v=19$m=65536,t=3,p=4$KZPKCgTQjZUHK4A0aI72KQ$KoQcuUnR4HNcaO7364bX56V9KYE35S1I9LPQeQpXVbs"
+	if err := h.db.Exec("UPDATE users SET password = ? WHERE id = ?", masterDataHash, "01920e5a-1234-7890-abcd-000000000001").Error; err != nil {
+		t.Fatal(err)
+	}
+	rerun(migrations.WithDemoMode(ctx, true))
+	if n := countUsers(); n != 7 {
+		t.Errorf("%d users after migrating in demo mode, want 7", n)
+	}
+
+	// ...and outside demo mode deletes them but no one else.
+	rerun(ctx)
+	if n := countUsers(); n != 1 {
+		t.Errorf("%d users after migrating, want only the non-demo user", n)
+	}
+}
+
+func TestRemoveDemoAccountsAfterMigration(t *testing.T) {
+	h := newTest(t)
+	ctx := context.Background()
+	// Seeded after the migration has already run, as by a demo seed against
+	// the wrong database.
+	if _, err := service.NewSeedService(repository.NewUnitOfWork(h.db), true).Seed(ctx, service.SeedProfileDemo); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	keptID := h.createUser(t, models.PatientRole, "Ploy").ID
+	sqlDB, err := h.db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := migrations.RemoveDemoAccounts(ctx, sqlDB)
+	if err != nil || removed != 7 {
+		t.Fatalf("removed %d demo accounts (%v), want 7", removed, err)
+	}
+	var ids []string
+	if err := h.db.Model(&models.User{}).Pluck("id", &ids).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 1 || ids[0] != keptID.String() {
+		t.Errorf("users left = %v, want only %s", ids, keptID)
+	}
+	if removed, err := migrations.RemoveDemoAccounts(ctx, sqlDB); err != nil || removed != 0 {
+		t.Errorf("second removal = %d (%v), want nothing left", removed, err)
+	}
+}
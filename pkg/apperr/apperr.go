@@ -2,6 +2,8 @@ package apperr
 
 import (
 	"errors"
+	"log/slog"
+	"user-service/pkg/response"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -15,8 +17,54 @@ const (
 	CodeNotFound
 	CodeConflict
 	CodeInternal
+	CodePreconditionFailed
+	CodePreconditionRequired
+	CodeUnavailable
 )
 
+// codeNames are the machine-readable codes sent to clients. They are part of
+// the API contract: add new ones, never rename existing ones.
+var codeNames = map[Code]string{
+	CodeBadRequest:   "BAD_REQUEST",
+	CodeUnauthorized: "UNAUTHORIZED",
+	CodeForbidden:    "FORBIDDEN",
+	CodeNotFound:     "NOT_FOUND",
+	CodeConflict:     "CONFLICT",
+	CodeInternal:     "INTERNAL_SERVER_ERROR",
+
+	CodePreconditionFailed:   "PRECONDITION_FAILED",
+	CodePreconditionRequired: "PRECONDITION_REQUIRED",
+	CodeUnavailable:          "SERVICE_UNAVAILABLE",
+}
+
+var codeStatuses = map[Code]int{
+	CodeBadRequest:   fiber.StatusBadRequest,
+	CodeUnauthorized: fiber.StatusUnauthorized,
+	CodeForbidden:    fiber.StatusForbidden,
+	CodeNotFound:     fiber.StatusNotFound,
+	CodeConflict:     fiber.StatusConflict,
+	CodeInternal:     fiber.StatusInternalServerError,
+
+	CodePreconditionFailed:   fiber.StatusPreconditionFailed,
+	CodePreconditionRequired: fiber.StatusPreconditionRequired,
+	CodeUnavailable:          fiber.StatusServiceUnavailable,
+}
+
+func (c Code) String() string {
+	if name, ok := codeNames[c]; ok {
+		return name
+	}
+	return codeNames[CodeInternal]
+}
+
+// Status returns the HTTP status for the code.
+func (c Code) Status() int {
+	if status, ok := codeStatuses[c]; ok {
+		return status
+	}
+	return fiber.StatusInternalServerError
+}
+
 type Error struct {
 	Code   Code
 	Msg    string
@@ -36,6 +84,25 @@ func New(code Code, msg string, err error) *Error {
 	return &Error{Code: code, Msg: msg, Err: err}
 }
 
+// Wrap returns err unchanged if it already carries an *Error (for example a
+// translated database error), and otherwise wraps it with code and msg.
+func Wrap(err error, code Code, msg string) error {
+	var ae *Error
+	if errors.As(err, &ae) {
+		return err
+	}
+	return New(code, msg, err)
+}
+
+// WithField attaches a message for a specific request field.
+func (e *Error) WithField(name string, msg any) *Error {
+	if e.Fields == nil {
+		e.Fields = map[string]any{}
+	}
+	e.Fields[name] = msg
+	return e
+}
+
 func IsCode(err error, code Code) bool {
 	var ae *Error
 	if errors.As(err, &ae) {
@@ -46,25 +113,29 @@ func IsCode(err error, code Code) bool {
 
 func WriteError(c *fiber.Ctx, err error) error {
 	var ae *Error
-	status := fiber.StatusInternalServerError
+	code := CodeInternal
 	msg := "internal error"
+	var details interface{}
 
 	if errors.As(err, &ae) {
+		code = ae.Code
 		msg = ae.Msg
-		switch ae.Code {
-		case CodeBadRequest:
-			status = fiber.StatusBadRequest
-		case CodeUnauthorized:
-			status = fiber.StatusUnauthorized
-		case CodeForbidden:
-			status = fiber.StatusForbidden
-		case CodeNotFound:
-			status = fiber.StatusNotFound
-		case CodeConflict:
-			status = fiber.StatusConflict
-		default:
-			status = fiber.StatusInternalServerError
+		if len(ae.Fields) > 0 {
+			details = ae.Fields
 		}
 	}
-	return c.Status(status).JSON(fiber.Map{"error": msg})
+	if code == CodeInternal {
+		slog.ErrorContext(c.UserContext(), msg, "error", err)
+	}
+	return response.Error(c, code.Status(), code.String(), msg, details)
+}
+
+// ErrorHandler is the Fiber error handler, so errors returned by handlers and
+// framework errors (unknown route, body too large, ...) share the envelope.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	var fe *fiber.Error
+	if errors.As(err, &fe) {
+		return response.Failed(c, fe.Code, fe.Message)
+	}
+	return WriteError(c, err)
 }
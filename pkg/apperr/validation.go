@@ -0,0 +1,49 @@
+package apperr
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"user-service/pkg/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const unknownFieldPrefix = "json: unknown field "
+
+// InvalidBody turns a body parsing error (decoding or validation) into a
+// bad request whose Fields describe each offending field, without exposing
+// the raw decoder or validator text.
+func InvalidBody(err error) *Error {
+	e := New(CodeBadRequest, "invalid request body", err)
+
+	if fields := validation.Describe(err); fields != nil {
+		for _, fe := range fields {
+			e.WithField(fe.Field, fe)
+		}
+		return e
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		e.WithField(typeErr.Field, validation.NewFieldError(typeErr.Field, "type", typeErr.Type.String(), typeErr.Type.Kind()))
+	case strings.Contains(err.Error(), unknownFieldPrefix):
+		// encoding/json has no typed error for DisallowUnknownFields
+		_, quoted, _ := strings.Cut(err.Error(), unknownFieldPrefix)
+		if field, uerr := strconv.Unquote(quoted); uerr == nil {
+			e.WithField(field, validation.NewFieldError(field, "unknown", "", reflect.Invalid))
+		}
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		e.Msg = "malformed JSON body"
+	case errors.Is(err, io.EOF):
+		e.Msg = "request body is empty"
+	case errors.Is(err, fiber.ErrUnprocessableEntity):
+		e.Msg = "unsupported content type"
+	}
+	return e
+}
@@ -0,0 +1,46 @@
+// Package buildinfo describes the running binary. Version, Commit and
+// BuildTime can be set at link time:
+//
+//	go build -ldflags "-X user-service/pkg/buildinfo.Version=1.2.0"
+//
+// Commit and BuildTime otherwise fall back to the VCS information that the
+// go command stamps into binaries built inside a git checkout.
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
+)
+
+type Info struct {
+	Version   string
+	Commit    string
+	BuildTime string
+	GoVersion string
+}
+
+// Get returns the build information of the running binary.
+func Get() Info {
+	info := Info{Version: Version, Commit: Commit, BuildTime: BuildTime, GoVersion: runtime.Version()}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range bi.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.time":
+				if info.BuildTime == "" {
+					info.BuildTime = setting.Value
+				}
+			}
+		}
+	}
+	return info
+}
@@ -7,9 +7,22 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"time"
+	"user-service/pkg/logging"
+	"user-service/pkg/metrics"
+	"user-service/pkg/tracing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// clientName labels the client's metrics.
+const clientName = "user_service"
+
 type UserClient struct {
 	baseUrl string
 	hc      *http.Client
@@ -24,7 +37,13 @@ func New(baseUrl string) *UserClient {
 	}
 }
 
-func (c *UserClient) doRequest(ctx context.Context, method, path string, body interface{}, response interface{}) error {
+func (c *UserClient) doRequest(ctx context.Context, method, path string, body interface{}, response interface{}) (err error) {
+	ctx, span := tracing.Start(ctx, "UserClient "+method+" "+path,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.HTTPRequestMethodKey.String(method), semconv.URLPath(path)),
+	)
+	defer tracing.End(span, &err)
+
 	accessToken := contextUtils.GetAccessToken(ctx)
 	if accessToken == "" {
 		return fmt.Errorf("access token is empty")
@@ -33,20 +52,19 @@ func (c *UserClient) doRequest(ctx context.Context, method, path string, body in
 	url := fmt.Sprintf("%s%s", c.baseUrl, path)
 
 	var req *http.Request
-	var err error
 
 	if body != nil {
 		jsonBody, err := json.Marshal(body)
 		if err != nil {
 			return fmt.Errorf("failed to marshal request body: %w", err)
 		}
-		req, err = http.NewRequest(method, url, bytes.NewBuffer(jsonBody))
+		req, err = http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(jsonBody))
 		if err != nil {
 			return fmt.Errorf("failed to create request: %w", err)
 		}
 		req.Header.Set("Content-Type", "application/json")
 	} else {
-		req, err = http.NewRequest(method, url, nil)
+		req, err = http.NewRequestWithContext(ctx, method, url, nil)
 		if err != nil {
 			return fmt.Errorf("failed to create request: %w", err)
 		}
@@ -57,26 +75,38 @@ func (c *UserClient) doRequest(ctx context.Context, method, path string, body in
 		Value: accessToken,
 	})
 
-	fmt.Println("Requesting URL:", url)
-	fmt.Println("With Method:", method)
-	if body != nil {
-		fmt.Println("With Body:", body)
+	if requestID := logging.RequestID(ctx); requestID != "" {
+		req.Header.Set("X-Request-ID", requestID)
 	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
 
+	slog.DebugContext(ctx, "user service request", "method", method, "url", url)
+
+	start := time.Now()
 	resp, err := c.hc.Do(req)
 	if err != nil {
+		metrics.ObserveClientRequest(clientName, method, path, 0, time.Since(start))
 		return fmt.Errorf("failed to execute request: %w", err)
 	}
 	defer resp.Body.Close()
+	metrics.ObserveClientRequest(clientName, method, path, resp.StatusCode, time.Since(start))
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
 	if response != nil {
-		if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+		// responses are wrapped in the {"data": ..., "meta": ...} envelope
+		var envelope struct {
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
 			return fmt.Errorf("failed to decode response: %w", err)
 		}
+		if err := json.Unmarshal(envelope.Data, response); err != nil {
+			return fmt.Errorf("failed to decode response data: %w", err)
+		}
 	}
 
 	return nil
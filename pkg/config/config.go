@@ -1,29 +1,371 @@
+// Package config loads the service configuration. Values come from, in
+// increasing order of precedence: the defaults below, a YAML file, a .env
+// file and the environment. Every setting has an environment variable,
+// named by its env tag; see config.example.yaml for the YAML layout.
 package config
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/url"
 	"os"
+	"reflect"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
 )
 
-func LoadConfig() {
-	_ = godotenv.Load()
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+	EnvTest        = "test"
+
+	// DefaultFile is read when CONFIG_FILE is not set. It is optional.
+	DefaultFile = "config.yaml"
+)
+
+// Defaults for local development. In production the secrets among them are
+// refused; see Validate.
+const (
+	defaultJWTSecret      = "secret"
+	defaultDBPassword     = "password"
+	defaultEncryptionKeys = "1:ZGV2LW9ubHktaW5zZWN1cmUta2VrLWNoYW5nZS1tZSE="
+	defaultBlindIndexKey  = "dev-only-insecure-blind-index-key"
+
+	minProductionSecretLen = 32
+)
+
+type Config struct {
+	// Env is development, test or production.
+	Env string `yaml:"env" env:"APP_ENV"`
+	// Demo allows `app seed --profile=demo` and keeps the demo accounts when
+	// migrating and serving. It is refused in production.
+	Demo bool `yaml:"demo" env:"DEMO_MODE"`
+
+	Server     ServerConfig     `yaml:"server"`
+	Database   DatabaseConfig   `yaml:"database"`
+	Auth       AuthConfig       `yaml:"auth"`
+	Encryption EncryptionConfig `yaml:"encryption"`
+	Retention  RetentionConfig  `yaml:"retention"`
+	Log        LogConfig        `yaml:"log"`
+	Tracing    TracingConfig    `yaml:"tracing"`
+	Clients    ClientsConfig    `yaml:"clients"`
+	FHIR       FHIRConfig       `yaml:"fhir"`
+}
+
+type ServerConfig struct {
+	Port             int           `yaml:"port" env:"APP_PORT"`
+	CORSAllowOrigins string        `yaml:"cors_allow_origins" env:"CORS_ALLOW_ORIGINS"`
+	ShutdownTimeout  time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+	MigrateOnStart   bool          `yaml:"migrate_on_start" env:"MIGRATE_ON_START"`
+}
+
+type DatabaseConfig struct {
+	Host            string        `yaml:"host" env:"DB_HOST"`
+	Port            int           `yaml:"port" env:"DB_PORT"`
+	User            string        `yaml:"user" env:"DB_USER"`
+	Password        string        `yaml:"password" env:"DB_PASSWORD" secret:"true"`
+	Name            string        `yaml:"name" env:"DB_NAME"`
+	SSLMode         string        `yaml:"sslmode" env:"DB_SSLMODE"`
+	LogLevel        string        `yaml:"log_level" env:"DB_LOG_LEVEL"`
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"DB_MAX_OPEN_CONNS"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME"`
+	ConnectAttempts int           `yaml:"connect_attempts" env:"DB_CONNECT_ATTEMPTS"`
+}
+
+type AuthConfig struct {
+	JWTSecret string `yaml:"jwt_secret" env:"JWT_SECRET" secret:"true"`
+	// JWTTTL is the token lifetime in seconds.
+	JWTTTL int `yaml:"jwt_ttl" env:"JWT_TTL"`
+}
+
+type EncryptionConfig struct {
+	// Keys lists the key-encryption keys as "version:base64key,...".
+	Keys          string `yaml:"keys" env:"ENCRYPTION_KEYS" secret:"true"`
+	ActiveKey     int    `yaml:"active_key" env:"ENCRYPTION_ACTIVE_KEY"`
+	BlindIndexKey string `yaml:"blind_index_key" env:"BLIND_INDEX_KEY" secret:"true"`
+}
+
+type RetentionConfig struct {
+	AccountErasureDays int `yaml:"account_erasure_days" env:"ACCOUNT_ERASURE_RETENTION_DAYS"`
+	PurgeDays          int `yaml:"purge_days" env:"PURGE_RETENTION_DAYS"`
+}
+
+type LogConfig struct {
+	Level  string `yaml:"level" env:"LOG_LEVEL"`
+	Format string `yaml:"format" env:"LOG_FORMAT"`
+}
+
+type TracingConfig struct {
+	Exporter    string `yaml:"exporter" env:"TRACING_EXPORTER"`
+	ServiceName string `yaml:"service_name" env:"OTEL_SERVICE_NAME"`
+}
+
+type ClientsConfig struct {
+	UserServiceURL string `yaml:"user_service_url" env:"USER_SERVICE_URL"`
+}
+
+// FHIRConfig names the identifier systems of the FHIR API. They are
+// absolute URIs that partners use to tell this hospital's numbers apart from
+// other hospitals'.
+type FHIRConfig struct {
+	HospitalNumberSystem string `yaml:"hospital_number_system" env:"FHIR_HOSPITAL_NUMBER_SYSTEM"`
+	PractitionerSystem   string `yaml:"practitioner_system" env:"FHIR_PRACTITIONER_SYSTEM"`
+}
+
+// Defaults returns the development configuration.
+func Defaults() *Config {
+	return &Config{
+		Env: EnvDevelopment,
+		Server: ServerConfig{
+			Port:             8000,
+			CORSAllowOrigins: "http://localhost:3000",
+			ShutdownTimeout:  30 * time.Second,
+			MigrateOnStart:   true,
+		},
+		Database: DatabaseConfig{
+			Host:            "localhost",
+			Port:            5432,
+			User:            "user",
+			Password:        defaultDBPassword,
+			Name:            "userdb",
+			SSLMode:         "disable",
+			LogLevel:        "warn",
+			MaxOpenConns:    25,
+			MaxIdleConns:    10,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+			ConnectAttempts: 10,
+		},
+		Auth: AuthConfig{
+			JWTSecret: defaultJWTSecret,
+			JWTTTL:    3600,
+		},
+		Encryption: EncryptionConfig{
+			Keys:          defaultEncryptionKeys,
+			BlindIndexKey: defaultBlindIndexKey,
+		},
+		Retention: RetentionConfig{
+			AccountErasureDays: 30,
+			PurgeDays:          365,
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "json",
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			ServiceName: "sa-user",
+		},
+		Clients: ClientsConfig{
+			UserServiceURL: "http://localhost:8000",
+		},
+		FHIR: FHIRConfig{
+			HospitalNumberSystem: "urn:user-service:hospital-number",
+			PractitionerSystem:   "urn:user-service:doctor-username",
+		},
+	}
+}
+
+// Load reads the configuration and validates it. The YAML file is
+// CONFIG_FILE, or config.yaml if it exists.
+func Load() (*Config, error) {
+	cfg := Defaults()
+
+	path, explicit := os.LookupEnv("CONFIG_FILE")
+	if !explicit {
+		path = DefaultFile
+	}
+	if err := cfg.loadFile(path); err != nil {
+		if explicit || !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	// .env fills in variables that are not already set in the environment.
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("read .env: %w", err)
+	}
+	if err := applyEnv(reflect.ValueOf(cfg).Elem(), os.LookupEnv); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
 }
 
-func Get(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+func (c *Config) loadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
 	}
-	return defaultValue
+	defer f.Close()
+
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	// An empty file is io.EOF; it leaves the configuration unchanged.
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("read %s: %w", path, err)
+	}
+	return nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// applyEnv sets each field with an env tag whose variable is set. Malformed
+// values are errors rather than being ignored.
+func applyEnv(v reflect.Value, lookup func(string) (string, bool)) error {
+	var errs []error
+	for i := 0; i < v.NumField(); i++ {
+		field, sf := v.Field(i), v.Type().Field(i)
+		if field.Kind() == reflect.Struct && field.Type() != durationType {
+			errs = append(errs, applyEnv(field, lookup))
+			continue
+		}
+		name := sf.Tag.Get("env")
+		value, ok := lookup(name)
+		if name == "" || !ok {
+			continue
+		}
+		if err := setField(field, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
 }
 
-func GetInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
+func setField(field reflect.Value, value string) error {
+	if field.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", value)
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int:
 		n, err := strconv.Atoi(value)
-		if err == nil {
-			return n
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		field.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		field.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
+
+// Validate checks every setting and returns all problems at once. In
+// production it also refuses the development secrets.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
 		}
 	}
-	return defaultValue
-}
\ No newline at end of file
+
+	check(oneOf(c.Env, EnvDevelopment, EnvTest, EnvProduction), "APP_ENV must be development, test or production, got %q", c.Env)
+
+	check(c.Server.Port > 0 && c.Server.Port <= 65535, "APP_PORT must be a TCP port, got %d", c.Server.Port)
+	check(c.Server.CORSAllowOrigins != "", "CORS_ALLOW_ORIGINS is required")
+	check(c.Server.ShutdownTimeout > 0, "SHUTDOWN_TIMEOUT must be positive")
+
+	check(c.Database.Host != "", "DB_HOST is required")
+	check(c.Database.Port > 0 && c.Database.Port <= 65535, "DB_PORT must be a TCP port, got %d", c.Database.Port)
+	check(c.Database.User != "", "DB_USER is required")
+	check(c.Database.Name != "", "DB_NAME is required")
+	check(oneOf(c.Database.LogLevel, "silent", "error", "warn", "info"), "DB_LOG_LEVEL must be silent, error, warn or info, got %q", c.Database.LogLevel)
+	check(c.Database.MaxOpenConns >= 0, "DB_MAX_OPEN_CONNS must not be negative")
+	check(c.Database.MaxIdleConns >= 0, "DB_MAX_IDLE_CONNS must not be negative")
+	check(c.Database.MaxOpenConns == 0 || c.Database.MaxIdleConns <= c.Database.MaxOpenConns, "DB_MAX_IDLE_CONNS must not exceed DB_MAX_OPEN_CONNS")
+	check(c.Database.ConnMaxLifetime >= 0, "DB_CONN_MAX_LIFETIME must not be negative")
+	check(c.Database.ConnMaxIdleTime >= 0, "DB_CONN_MAX_IDLE_TIME must not be negative")
+	check(c.Database.ConnectAttempts >= 1, "DB_CONNECT_ATTEMPTS must be at least 1")
+
+	check(c.Auth.JWTSecret != "", "JWT_SECRET is required")
+	check(c.Auth.JWTTTL > 0, "JWT_TTL must be positive")
+
+	check(c.Encryption.Keys != "", "ENCRYPTION_KEYS is required")
+	check(c.Encryption.BlindIndexKey != "", "BLIND_INDEX_KEY is required")
+
+	check(c.Retention.AccountErasureDays > 0, "ACCOUNT_ERASURE_RETENTION_DAYS must be positive")
+	check(c.Retention.PurgeDays > 0, "PURGE_RETENTION_DAYS must be positive")
+
+	var level slog.Level
+	check(level.UnmarshalText([]byte(c.Log.Level)) == nil, "LOG_LEVEL must be debug, info, warn or error, got %q", c.Log.Level)
+	check(oneOf(c.Log.Format, "json", "text"), "LOG_FORMAT must be json or text, got %q", c.Log.Format)
+	check(oneOf(c.Tracing.Exporter, "none", "stdout", "otlp"), "TRACING_EXPORTER must be none, stdout or otlp, got %q", c.Tracing.Exporter)
+	check(absoluteURI(c.FHIR.HospitalNumberSystem), "FHIR_HOSPITAL_NUMBER_SYSTEM must be an absolute URI, got %q", c.FHIR.HospitalNumberSystem)
+	check(absoluteURI(c.FHIR.PractitionerSystem), "FHIR_PRACTITIONER_SYSTEM must be an absolute URI, got %q", c.FHIR.PractitionerSystem)
+
+	if c.Env == EnvProduction {
+		check(c.Auth.JWTSecret != defaultJWTSecret && len(c.Auth.JWTSecret) >= minProductionSecretLen,
+			"JWT_SECRET must be set to a random value of at least %d bytes in production", minProductionSecretLen)
+		check(c.Database.Password != defaultDBPassword, "DB_PASSWORD must be changed from the default in production")
+		check(c.Encryption.Keys != defaultEncryptionKeys, "ENCRYPTION_KEYS must be changed from the development key in production")
+		check(c.Encryption.BlindIndexKey != defaultBlindIndexKey, "BLIND_INDEX_KEY must be changed from the development key in production")
+		check(c.Server.CORSAllowOrigins != "*", "CORS_ALLOW_ORIGINS must list origins in production")
+		check(!c.Demo, "DEMO_MODE must be off in production")
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration:\n%w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// Redacted returns the configuration as YAML with secrets masked, for
+// printing.
+func (c *Config) Redacted() string {
+	copied := *c
+	redact(reflect.ValueOf(&copied).Elem())
+	var out strings.Builder
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(&copied); err != nil {
+		return fmt.Sprintf("marshal config: %v", err)
+	}
+	return out.String()
+}
+
+func redact(v reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		field, sf := v.Field(i), v.Type().Field(i)
+		switch {
+		case field.Kind() == reflect.Struct && field.Type() != durationType:
+			redact(field)
+		case sf.Tag.Get("secret") == "true" && field.String() != "":
+			field.SetString("[REDACTED]")
+		}
+	}
+}
+
+func oneOf(value string, allowed ...string) bool {
+	return slices.Contains(allowed, value)
+}
+
+func absoluteURI(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && u.Scheme != ""
+}
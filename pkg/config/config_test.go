@@ -0,0 +1,107 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"user-service/pkg/config"
+)
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	t.Setenv("CONFIG_FILE", writeFile(t, "database:\n  host: yaml-host\n  port: 6543\nserver:\n  shutdown_timeout: 10s\n"))
+	t.Setenv("DB_HOST", "env-host")
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Database.Host != "env-host" {
+		t.Errorf("Database.Host = %q, want the environment to win over the file", cfg.Database.Host)
+	}
+	if cfg.Database.Port != 6543 {
+		t.Errorf("Database.Port = %d, want 6543 from the file", cfg.Database.Port)
+	}
+	if cfg.Server.ShutdownTimeout.String() != "10s" {
+		t.Errorf("Server.ShutdownTimeout = %v, want 10s from the file", cfg.Server.ShutdownTimeout)
+	}
+	if cfg.Database.Name != "userdb" {
+		t.Errorf("Database.Name = %q, want the default", cfg.Database.Name)
+	}
+}
+
+func TestLoadRejectsInvalidInput(t *testing.T) {
+	for _, tc := range []struct {
+		name, file, env, value, want string
+	}{
+		{name: "malformed integer", env: "JWT_TTL", value: "an hour", want: "JWT_TTL"},
+		{name: "malformed duration", env: "SHUTDOWN_TIMEOUT", value: "30", want: "SHUTDOWN_TIMEOUT"},
+		{name: "out of range", env: "DB_PORT", value: "70000", want: "DB_PORT"},
+		{name: "relative URI", env: "FHIR_HOSPITAL_NUMBER_SYSTEM", value: "hn", want: "FHIR_HOSPITAL_NUMBER_SYSTEM"},
+		{name: "unknown key", file: "databse:\n  host: typo\n", want: "databse"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("CONFIG_FILE", writeFile(t, tc.file))
+			if tc.env != "" {
+				t.Setenv(tc.env, tc.value)
+			}
+			_, err := config.Load()
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("Load error = %v, want one mentioning %s", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestProductionRefusesDevelopmentSecrets(t *testing.T) {
+	cfg := config.Defaults()
+	cfg.Env = config.EnvProduction
+	cfg.Demo = true
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Validate accepted the development secrets in production")
+	}
+	for _, name := range []string{"JWT_SECRET", "DB_PASSWORD", "ENCRYPTION_KEYS", "BLIND_INDEX_KEY", "DEMO_MODE"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("Validate error does not mention %s: %v", name, err)
+		}
+	}
+
+	cfg.Auth.JWTSecret = strings.Repeat("s", 32)
+	cfg.Database.Password = "a-real-password"
+	cfg.Encryption.Keys = "1:cHJvZHVjdGlvbi1rZXktZm9yLXRoZS10ZXN0cy0zMmI="
+	cfg.Encryption.BlindIndexKey = "production-blind-index-key"
+	cfg.Demo = false
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+}
+
+func TestRedactedHidesSecrets(t *testing.T) {
+	cfg := config.Defaults()
+	cfg.Auth.JWTSecret = "jwt-secret-value"
+	cfg.Database.Password = "db-password-value"
+
+	dump := cfg.Redacted()
+	for _, secret := range []string{"jwt-secret-value", "db-password-value", cfg.Encryption.Keys, cfg.Encryption.BlindIndexKey} {
+		if strings.Contains(dump, secret) {
+			t.Errorf("Redacted output contains %q:\n%s", secret, dump)
+		}
+	}
+	if !strings.Contains(dump, "host: localhost") {
+		t.Errorf("Redacted output is missing non-secret settings:\n%s", dump)
+	}
+	if cfg.Auth.JWTSecret != "jwt-secret-value" {
+		t.Error("Redacted modified the configuration")
+	}
+}
@@ -3,9 +3,10 @@ package contextUtils
 import (
 	"context"
 	// "time"
-	"user-service/pkg/response"
+	"user-service/pkg/apperr"
+	"user-service/pkg/logging"
+	"user-service/pkg/validation"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -17,31 +18,37 @@ const (
 	ContextKeyAccessToken contextKey = "accessToken"
 )
 
+var validate = validation.New()
+
 func WithBody[T any]() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		var body T
 		if err := c.BodyParser(&body); err != nil {
-			return response.BadRequest(c, "Cannot parse JSON")
+			return apperr.WriteError(c, apperr.InvalidBody(err))
 		}
-		validate := validator.New()
 		if err := validate.Struct(body); err != nil {
-			return response.BadRequest(c, err.Error())
+			return apperr.WriteError(c, apperr.InvalidBody(err))
 		}
 		c.Locals("body", body)
 		return c.Next()
 	}
 }
 
+// GetUserId returns the authenticated user's ID, or "" on a request made
+// without a token.
 func GetUserId(c context.Context) string {
-	return c.Value(ContextKeyUserID).(string)
+	s, _ := c.Value(ContextKeyUserID).(string)
+	return s
 }
 
 func GetRole(c context.Context) string {
-	return c.Value(ContextKeyRole).(string)
+	s, _ := c.Value(ContextKeyRole).(string)
+	return s
 }
 
 func GetAccessToken(c context.Context) string {
-	return c.Value(ContextKeyAccessToken).(string)
+	s, _ := c.Value(ContextKeyAccessToken).(string)
+	return s
 }
 
 func GetContext(c *fiber.Ctx) context.Context {
@@ -54,6 +61,9 @@ func GetContext(c *fiber.Ctx) context.Context {
 	if s, ok := role.(string); ok {
 		ctx = context.WithValue(ctx, ContextKeyRole, s)
 	}
+	if requestID := c.GetRespHeader(fiber.HeaderXRequestID); requestID != "" {
+		ctx = logging.WithRequestID(ctx, requestID)
+	}
 	token := c.Locals("accessToken")
 	if s, ok := token.(string); ok {
 		ctx = context.WithValue(ctx, ContextKeyAccessToken, s)
@@ -1,9 +1,9 @@
 package db
 
 import (
+	"context"
 	"fmt"
-	"log"
-	"os"
+	"log/slog"
 	"time"
 
 	"gorm.io/driver/postgres"
@@ -11,6 +11,11 @@ import (
 	"gorm.io/gorm/logger"
 )
 
+const (
+	initialRetryDelay = 500 * time.Millisecond
+	maxRetryDelay     = 10 * time.Second
+)
+
 type Config struct {
 	Host     string
 	Port     int
@@ -18,25 +23,73 @@ type Config struct {
 	Password string
 	Dbname   string
 	Sslmode  string
+	// Logger receives GORM's logs; see NewLogger.
+	Logger logger.Interface
+
+	// Connection pool settings; zero leaves the database/sql default.
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+
+	// ConnectAttempts is how many times Open tries to reach the database,
+	// backing off exponentially between attempts. Zero means once.
+	ConnectAttempts int
 }
 
-func Open(cfg Config) *gorm.DB {
-	newLogger := logger.New(
-		log.New(os.Stdout, "\r\n", log.LstdFlags), // io writer
-		logger.Config{
-			SlowThreshold: time.Second, // Slow SQL threshold
-			LogLevel:      logger.Info, // Log level
-			Colorful:      true,        // Enable color
-		},
-	)
+// Open connects to the database and configures the connection pool. While
+// the database is unreachable, for example while it is still starting, it
+// retries with backoff until ConnectAttempts is exhausted or ctx is done.
+func Open(ctx context.Context, cfg Config) (*gorm.DB, error) {
 	con := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s", cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Dbname, cfg.Sslmode)
-	db, err := gorm.Open(postgres.Open(con), &gorm.Config{
-		Logger: newLogger,
-	})
 
+	var db *gorm.DB
+	var err error
+	delay := initialRetryDelay
+	for attempt := 1; ; attempt++ {
+		// Failed attempts are logged below; keep GORM from logging them too.
+		db, err = gorm.Open(postgres.Open(con), &gorm.Config{
+			Logger: logger.Discard,
+		})
+		if err == nil {
+			break
+		}
+		if attempt >= cfg.ConnectAttempts {
+			return nil, fmt.Errorf("connect to database after %d attempts: %w", attempt, err)
+		}
+		slog.WarnContext(ctx, "database unavailable, retrying", "attempt", attempt, "retry_in", delay, "error", err)
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("connect to database: %w", ctx.Err())
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxRetryDelay)
+	}
+
+	db.Logger = cfg.Logger
+	if db.Logger == nil {
+		db.Logger = logger.Default
+	}
+
+	sqlDB, err := db.DB()
 	if err != nil {
-		panic("failed to connect to database")
+		return nil, fmt.Errorf("get *sql.DB: %w", err)
+	}
+	if cfg.MaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	}
+	if cfg.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	}
+	if cfg.ConnMaxLifetime > 0 {
+		sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	}
+	if cfg.ConnMaxIdleTime > 0 {
+		sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
 	}
 
-	return db
+	if err := db.Use(TracingPlugin{}); err != nil {
+		return nil, fmt.Errorf("register tracing plugin: %w", err)
+	}
+	return db, nil
 }
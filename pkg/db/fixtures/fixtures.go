@@ -0,0 +1,76 @@
+// Package fixtures embeds the data loaded by `app seed`. Each profile is a
+// YAML file named after it, so new seed data needs no code change.
+package fixtures
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed *.yaml
+var FS embed.FS
+
+// Fixture is the content of one profile file.
+type Fixture struct {
+	// Password is given to every account in the fixture.
+	Password               string    `yaml:"password"`
+	HealthcareEntitlements []string  `yaml:"healthcare_entitlements"`
+	Patients               []Patient `yaml:"patients"`
+	Doctors                []Doctor  `yaml:"doctors"`
+	Admins                 []Admin   `yaml:"admins"`
+}
+
+// Account holds the users columns shared by every role.
+type Account struct {
+	ID          uuid.UUID `yaml:"id"`
+	FirstName   string    `yaml:"first_name"`
+	LastName    string    `yaml:"last_name"`
+	Gender      string    `yaml:"gender"`
+	PhoneNumber string    `yaml:"phone_number"`
+}
+
+type Patient struct {
+	Account                `yaml:",inline"`
+	HospitalID             string     `yaml:"hospital_id"`
+	BirthDate              *time.Time `yaml:"birth_date"`
+	IDCardNumber           *string    `yaml:"id_card_number"`
+	Address                *string    `yaml:"address"`
+	Allergies              *string    `yaml:"allergies"`
+	EmergencyContact       *string    `yaml:"emergency_contact"`
+	BloodType              *string    `yaml:"blood_type"`
+	HealthcareEntitlements []string   `yaml:"healthcare_entitlements"`
+}
+
+type Doctor struct {
+	Account         `yaml:",inline"`
+	Username        string  `yaml:"username"`
+	Specialty       *string `yaml:"specialty"`
+	Bio             *string `yaml:"bio"`
+	YearsExperience *int    `yaml:"years_experience"`
+}
+
+type Admin struct {
+	Account  `yaml:",inline"`
+	Username string `yaml:"username"`
+}
+
+// Load reads the fixture for profile. Unknown keys are rejected so a typo
+// does not silently drop data.
+func Load(profile string) (*Fixture, error) {
+	data, err := FS.ReadFile(profile + ".yaml")
+	if err != nil {
+		return nil, fmt.Errorf("fixture %q: %w", profile, err)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var fixture Fixture
+	if err := dec.Decode(&fixture); err != nil {
+		return nil, fmt.Errorf("fixture %q: %w", profile, err)
+	}
+	return &fixture, nil
+}
@@ -0,0 +1,27 @@
+package fixtures_test
+
+import (
+	"testing"
+	"user-service/pkg/db/fixtures"
+	"user-service/pkg/validation"
+)
+
+// TestFixturesPassValidation checks the seeded patients against the rules
+// registration applies, so demo data can also be entered through the API.
+func TestFixturesPassValidation(t *testing.T) {
+	validate := validation.New()
+	for _, profile := range []string{"reference", "demo"} {
+		fixture, err := fixtures.Load(profile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, patient := range fixture.Patients {
+			if patient.IDCardNumber == nil {
+				continue
+			}
+			if err := validate.Var(*patient.IDCardNumber, "thai_id"); err != nil {
+				t.Errorf("%s: patient %s has an invalid ID card number %s", profile, patient.ID, *patient.IDCardNumber)
+			}
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// ParseLogLevel parses a GORM log level: silent, error, warn or info. At info
+// every statement is logged.
+func ParseLogLevel(s string) (logger.LogLevel, error) {
+	switch strings.ToLower(s) {
+	case "silent":
+		return logger.Silent, nil
+	case "error":
+		return logger.Error, nil
+	case "warn", "":
+		return logger.Warn, nil
+	case "info":
+		return logger.Info, nil
+	}
+	return 0, fmt.Errorf("invalid DB log level %q", s)
+}
+
+// unboundPlaceholder matches the "$1$" GORM's Explain leaves for a
+// placeholder it has no value for.
+var unboundPlaceholder = regexp.MustCompile(`\$(\d+)\$`)
+
+// slogLogger sends GORM's logs to slog. Statements are logged with their
+// placeholders, never with the bound values, which hold patient data.
+type slogLogger struct {
+	log           *slog.Logger
+	level         logger.LogLevel
+	slowThreshold time.Duration
+}
+
+// NewLogger returns a GORM logger writing to l at level. Statements slower
+// than slowThreshold are logged as warnings.
+func NewLogger(l *slog.Logger, level logger.LogLevel, slowThreshold time.Duration) logger.Interface {
+	return &slogLogger{log: l, level: level, slowThreshold: slowThreshold}
+}
+
+func (l *slogLogger) LogMode(level logger.LogLevel) logger.Interface {
+	clone := *l
+	clone.level = level
+	return &clone
+}
+
+func (l *slogLogger) Info(ctx context.Context, msg string, data ...any) {
+	if l.level >= logger.Info {
+		l.log.InfoContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *slogLogger) Warn(ctx context.Context, msg string, data ...any) {
+	if l.level >= logger.Warn {
+		l.log.WarnContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *slogLogger) Error(ctx context.Context, msg string, data ...any) {
+	if l.level >= logger.Error {
+		l.log.ErrorContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *slogLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	statement := func() []any {
+		sql, rows := fc()
+		return []any{"sql", unboundPlaceholder.ReplaceAllString(sql, "$$$1"), "rows", rows, "elapsed", elapsed}
+	}
+	switch {
+	case err != nil && l.level >= logger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		l.log.ErrorContext(ctx, "sql failed", append(statement(), "error", err)...)
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && l.level >= logger.Warn:
+		l.log.WarnContext(ctx, "slow sql", statement()...)
+	case l.level >= logger.Info:
+		l.log.InfoContext(ctx, "sql", statement()...)
+	}
+}
+
+// ParamsFilter implements gorm.ParamsFilter so that fc in Trace returns the
+// statement without its bound values.
+func (l *slogLogger) ParamsFilter(_ context.Context, sql string, _ ...any) (string, []any) {
+	return sql, nil
+}
@@ -0,0 +1,153 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+	"user-service/pkg/db/migrations"
+
+	"github.com/pressly/goose/v3"
+)
+
+func setupGoose() error {
+	goose.SetBaseFS(migrations.FS)
+	if err := goose.SetDialect("postgres"); err != nil {
+		return fmt.Errorf("goose set dialect: %w", err)
+	}
+	return nil
+}
+
+// MigrateUp applies all up migrations embedded in the binary.
+func MigrateUp(ctx context.Context, sqlDB *sql.DB) error {
+	if err := setupGoose(); err != nil {
+		return err
+	}
+	// directory path is relative to the root of the embedded FS
+	if err := goose.UpContext(ctx, sqlDB, "."); err != nil {
+		return fmt.Errorf("goose up: %w", err)
+	}
+	return nil
+}
+
+// MigrateDown rolls back the most recently applied migration.
+func MigrateDown(ctx context.Context, sqlDB *sql.DB) error {
+	if err := setupGoose(); err != nil {
+		return err
+	}
+	if err := goose.DownContext(ctx, sqlDB, "."); err != nil {
+		return fmt.Errorf("goose down: %w", err)
+	}
+	return nil
+}
+
+// MigrateRedo rolls back the most recently applied migration and applies it
+// again.
+func MigrateRedo(ctx context.Context, sqlDB *sql.DB) error {
+	if err := setupGoose(); err != nil {
+		return err
+	}
+	if err := goose.RedoContext(ctx, sqlDB, "."); err != nil {
+		return fmt.Errorf("goose redo: %w", err)
+	}
+	return nil
+}
+
+// MigrationStatus is the state of one embedded migration in the database.
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// newProvider returns a goose provider for the embedded migrations. Unlike
+// the package-level goose functions it keeps no global state, so it is safe
+// to use from concurrent requests.
+func newProvider(sqlDB *sql.DB) (*goose.Provider, error) {
+	provider, err := goose.NewProvider(goose.DialectPostgres, sqlDB, migrations.FS)
+	if err != nil {
+		return nil, fmt.Errorf("goose provider: %w", err)
+	}
+	return provider, nil
+}
+
+// Status lists every embedded migration, oldest first, with whether and when
+// it was applied.
+func Status(ctx context.Context, sqlDB *sql.DB) ([]MigrationStatus, error) {
+	provider, err := newProvider(sqlDB)
+	if err != nil {
+		return nil, err
+	}
+	results, err := provider.Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("goose status: %w", err)
+	}
+	statuses := make([]MigrationStatus, 0, len(results))
+	for _, res := range results {
+		statuses = append(statuses, MigrationStatus{
+			Version:   res.Source.Version,
+			Name:      filepath.Base(res.Source.Path),
+			Applied:   res.State == goose.StateApplied,
+			AppliedAt: res.AppliedAt,
+		})
+	}
+	return statuses, nil
+}
+
+// CreateMigration writes a new, timestamped migration skeleton into dir,
+// which should be the migrations package in the source tree; kind is "sql"
+// or "go".
+func CreateMigration(dir, name, kind string) error {
+	if err := goose.Create(nil, dir, name, kind); err != nil {
+		return fmt.Errorf("goose create: %w", err)
+	}
+	return nil
+}
+
+// SchemaVersion returns the goose version the database is at. It only
+// reads the version table, which must already exist.
+func SchemaVersion(ctx context.Context, sqlDB *sql.DB) (int64, error) {
+	provider, err := newProvider(sqlDB)
+	if err != nil {
+		return 0, err
+	}
+	version, err := provider.GetDBVersion(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("goose version: %w", err)
+	}
+	return version, nil
+}
+
+// LatestSchemaVersion returns the version of the newest migration embedded
+// in the binary.
+func LatestSchemaVersion(sqlDB *sql.DB) (int64, error) {
+	provider, err := newProvider(sqlDB)
+	if err != nil {
+		return 0, err
+	}
+	sources := provider.ListSources()
+	if len(sources) == 0 {
+		return 0, errors.New("no migrations embedded")
+	}
+	return sources[len(sources)-1].Version, nil
+}
+
+// CheckSchemaVersion returns an error unless every embedded migration has
+// been applied.
+func CheckSchemaVersion(ctx context.Context, sqlDB *sql.DB) error {
+	current, err := SchemaVersion(ctx, sqlDB)
+	if err != nil {
+		return err
+	}
+	latest, err := LatestSchemaVersion(sqlDB)
+	if err != nil {
+		return err
+	}
+	if current != latest {
+		return fmt.Errorf("schema at version %d, want %d", current, latest)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Trigram indexes back fuzzy matching (similarity, %, ILIKE) on names,
+-- which works for Thai where dictionary-based stemming does not.
+CREATE INDEX IF NOT EXISTS idx_users_first_name_trgm ON users USING gin (first_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_users_last_name_trgm ON users USING gin (last_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_patients_hospital_id_trgm ON patients USING gin (hospital_id gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_doctors_username_trgm ON doctors USING gin (username gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_doctors_specialty_trgm ON doctors USING gin (specialty gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_doctors_bio_trgm ON doctors USING gin (bio gin_trgm_ops);
+
+-- Full-text indexes use the 'simple' configuration: no stemming, so Thai
+-- and English tokens are matched as written.
+CREATE INDEX IF NOT EXISTS idx_users_name_fts ON users
+  USING gin (to_tsvector('simple', first_name || ' ' || last_name));
+CREATE INDEX IF NOT EXISTS idx_doctors_profile_fts ON doctors
+  USING gin (to_tsvector('simple', username || ' ' || coalesce(specialty, '') || ' ' || coalesce(bio, '')));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_doctors_profile_fts;
+DROP INDEX IF EXISTS idx_users_name_fts;
+DROP INDEX IF EXISTS idx_doctors_bio_trgm;
+DROP INDEX IF EXISTS idx_doctors_specialty_trgm;
+DROP INDEX IF EXISTS idx_doctors_username_trgm;
+DROP INDEX IF EXISTS idx_patients_hospital_id_trgm;
+DROP INDEX IF EXISTS idx_users_last_name_trgm;
+DROP INDEX IF EXISTS idx_users_first_name_trgm;
+DROP EXTENSION IF EXISTS pg_trgm;
+-- +goose StatementEnd
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE deletion_request_status AS ENUM ('pending','approved','rejected');
+
+-- Deletion requests never cascade: the user row is soft-deleted on approval
+-- and later anonymized in place so its UUID stays valid for other services.
+CREATE TABLE account_deletion_requests (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id uuid NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+  status deletion_request_status NOT NULL DEFAULT 'pending',
+  reason text,
+  reviewed_by uuid REFERENCES users(id) ON DELETE SET NULL,
+  review_note text,
+  requested_at timestamptz NOT NULL DEFAULT now(),
+  reviewed_at timestamptz,
+  anonymized_at timestamptz,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+-- At most one open (pending or approved) request per user.
+CREATE UNIQUE INDEX idx_account_deletion_requests_open
+  ON account_deletion_requests (user_id)
+  WHERE status IN ('pending','approved');
+
+CREATE INDEX idx_account_deletion_requests_status ON account_deletion_requests (status, reviewed_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS account_deletion_requests;
+DROP TYPE IF EXISTS deletion_request_status;
+-- +goose StatementEnd
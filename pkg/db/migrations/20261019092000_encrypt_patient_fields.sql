@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Encrypted envelopes are far longer than 13 characters, so the ID card
+-- column becomes text and its length is enforced by request validation.
+ALTER TABLE patients DROP CONSTRAINT IF EXISTS patients_id_card_len;
+ALTER TABLE patients ALTER COLUMN id_card_number TYPE text;
+
+-- HMAC of the plaintext ID card number, used for equality lookups.
+ALTER TABLE patients ADD COLUMN id_card_blind_index text;
+CREATE INDEX idx_patients_id_card_blind_index ON patients (id_card_blind_index);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- The key is not available to SQL, so encrypted values cannot be restored
+-- here. Refuse to go down while any remain; with only plaintext values the
+-- original varchar(13) type and length check are restored.
+DO $$
+BEGIN
+  IF EXISTS (
+    SELECT 1 FROM patients
+    WHERE id_card_number LIKE 'enc:%' OR address LIKE 'enc:%'
+       OR allergies LIKE 'enc:%' OR emergency_contact LIKE 'enc:%'
+  ) THEN
+    RAISE EXCEPTION 'patients hold encrypted values; decrypt them before migrating below 20261019092000';
+  END IF;
+END
+$$;
+DROP INDEX IF EXISTS idx_patients_id_card_blind_index;
+ALTER TABLE patients DROP COLUMN IF EXISTS id_card_blind_index;
+ALTER TABLE patients ALTER COLUMN id_card_number TYPE varchar(13);
+ALTER TABLE patients ADD CONSTRAINT patients_id_card_len
+  CHECK (id_card_number IS NULL OR length(id_card_number)=13);
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- One active patient per national ID card number. The blind index stands in
+-- for the encrypted column; soft-deleted and anonymized rows are excluded.
+DROP INDEX IF EXISTS idx_patients_id_card_blind_index;
+CREATE UNIQUE INDEX idx_patients_id_card_blind_index ON patients (id_card_blind_index)
+  WHERE deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_patients_id_card_blind_index;
+CREATE INDEX idx_patients_id_card_blind_index ON patients (id_card_blind_index);
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Row versions for optimistic concurrency: every versioned update matches on
+-- the version it read and increments it, and the profile ETag is built from them.
+ALTER TABLE users ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE patients ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE doctors ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE doctors DROP COLUMN version;
+ALTER TABLE patients DROP COLUMN version;
+ALTER TABLE users DROP COLUMN version;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- One row per field changed by a profile update. Values are encrypted by the
+-- application like the patient columns they come from. The history goes with
+-- the user when the user row is purged, and survives its actor.
+CREATE TABLE profile_changes (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  field text NOT NULL,
+  old_value text,
+  new_value text,
+  actor_id uuid REFERENCES users(id) ON DELETE SET NULL,
+  actor_role roles NOT NULL,
+  changed_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_profile_changes_user_id ON profile_changes (user_id, changed_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS profile_changes;
+-- +goose StatementEnd
@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Who read or wrote which patient's data. Each entry carries the hash of the
+-- previous one, so editing, reordering or removing entries breaks the chain;
+-- the triggers below make the table append-only for the application role.
+-- Actor and target IDs deliberately have no foreign keys: the log outlives
+-- purged users.
+CREATE TABLE audit_log (
+  id bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  occurred_at timestamptz NOT NULL,
+  actor_id uuid,
+  actor_role text NOT NULL DEFAULT '',
+  action text NOT NULL,
+  target_ids jsonb NOT NULL DEFAULT '[]',
+  request_id text NOT NULL DEFAULT '',
+  ip text NOT NULL DEFAULT '',
+  outcome text NOT NULL,
+  status integer NOT NULL,
+  prev_hash text NOT NULL,
+  hash text NOT NULL UNIQUE
+);
+
+CREATE INDEX idx_audit_log_occurred_at ON audit_log (occurred_at);
+CREATE INDEX idx_audit_log_actor_id ON audit_log (actor_id);
+CREATE INDEX idx_audit_log_target_ids ON audit_log USING gin (target_ids jsonb_path_ops);
+
+CREATE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_no_update_delete BEFORE UPDATE OR DELETE ON audit_log
+  FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
+CREATE TRIGGER audit_log_no_truncate BEFORE TRUNCATE ON audit_log
+  FOR EACH STATEMENT EXECUTE FUNCTION audit_log_append_only();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS audit_log;
+DROP FUNCTION IF EXISTS audit_log_append_only();
+-- +goose StatementEnd
@@ -0,0 +1,80 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upRemoveDemoAccounts, downRemoveDemoAccounts)
+}
+
+// demoAccountIDs are the patients, doctors and admin that the master data
+// migration inserts into every environment with a shared, published
+// password. That migration has shipped and is left as it is; this one deletes
+// the accounts again, and the demo seed fixture restores them where wanted.
+const demoAccountIDs = `
+	'01920e5a-1234-7890-abcd-000000000001',
+	'01920e5a-1234-7890-abcd-000000000002',
+	'01920e5a-1234-7890-abcd-000000000003',
+	'01920e5a-1234-7890-abcd-000000000004',
+	'01920e5a-1234-7890-abcd-000000000005',
+	'01920e5a-1234-7890-abcd-000000000006',
+	'01920e5a-1234-7890-abcd-000000000007'`
+
+// masterDataPasswordHash is the password the master data migration gave the
+// demo accounts; accounts loaded from the demo fixture have another.
+const masterDataPasswordHash = "$argon2id$v=19$m=65536,t=3,p=4$KZPKCgTQjZUHK4A0aI72KQ$KoQcuUnR4HNcaO7364bX56V9KYE35S1I9LPQeQpXVbs"
+
+// upRemoveDemoAccounts deletes the demo accounts. In demo mode it keeps those
+// loaded from the demo fixture and deletes only the ones the master data
+// migration inserted, whose ID card numbers fail the checksum; `app seed
+// --profile=demo` loads them again.
+func upRemoveDemoAccounts(ctx context.Context, tx *sql.Tx) error {
+	if demoMode(ctx) {
+		_, err := removeDemoAccounts(ctx, tx, "password = $1", masterDataPasswordHash)
+		return err
+	}
+	_, err := removeDemoAccounts(ctx, tx, "true")
+	return err
+}
+
+// RemoveDemoAccounts deletes the demo accounts and returns how many users it
+// removed. The migration runs once, so `serve` calls this on every start
+// outside demo mode: a later `seed --profile=demo` or a restored demo dump
+// would otherwise leave accounts with a published password.
+func RemoveDemoAccounts(ctx context.Context, db *sql.DB) (int64, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	removed, err := removeDemoAccounts(ctx, tx, "true")
+	if err != nil {
+		return 0, err
+	}
+	return removed, tx.Commit()
+}
+
+// removeDemoAccounts deletes the demo accounts in tx whose users row matches
+// filter. Deleting the users rows cascades to their role rows, entitlements
+// and profile history; deletion requests do not cascade.
+func removeDemoAccounts(ctx context.Context, tx *sql.Tx, filter string, args ...any) (int64, error) {
+	demoUsers := `SELECT id FROM users WHERE id IN (` + demoAccountIDs + `) AND ` + filter
+	if _, err := tx.ExecContext(ctx, `DELETE FROM account_deletion_requests WHERE user_id IN (`+demoUsers+`)`, args...); err != nil {
+		return 0, err
+	}
+	res, err := tx.ExecContext(ctx, `DELETE FROM users WHERE id IN (`+demoUsers+`)`, args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// downRemoveDemoAccounts does nothing: `app seed --profile=demo` restores the
+// demo accounts where they are wanted.
+func downRemoveDemoAccounts(ctx context.Context, tx *sql.Tx) error {
+	return nil
+}
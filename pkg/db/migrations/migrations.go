@@ -0,0 +1,25 @@
+// Package migrations embeds the goose SQL migrations and registers the Go
+// ones, so the server binary and the integration tests apply exactly the same
+// schema.
+package migrations
+
+import (
+	"context"
+	"embed"
+)
+
+//go:embed *.sql
+var FS embed.FS
+
+type demoModeKey struct{}
+
+// WithDemoMode returns a context under which migrations keep the demo
+// accounts loaded by `app seed --profile=demo`.
+func WithDemoMode(ctx context.Context, on bool) context.Context {
+	return context.WithValue(ctx, demoModeKey{}, on)
+}
+
+func demoMode(ctx context.Context) bool {
+	on, _ := ctx.Value(demoModeKey{}).(bool)
+	return on
+}
@@ -0,0 +1,68 @@
+package db
+
+import (
+	"errors"
+	"user-service/pkg/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const spanKey = "tracing:span"
+
+// TracingPlugin is a GORM plugin that wraps every statement in a client span,
+// a child of the span in the statement's context. The span carries the
+// statement with its placeholders, never the bound values.
+type TracingPlugin struct{}
+
+func (TracingPlugin) Name() string { return "tracing" }
+
+func (TracingPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("tracing:before_create", startSpan("create")),
+		cb.Create().After("gorm:create").Register("tracing:after_create", endSpan),
+		cb.Query().Before("gorm:query").Register("tracing:before_query", startSpan("query")),
+		cb.Query().After("gorm:query").Register("tracing:after_query", endSpan),
+		cb.Update().Before("gorm:update").Register("tracing:before_update", startSpan("update")),
+		cb.Update().After("gorm:update").Register("tracing:after_update", endSpan),
+		cb.Delete().Before("gorm:delete").Register("tracing:before_delete", startSpan("delete")),
+		cb.Delete().After("gorm:delete").Register("tracing:after_delete", endSpan),
+		cb.Row().Before("gorm:row").Register("tracing:before_row", startSpan("row")),
+		cb.Row().After("gorm:row").Register("tracing:after_row", endSpan),
+		cb.Raw().Before("gorm:raw").Register("tracing:before_raw", startSpan("raw")),
+		cb.Raw().After("gorm:raw").Register("tracing:after_raw", endSpan),
+	)
+}
+
+func startSpan(operation string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		ctx, span := tracing.Start(tx.Statement.Context, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemNamePostgreSQL, semconv.DBOperationName(operation)),
+		)
+		tx.Statement.Context = ctx
+		tx.InstanceSet(spanKey, span)
+	}
+}
+
+func endSpan(tx *gorm.DB) {
+	value, ok := tx.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	span := value.(trace.Span)
+	defer span.End()
+
+	span.SetAttributes(
+		semconv.DBCollectionName(tx.Statement.Table),
+		semconv.DBQueryText(tx.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", tx.Statement.RowsAffected),
+	)
+	if err := tx.Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		span.SetStatus(codes.Error, "statement failed")
+	}
+}
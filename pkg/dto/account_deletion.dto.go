@@ -0,0 +1,27 @@
+package dto
+
+import "time"
+
+type RequestAccountDeletionRequestDto struct {
+	Reason *string `json:"reason,omitempty" validate:"omitempty,max=1000"`
+}
+
+type ReviewAccountDeletionRequestDto struct {
+	Note *string `json:"note,omitempty" validate:"omitempty,max=1000"`
+}
+
+type AccountDeletionRequestResponseDto struct {
+	ID           string     `json:"id"`
+	UserID       string     `json:"user_id"`
+	Status       string     `json:"status"`
+	Reason       *string    `json:"reason,omitempty"`
+	ReviewedBy   *string    `json:"reviewed_by,omitempty"`
+	ReviewNote   *string    `json:"review_note,omitempty"`
+	RequestedAt  time.Time  `json:"requested_at"`
+	ReviewedAt   *time.Time `json:"reviewed_at,omitempty"`
+	AnonymizedAt *time.Time `json:"anonymized_at,omitempty"`
+}
+
+type AnonymizeAccountsResponseDto struct {
+	Anonymized int `json:"anonymized"`
+}
@@ -0,0 +1,10 @@
+package dto
+
+type AdminLoginRequestDto struct {
+	Username string `json:"username" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+type AdminLoginResponseDto struct {
+	AccessToken string `json:"access_token"`
+}
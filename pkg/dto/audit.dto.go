@@ -0,0 +1,35 @@
+package dto
+
+import "time"
+
+type AuditLogQueryDto struct {
+	ActorID   string `query:"actor_id"`
+	PatientID string `query:"patient_id"`
+	Action    string `query:"action"`
+	From      string `query:"from"`
+	To        string `query:"to"`
+	Page      int    `query:"page"`
+	PageSize  int    `query:"page_size"`
+}
+
+type AuditEntryResponseDto struct {
+	ID         int64     `json:"id"`
+	OccurredAt time.Time `json:"occurred_at"`
+	ActorID    *string   `json:"actor_id"`
+	ActorRole  string    `json:"actor_role"`
+	Action     string    `json:"action"`
+	TargetIDs  []string  `json:"target_ids"`
+	RequestID  string    `json:"request_id"`
+	IP         string    `json:"ip"`
+	Outcome    string    `json:"outcome"`
+	Status     int       `json:"status"`
+	PrevHash   string    `json:"prev_hash"`
+	Hash       string    `json:"hash"`
+}
+
+type AuditVerifyResponseDto struct {
+	Valid   bool  `json:"valid"`
+	Checked int64 `json:"checked"`
+	// BrokenAt is the ID of the first entry whose hash does not match.
+	BrokenAt *int64 `json:"broken_at,omitempty"`
+}
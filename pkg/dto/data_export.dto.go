@@ -0,0 +1,58 @@
+package dto
+
+import "time"
+
+type DataExportQueryDto struct {
+	// Format is json (the default) or zip.
+	Format string `query:"format"`
+}
+
+// DataExportDto is everything the service holds about one patient.
+type DataExportDto struct {
+	GeneratedAt            time.Time            `json:"generated_at"`
+	User                   DataExportUserDto    `json:"user"`
+	Patient                DataExportPatientDto `json:"patient"`
+	HealthcareEntitlements []string             `json:"healthcare_entitlements"`
+	// ProfileChanges and DeletionRequests name staff by role only: actor_id
+	// is set only for the patient's own changes, and reviewed_by never.
+	ProfileChanges   []*ProfileChangeResponseDto          `json:"profile_changes"`
+	DeletionRequests []*AccountDeletionRequestResponseDto `json:"deletion_requests"`
+	// AuditEntries are the recorded requests made by the patient or that
+	// read or changed their data, oldest first.
+	AuditEntries []*DataExportAuditEntryDto `json:"audit_entries"`
+}
+
+type DataExportUserDto struct {
+	ID          string    `json:"id"`
+	FirstName   string    `json:"first_name"`
+	LastName    string    `json:"last_name"`
+	Gender      string    `json:"gender"`
+	PhoneNumber string    `json:"phone_number"`
+	Role        string    `json:"role"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type DataExportPatientDto struct {
+	HospitalID       string     `json:"hospital_id"`
+	BirthDate        *time.Time `json:"birth_date"`
+	IDCardNumber     *string    `json:"id_card_number"`
+	Address          *string    `json:"address"`
+	Allergies        *string    `json:"allergies"`
+	EmergencyContact *string    `json:"emergency_contact"`
+	BloodType        *string    `json:"blood_type"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// DataExportAuditEntryDto is an audit entry as the patient sees it: who
+// (by role) did what and when. TargetIDs only ever holds the patient's own
+// ID, since one entry can cover several patients; the staff member's
+// identity, IP address and the chain hashes are left out.
+type DataExportAuditEntryDto struct {
+	OccurredAt time.Time `json:"occurred_at"`
+	Action     string    `json:"action"`
+	ActorRole  string    `json:"actor_role"`
+	Outcome    string    `json:"outcome"`
+	TargetIDs  []string  `json:"target_ids"`
+}
@@ -0,0 +1,13 @@
+package dto
+
+// FHIRSearchQueryDto holds the FHIR search parameters the service supports.
+// Practitioner only applies to PractitionerRole searches, which in turn
+// reject Identifier and Name.
+type FHIRSearchQueryDto struct {
+	ID           string `query:"_id"`
+	Identifier   string `query:"identifier"`
+	Name         string `query:"name"`
+	Practitioner string `query:"practitioner"`
+	Count        int    `query:"_count"`
+	Offset       int    `query:"_offset"`
+}
@@ -15,4 +15,7 @@ type GetProfileResponseDto struct {
 	Allergies        *string    `json:"allergies"`
 	EmergencyContact *string    `json:"emergency_contact"`
 	BloodType        *string    `json:"blood_type"`
+
+	// ETag identifies this version of the profile; it is sent as a header.
+	ETag string `json:"-"`
 }
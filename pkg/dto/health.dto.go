@@ -0,0 +1,18 @@
+package dto
+
+type HealthResponseDto struct {
+	Status string `json:"status"`
+}
+
+type ReadinessResponseDto struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+type VersionResponseDto struct {
+	Version       string `json:"version"`
+	Commit        string `json:"commit,omitempty"`
+	BuildTime     string `json:"build_time,omitempty"`
+	GoVersion     string `json:"go_version"`
+	SchemaVersion *int64 `json:"schema_version,omitempty"`
+}
@@ -0,0 +1,6 @@
+package dto
+
+type KeyRotationResultDto struct {
+	Scanned int `json:"scanned"`
+	Rotated int `json:"rotated"`
+}
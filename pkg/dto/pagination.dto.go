@@ -0,0 +1,13 @@
+package dto
+
+type PageQueryDto struct {
+	Page     int `query:"page"`
+	PageSize int `query:"page_size"`
+}
+
+type PageDto[T any] struct {
+	Items    []T
+	Page     int
+	PageSize int
+	Total    int64
+}
@@ -0,0 +1,30 @@
+package dto
+
+import "user-service/pkg/validation"
+
+// PatientImportQueryDto holds the options of a bulk patient import.
+type PatientImportQueryDto struct {
+	DryRun    bool `query:"dry_run"`
+	BatchSize int  `query:"batch_size"`
+}
+
+type PatientImportResponseDto struct {
+	DryRun bool `json:"dry_run"`
+	Total  int  `json:"total"`
+	// Imported counts the patients created, or that would be created in a
+	// dry run.
+	Imported int                        `json:"imported"`
+	Failed   int                        `json:"failed"`
+	Errors   []PatientImportRowErrorDto `json:"errors"`
+
+	// UserIDs are the IDs of the imported patients, for the audit log.
+	UserIDs []string `json:"-"`
+}
+
+// PatientImportRowErrorDto explains why one CSV row was not imported.
+type PatientImportRowErrorDto struct {
+	// Line is the row's line number in the file; the header is line 1.
+	Line       int                     `json:"line"`
+	HospitalID string                  `json:"hospital_id,omitempty"`
+	Errors     []validation.FieldError `json:"errors"`
+}
@@ -1,8 +1,8 @@
 package dto
 
 type PatientLoginRequestDto struct {
-	HospitalID string `json:"hospital_id" required:"required"`
-	Password string `json:"password" required:"required"`
+	HospitalID string `json:"hospital_id" validate:"required"`
+	Password   string `json:"password" validate:"required"`
 }
 
 type PatientLoginResponseDto struct {
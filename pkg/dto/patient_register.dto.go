@@ -11,7 +11,7 @@ type PatientRegisterPatientRequestDto struct {
 	// Patient specific fields
 	HospitalID       string     `json:"hospital_id" validate:"required"`
 	BirthDate        *time.Time `json:"birth_date,omitempty"`
-	IDCardNumber     *string    `json:"id_card_number,omitempty" validate:"omitempty,len=13,numeric"`
+	IDCardNumber     *string    `json:"id_card_number,omitempty" validate:"omitempty,len=13,numeric,thai_id"`
 	Address          *string    `json:"address,omitempty"`
 	Allergies        *string    `json:"allergies,omitempty"`
 	EmergencyContact *string    `json:"emergency_contact,omitempty"`
@@ -20,4 +20,7 @@ type PatientRegisterPatientRequestDto struct {
 
 type PatientRegisterResponseDto struct {
 	Message string `json:"message"`
+
+	// UserID is the ID of the new patient, for the audit log.
+	UserID string `json:"-"`
 }
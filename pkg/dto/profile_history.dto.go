@@ -0,0 +1,13 @@
+package dto
+
+import "time"
+
+type ProfileChangeResponseDto struct {
+	ID        string    `json:"id"`
+	Field     string    `json:"field"`
+	OldValue  *string   `json:"old_value"`
+	NewValue  *string   `json:"new_value"`
+	ActorID   *string   `json:"actor_id"`
+	ActorRole string    `json:"actor_role"`
+	ChangedAt time.Time `json:"changed_at"`
+}
@@ -0,0 +1,6 @@
+package dto
+
+type SearchRequestDto struct {
+	Query string `query:"q"`
+	Limit int    `query:"limit"`
+}
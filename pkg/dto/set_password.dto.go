@@ -0,0 +1,11 @@
+package dto
+
+// SetPatientPasswordRequestDto is an admin setting a patient's password, for
+// patients imported without one or who have lost theirs.
+type SetPatientPasswordRequestDto struct {
+	Password string `json:"password" validate:"required,min=6"`
+}
+
+type SetPatientPasswordResponseDto struct {
+	Message string `json:"message"`
+}
@@ -0,0 +1,21 @@
+package dto
+
+import "time"
+
+type DeletedUserResponseDto struct {
+	ID        string    `json:"id"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Role      string    `json:"role"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+type RestoreUserResponseDto struct {
+	Message string `json:"message"`
+}
+
+type PurgeResultDto struct {
+	Users    int64 `json:"users"`
+	Patients int64 `json:"patients"`
+	Doctors  int64 `json:"doctors"`
+}
@@ -8,7 +8,7 @@ type UpdatePatientProfileRequestDto struct {
 	PhoneNumber *string `json:"phone_number,omitempty"`
 	// Patient specific fields
 	BirthDate        *time.Time `json:"birth_date,omitempty"`
-	IDCardNumber     *string    `json:"id_card_number,omitempty" validate:"omitempty,len=13,numeric"`
+	IDCardNumber     *string    `json:"id_card_number,omitempty" validate:"omitempty,len=13,numeric,thai_id"`
 	Address          *string    `json:"address,omitempty"`
 	Allergies        *string    `json:"allergies,omitempty"`
 	EmergencyContact *string    `json:"emergency_contact,omitempty"`
@@ -17,4 +17,7 @@ type UpdatePatientProfileRequestDto struct {
 
 type UpdatePatientProfileResponseDto struct {
 	Message string `json:"message"`
+
+	// ETag identifies the updated profile; it is sent as a header.
+	ETag string `json:"-"`
 }
@@ -0,0 +1,245 @@
+// Package encryption implements envelope encryption for sensitive columns.
+//
+// Every value is encrypted with its own random data-encryption key (DEK)
+// using AES-256-GCM. The DEK is in turn encrypted ("wrapped") with a
+// versioned key-encryption key (KEK) from configuration, and stored next to
+// the ciphertext:
+//
+//	enc:v2:<kek version>:<base64 wrapped DEK>:<base64 ciphertext>
+//
+// The ciphertext is authenticated together with associated data naming where
+// the value is stored (its table, column and row), so a value copied into
+// another row or column fails to decrypt. Values in the older enc:v1 format
+// have no associated data; they still decrypt and are rewritten as v2 by
+// the rotate-keys command.
+//
+// Rotating the KEK only requires re-wrapping, which the rotate-keys command
+// does by re-saving every row with the active key.
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	prefix       = "enc:v2:"
+	legacyPrefix = "enc:v1:"
+	keySize      = 32
+)
+
+var ErrUnknownKey = errors.New("encryption: unknown key version")
+
+type Keyring struct {
+	keks     map[int][]byte
+	active   int
+	indexKey []byte
+}
+
+// ParseKeys parses a comma separated list of "<version>:<base64 key>" pairs,
+// e.g. "1:3q2+7w...,2:q83v...". Each key must decode to 32 bytes.
+func ParseKeys(spec string) (map[int][]byte, error) {
+	keys := map[int][]byte{}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		version, encoded, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("encryption: key %q is not in <version>:<base64> form", entry)
+		}
+		v, err := strconv.Atoi(version)
+		if err != nil || v <= 0 {
+			return nil, fmt.Errorf("encryption: invalid key version %q", version)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("encryption: key version %d: %w", v, err)
+		}
+		if _, dup := keys[v]; dup {
+			return nil, fmt.Errorf("encryption: duplicate key version %d", v)
+		}
+		keys[v] = key
+	}
+	return keys, nil
+}
+
+// NewKeyring builds a keyring. active selects the KEK used for new values;
+// zero selects the highest version. indexKey is the HMAC key for blind indexes.
+func NewKeyring(keks map[int][]byte, active int, indexKey []byte) (*Keyring, error) {
+	if len(keks) == 0 {
+		return nil, errors.New("encryption: no key-encryption keys configured")
+	}
+	for v, k := range keks {
+		if len(k) != keySize {
+			return nil, fmt.Errorf("encryption: key version %d must be %d bytes, got %d", v, keySize, len(k))
+		}
+	}
+	if active == 0 {
+		versions := make([]int, 0, len(keks))
+		for v := range keks {
+			versions = append(versions, v)
+		}
+		sort.Ints(versions)
+		active = versions[len(versions)-1]
+	}
+	if _, ok := keks[active]; !ok {
+		return nil, fmt.Errorf("encryption: active key version %d is not configured", active)
+	}
+	if len(indexKey) < 16 {
+		return nil, errors.New("encryption: blind index key must be at least 16 bytes")
+	}
+	return &Keyring{keks: keks, active: active, indexKey: indexKey}, nil
+}
+
+func (k *Keyring) ActiveVersion() int {
+	return k.active
+}
+
+// SelfTest encrypts and decrypts a probe value with the active key, to check
+// that the key material is usable.
+func (k *Keyring) SelfTest() error {
+	const probe = "self-test"
+	aad := []byte("self-test")
+	sealed, err := k.Encrypt([]byte(probe), aad)
+	if err != nil {
+		return fmt.Errorf("encrypt: %w", err)
+	}
+	opened, err := k.Decrypt(sealed, aad)
+	if err != nil {
+		return fmt.Errorf("decrypt: %w", err)
+	}
+	if string(opened) != probe {
+		return errors.New("decrypted value does not match")
+	}
+	return nil
+}
+
+// Encrypt seals plaintext under a fresh DEK wrapped with the active KEK. aad
+// is authenticated but not stored; Decrypt must be given the same bytes.
+func (k *Keyring) Encrypt(plaintext, aad []byte) (string, error) {
+	dek := make([]byte, keySize)
+	if _, err := rand.Read(dek); err != nil {
+		return "", err
+	}
+	wrapped, err := seal(k.keks[k.active], dek, nil)
+	if err != nil {
+		return "", err
+	}
+	ciphertext, err := seal(dek, plaintext, aad)
+	if err != nil {
+		return "", err
+	}
+	return prefix + strconv.Itoa(k.active) + ":" +
+		base64.RawStdEncoding.EncodeToString(wrapped) + ":" +
+		base64.RawStdEncoding.EncodeToString(ciphertext), nil
+}
+
+// Decrypt opens a value produced by Encrypt with the same aad. Values
+// without the envelope prefix predate encryption and are returned unchanged;
+// v1 envelopes predate associated data and are opened without it.
+func (k *Keyring) Decrypt(value string, aad []byte) ([]byte, error) {
+	var envelope string
+	switch {
+	case strings.HasPrefix(value, prefix):
+		envelope = strings.TrimPrefix(value, prefix)
+	case strings.HasPrefix(value, legacyPrefix):
+		envelope, aad = strings.TrimPrefix(value, legacyPrefix), nil
+	default:
+		return []byte(value), nil
+	}
+	parts := strings.Split(envelope, ":")
+	if len(parts) != 3 {
+		return nil, errors.New("encryption: malformed envelope")
+	}
+	version, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return nil, errors.New("encryption: malformed key version")
+	}
+	kek, ok := k.keks[version]
+	if !ok {
+		return nil, fmt.Errorf("%w %d", ErrUnknownKey, version)
+	}
+	wrapped, err := base64.RawStdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("encryption: wrapped key: %w", err)
+	}
+	ciphertext, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("encryption: ciphertext: %w", err)
+	}
+	dek, err := open(kek, wrapped, nil)
+	if err != nil {
+		return nil, err
+	}
+	return open(dek, ciphertext, aad)
+}
+
+// NeedsRotation reports whether a stored value is plaintext, in the v1
+// format or wrapped with a KEK other than the active one.
+func (k *Keyring) NeedsRotation(value string) bool {
+	if !strings.HasPrefix(value, prefix) {
+		return true
+	}
+	version, _, _ := strings.Cut(strings.TrimPrefix(value, prefix), ":")
+	return version != strconv.Itoa(k.active)
+}
+
+// BlindIndex returns a keyed hash of value that supports equality lookups
+// without storing the plaintext.
+func (k *Keyring) BlindIndex(value string) string {
+	mac := hmac.New(sha256.New, k.indexKey)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix) || strings.HasPrefix(value, legacyPrefix)
+}
+
+func seal(key, plaintext, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+func open(key, sealed, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("encryption: ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, aad)
+	if err != nil {
+		return nil, fmt.Errorf("encryption: %w", err)
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
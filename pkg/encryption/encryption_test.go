@@ -0,0 +1,278 @@
+package encryption_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"user-service/pkg/encryption"
+	"user-service/pkg/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm/schema"
+)
+
+var (
+	keyV1    = bytes.Repeat([]byte{1}, 32)
+	keyV2    = bytes.Repeat([]byte{2}, 32)
+	indexKey = []byte("test-blind-index-key")
+	aad      = []byte("patients.address:0199f0a4-0000-7000-8000-000000000001")
+)
+
+func newKeyring(t *testing.T, keks map[int][]byte, active int) *encryption.Keyring {
+	t.Helper()
+	k, err := encryption.NewKeyring(keks, active, indexKey)
+	if err != nil {
+		t.Fatalf("new keyring: %v", err)
+	}
+	return k
+}
+
+func TestRoundTrip(t *testing.T) {
+	k := newKeyring(t, map[int][]byte{1: keyV1}, 0)
+	for _, plaintext := range []string{"", "1101700203450", "99 ถนนพระรามที่ 4 กรุงเทพฯ"} {
+		sealed, err := k.Encrypt([]byte(plaintext), aad)
+		if err != nil {
+			t.Fatalf("encrypt %q: %v", plaintext, err)
+		}
+		if !encryption.IsEncrypted(sealed) || (plaintext != "" && strings.Contains(sealed, plaintext)) {
+			t.Errorf("sealed = %q, want an envelope without the plaintext", sealed)
+		}
+		opened, err := k.Decrypt(sealed, aad)
+		if err != nil || string(opened) != plaintext {
+			t.Errorf("decrypt = %q (%v), want %q", opened, err, plaintext)
+		}
+	}
+
+	first, _ := k.Encrypt([]byte("same"), aad)
+	second, _ := k.Encrypt([]byte("same"), aad)
+	if first == second {
+		t.Error("encrypting the same value twice gave the same envelope, want a fresh key and nonce each time")
+	}
+	if err := k.SelfTest(); err != nil {
+		t.Errorf("self test: %v", err)
+	}
+
+	opened, err := k.Decrypt("1101700203450", aad)
+	if err != nil || string(opened) != "1101700203450" {
+		t.Errorf("decrypt plaintext = %q (%v), want it returned unchanged", opened, err)
+	}
+}
+
+func TestDecryptWithWrongKey(t *testing.T) {
+	sealed, err := newKeyring(t, map[int][]byte{1: keyV1}, 0).Encrypt([]byte("secret"), aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := newKeyring(t, map[int][]byte{1: keyV2}, 0).Decrypt(sealed, aad); err == nil {
+		t.Error("decrypt with another key under the same version succeeded, want an error")
+	}
+	if _, err := newKeyring(t, map[int][]byte{2: keyV2}, 0).Decrypt(sealed, aad); !errors.Is(err, encryption.ErrUnknownKey) {
+		t.Errorf("decrypt without the key version = %v, want ErrUnknownKey", err)
+	}
+}
+
+func TestDecryptWithOtherAssociatedData(t *testing.T) {
+	k := newKeyring(t, map[int][]byte{1: keyV1}, 0)
+	sealed, err := k.Encrypt([]byte("secret"), aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, other := range [][]byte{nil, []byte("patients.allergies:0199f0a4-0000-7000-8000-000000000001"), []byte("patients.address:0199f0a4-0000-7000-8000-000000000002")} {
+		if _, err := k.Decrypt(sealed, other); err == nil {
+			t.Errorf("decrypt with associated data %q succeeded, want an error", other)
+		}
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	old := newKeyring(t, map[int][]byte{1: keyV1}, 0)
+	sealed, err := old.Encrypt([]byte("secret"), aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotated := newKeyring(t, map[int][]byte{1: keyV1, 2: keyV2}, 0)
+	if rotated.ActiveVersion() != 2 {
+		t.Errorf("active version = %d, want the highest, 2", rotated.ActiveVersion())
+	}
+	opened, err := rotated.Decrypt(sealed, aad)
+	if err != nil || string(opened) != "secret" {
+		t.Errorf("decrypt under the old key = %q (%v), want the value", opened, err)
+	}
+	if !rotated.NeedsRotation(sealed) || old.NeedsRotation(sealed) {
+		t.Error("a value under the old key should need rotation only once a newer key is active")
+	}
+
+	resealed, err := rotated.Encrypt(opened, aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(resealed, "enc:v2:2:") || rotated.NeedsRotation(resealed) {
+		t.Errorf("resealed = %q, want it under key version 2", resealed)
+	}
+	if _, err := old.Decrypt(resealed, aad); !errors.Is(err, encryption.ErrUnknownKey) {
+		t.Errorf("old keyring decrypting the new value = %v, want ErrUnknownKey", err)
+	}
+
+	pinned := newKeyring(t, map[int][]byte{1: keyV1, 2: keyV2}, 1)
+	if pinned.ActiveVersion() != 1 {
+		t.Errorf("active version = %d, want the configured 1", pinned.ActiveVersion())
+	}
+	if _, err := encryption.NewKeyring(map[int][]byte{1: keyV1}, 2, indexKey); err == nil {
+		t.Error("keyring with an unconfigured active version was accepted")
+	}
+}
+
+func TestDecryptTamperedValue(t *testing.T) {
+	k := newKeyring(t, map[int][]byte{1: keyV1}, 0)
+	sealed, err := k.Encrypt([]byte("secret"), aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(sealed, ":")
+	ciphertext, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext[len(ciphertext)-1] ^= 1
+	parts[4] = base64.RawStdEncoding.EncodeToString(ciphertext)
+
+	for name, value := range map[string]string{
+		"flipped bit":     strings.Join(parts, ":"),
+		"truncated":       sealed[:len(sealed)-4],
+		"missing part":    strings.Join(parts[:4], ":"),
+		"bad key version": "enc:v2:x:" + strings.Join(parts[3:], ":"),
+	} {
+		if _, err := k.Decrypt(value, aad); err == nil {
+			t.Errorf("%s: decrypt succeeded, want an error", name)
+		}
+	}
+}
+
+// TestDecryptLegacyValue opens a v1 envelope, written before values were
+// bound to associated data.
+func TestDecryptLegacyValue(t *testing.T) {
+	k := newKeyring(t, map[int][]byte{1: keyV1}, 0)
+	dek := bytes.Repeat([]byte{9}, 32)
+	legacy := "enc:v1:1:" +
+		base64.RawStdEncoding.EncodeToString(gcmSeal(t, keyV1, dek)) + ":" +
+		base64.RawStdEncoding.EncodeToString(gcmSeal(t, dek, []byte("secret")))
+
+	opened, err := k.Decrypt(legacy, aad)
+	if err != nil || string(opened) != "secret" {
+		t.Errorf("decrypt v1 = %q (%v), want the value", opened, err)
+	}
+	if !k.NeedsRotation(legacy) {
+		t.Error("a v1 value should need rotation to bind it to its row")
+	}
+}
+
+func gcmSeal(t *testing.T, key, plaintext []byte) []byte {
+	t.Helper()
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		t.Fatal(err)
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil)
+}
+
+func TestBlindIndex(t *testing.T) {
+	k := newKeyring(t, map[int][]byte{1: keyV1}, 0)
+	other, err := encryption.NewKeyring(map[int][]byte{1: keyV1}, 0, []byte("another-blind-index-key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k.BlindIndex("1101700203450") != k.BlindIndex("1101700203450") {
+		t.Error("blind index is not deterministic")
+	}
+	if k.BlindIndex("1101700203450") == k.BlindIndex("3101001234565") || k.BlindIndex("1101700203450") == other.BlindIndex("1101700203450") {
+		t.Error("blind index should differ across values and index keys")
+	}
+}
+
+// TestSerializerBindsRow checks that a value the serializer wrote for one
+// patient's column cannot be read back as another patient's or column's.
+func TestSerializerBindsRow(t *testing.T) {
+	encryption.Init(newKeyring(t, map[int][]byte{1: keyV1}, 0))
+	ctx := context.Background()
+	patientSchema, err := schema.Parse(&models.Patient{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := patientSchema.LookUpField("Address")
+	allergies := patientSchema.LookUpField("Allergies")
+
+	value := "99 Rama IV Road"
+	patient := &models.Patient{UserID: uuid.New(), Address: &value}
+	stored, err := encryption.Serializer{}.Value(ctx, address, reflect.ValueOf(patient).Elem(), patient.Address)
+	if err != nil {
+		t.Fatalf("value: %v", err)
+	}
+
+	read := &models.Patient{UserID: patient.UserID}
+	if err := (encryption.Serializer{}).Scan(ctx, address, reflect.ValueOf(read).Elem(), stored); err != nil || read.Address == nil || *read.Address != value {
+		t.Errorf("scan into the same row = %v (%v), want %q", read.Address, err, value)
+	}
+	other := &models.Patient{UserID: uuid.New()}
+	if err := (encryption.Serializer{}).Scan(ctx, address, reflect.ValueOf(other).Elem(), stored); err == nil {
+		t.Error("scan into another patient succeeded, want an error")
+	}
+	if err := (encryption.Serializer{}).Scan(ctx, allergies, reflect.ValueOf(read).Elem(), stored); err == nil {
+		t.Error("scan into another column succeeded, want an error")
+	}
+
+	if _, err := (encryption.Serializer{}).Value(ctx, address, reflect.ValueOf(&models.Patient{}).Elem(), &value); err == nil {
+		t.Error("value of a row without a primary key succeeded, want an error")
+	}
+}
+
+// TestSerializerReadsBeforePrimaryKey checks that a value scanned before its
+// row's primary key, as when a query selects it first, is decrypted by Open.
+func TestSerializerReadsBeforePrimaryKey(t *testing.T) {
+	encryption.Init(newKeyring(t, map[int][]byte{1: keyV1}, 0))
+	ctx := context.Background()
+	patientSchema, err := schema.Parse(&models.Patient{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := patientSchema.LookUpField("Address")
+
+	value := "99 Rama IV Road"
+	patient := &models.Patient{UserID: uuid.New(), Address: &value}
+	stored, err := encryption.Serializer{}.Value(ctx, address, reflect.ValueOf(patient).Elem(), patient.Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	read := &models.Patient{}
+	if err := (encryption.Serializer{}).Scan(ctx, address, reflect.ValueOf(read).Elem(), stored); err != nil {
+		t.Fatalf("scan before the primary key: %v", err)
+	}
+	if read.Address == nil || strings.Contains(*read.Address, value) {
+		t.Fatalf("address before the primary key = %v, want it still sealed", read.Address)
+	}
+	read.UserID = patient.UserID
+	if err := encryption.Open(ctx, read, schema.NamingStrategy{}); err != nil || *read.Address != value {
+		t.Errorf("open = %q (%v), want %q", *read.Address, err, value)
+	}
+	if err := encryption.Open(ctx, read, schema.NamingStrategy{}); err != nil || *read.Address != value {
+		t.Errorf("second open = %q (%v), want the value left as it is", *read.Address, err)
+	}
+}
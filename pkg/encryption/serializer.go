@@ -0,0 +1,176 @@
+package encryption
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm/schema"
+)
+
+var defaultKeyring *Keyring
+
+// Init installs k as the process-wide keyring and registers the "encrypted"
+// GORM serializer, so model fields tagged `gorm:"serializer:encrypted"` are
+// encrypted on write and decrypted on read wherever they are queried.
+func Init(k *Keyring) {
+	defaultKeyring = k
+	schema.RegisterSerializer("encrypted", Serializer{})
+}
+
+// Default returns the keyring installed by Init, or nil.
+func Default() *Keyring {
+	return defaultKeyring
+}
+
+// BlindIndex hashes value with the default keyring; see Keyring.BlindIndex.
+func BlindIndex(value string) string {
+	return keyring().BlindIndex(value)
+}
+
+// Serializer encrypts string and *string fields with the default keyring.
+// Values are bound to their table, column and primary key, so the primary
+// key must be set before a row is written. On read, gorm sets columns in the
+// order they are selected; a value read before its row's primary key is
+// kept sealed and decrypted by Open, which models with encrypted fields call
+// from AfterFind. Scanning rows into such a model without the query
+// callbacks, as db.Raw(...).Scan does, needs the primary key selected first.
+type Serializer struct{}
+
+// pendingPrefix marks a sealed value Scan left for Open. Postgres text
+// cannot hold a NUL, so no stored plaintext starts with it.
+const pendingPrefix = "\x00pending:"
+
+var errNoPrimaryKeyValue = errors.New("no primary key value")
+
+func (Serializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	fieldValue := reflect.New(field.FieldType)
+	if dbValue != nil {
+		var stored string
+		switch v := dbValue.(type) {
+		case string:
+			stored = v
+		case []byte:
+			stored = string(v)
+		default:
+			return fmt.Errorf("encryption: unsupported column value %T for %s", dbValue, field.Name)
+		}
+		value, err := decryptField(ctx, field, dst, stored)
+		if errors.Is(err, errNoPrimaryKeyValue) {
+			value, err = pendingPrefix+stored, nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := setString(fieldValue.Elem(), value); err != nil {
+			return err
+		}
+	}
+	field.ReflectValueOf(ctx, dst).Set(fieldValue.Elem())
+	return nil
+}
+
+func (Serializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	rv := reflect.ValueOf(fieldValue)
+	if !rv.IsValid() || (rv.Kind() == reflect.Pointer && rv.IsNil()) {
+		return nil, nil
+	}
+	rv = reflect.Indirect(rv)
+	if rv.Kind() != reflect.String {
+		return nil, fmt.Errorf("encryption: unsupported field type %s for %s", field.FieldType, field.Name)
+	}
+	aad, err := associatedData(ctx, field, dst)
+	if err != nil {
+		return nil, err
+	}
+	return keyring().Encrypt([]byte(rv.String()), aad)
+}
+
+// Open decrypts the fields of model, a pointer to a scanned struct, that
+// Scan kept sealed because they were read before the primary key.
+func Open(ctx context.Context, model interface{}, namer schema.Namer) error {
+	s, err := schema.Parse(model, &schemas, namer)
+	if err != nil {
+		return err
+	}
+	dst := reflect.Indirect(reflect.ValueOf(model))
+	for _, field := range s.Fields {
+		switch field.Serializer.(type) {
+		case Serializer, *Serializer:
+		default:
+			continue
+		}
+		value := field.ReflectValueOf(ctx, dst)
+		if value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				continue
+			}
+			value = value.Elem()
+		}
+		if value.Kind() != reflect.String {
+			continue
+		}
+		stored, ok := strings.CutPrefix(value.String(), pendingPrefix)
+		if !ok {
+			continue
+		}
+		plaintext, err := decryptField(ctx, field, dst, stored)
+		if err != nil {
+			return err
+		}
+		value.SetString(plaintext)
+	}
+	return nil
+}
+
+// schemas caches the schemas Open parses.
+var schemas sync.Map
+
+// decryptField decrypts stored as the value of field in the row dst.
+func decryptField(ctx context.Context, field *schema.Field, dst reflect.Value, stored string) (string, error) {
+	aad, err := associatedData(ctx, field, dst)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := keyring().Decrypt(stored, aad)
+	if err != nil {
+		return "", fmt.Errorf("decrypt %s: %w", field.Name, err)
+	}
+	return string(plaintext), nil
+}
+
+// associatedData names where field of the row dst is stored, as
+// "<table>.<column>:<primary key>".
+func associatedData(ctx context.Context, field *schema.Field, dst reflect.Value) ([]byte, error) {
+	pk := field.Schema.PrioritizedPrimaryField
+	if pk == nil {
+		return nil, fmt.Errorf("encryption: %s has no primary key to bind %s to", field.Schema.Name, field.Name)
+	}
+	id := pk.ReflectValueOf(ctx, dst)
+	if id.IsZero() {
+		return nil, fmt.Errorf("encryption: %s.%s has %w to bind %s to", field.Schema.Name, pk.Name, errNoPrimaryKeyValue, field.Name)
+	}
+	return []byte(fmt.Sprintf("%s.%s:%v", field.Schema.Table, field.DBName, id.Interface())), nil
+}
+
+func setString(v reflect.Value, s string) error {
+	if v.Kind() == reflect.Pointer {
+		v.Set(reflect.New(v.Type().Elem()))
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.String {
+		return errors.New("encryption: serializer supports only string fields")
+	}
+	v.SetString(s)
+	return nil
+}
+
+func keyring() *Keyring {
+	if defaultKeyring == nil {
+		panic("encryption: Init has not been called")
+	}
+	return defaultKeyring
+}
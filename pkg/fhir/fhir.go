@@ -0,0 +1,143 @@
+// Package fhir declares the HL7 FHIR R4 resources the service exposes to
+// integration partners: Patient, Practitioner and PractitionerRole, the
+// searchset Bundle and OperationOutcome. Only the elements the service fills
+// in are declared; see https://hl7.org/fhir/R4/.
+package fhir
+
+import "time"
+
+// MIMEType is the media type of FHIR JSON.
+const MIMEType = "application/fhir+json"
+
+// Code systems and identifier systems defined outside the service.
+const (
+	// ThaiNationalIDSystem identifies Thai citizen ID numbers, as defined by
+	// the Thai Core FHIR implementation guide.
+	ThaiNationalIDSystem = "https://terms.sil-th.org/id/th-cid"
+	// ContactRoleSystem is the HL7 v2 table 0131 of patient contact roles.
+	ContactRoleSystem = "http://terminology.hl7.org/CodeSystem/v2-0131"
+	// PractitionerRoleSystem codes the roles a practitioner has.
+	PractitionerRoleSystem = "http://terminology.hl7.org/CodeSystem/practitioner-role"
+)
+
+type Meta struct {
+	VersionID   string     `json:"versionId,omitempty"`
+	LastUpdated *time.Time `json:"lastUpdated,omitempty"`
+}
+
+type Coding struct {
+	System  string `json:"system,omitempty"`
+	Code    string `json:"code,omitempty"`
+	Display string `json:"display,omitempty"`
+}
+
+type CodeableConcept struct {
+	Coding []Coding `json:"coding,omitempty"`
+	Text   string   `json:"text,omitempty"`
+}
+
+type Identifier struct {
+	Use    string `json:"use,omitempty"`
+	System string `json:"system,omitempty"`
+	Value  string `json:"value,omitempty"`
+}
+
+type HumanName struct {
+	Use    string   `json:"use,omitempty"`
+	Text   string   `json:"text,omitempty"`
+	Family string   `json:"family,omitempty"`
+	Given  []string `json:"given,omitempty"`
+}
+
+type ContactPoint struct {
+	System string `json:"system,omitempty"`
+	Value  string `json:"value,omitempty"`
+	Use    string `json:"use,omitempty"`
+}
+
+type Address struct {
+	Use  string `json:"use,omitempty"`
+	Text string `json:"text,omitempty"`
+}
+
+type Reference struct {
+	Reference string `json:"reference,omitempty"`
+	Display   string `json:"display,omitempty"`
+}
+
+type Patient struct {
+	ResourceType string           `json:"resourceType"`
+	ID           string           `json:"id"`
+	Meta         *Meta            `json:"meta,omitempty"`
+	Identifier   []Identifier     `json:"identifier,omitempty"`
+	Active       bool             `json:"active"`
+	Name         []HumanName      `json:"name,omitempty"`
+	Telecom      []ContactPoint   `json:"telecom,omitempty"`
+	Gender       string           `json:"gender,omitempty"`
+	BirthDate    string           `json:"birthDate,omitempty"`
+	Address      []Address        `json:"address,omitempty"`
+	Contact      []PatientContact `json:"contact,omitempty"`
+}
+
+type PatientContact struct {
+	Relationship []CodeableConcept `json:"relationship,omitempty"`
+	Name         *HumanName        `json:"name,omitempty"`
+}
+
+type Practitioner struct {
+	ResourceType string         `json:"resourceType"`
+	ID           string         `json:"id"`
+	Meta         *Meta          `json:"meta,omitempty"`
+	Identifier   []Identifier   `json:"identifier,omitempty"`
+	Active       bool           `json:"active"`
+	Name         []HumanName    `json:"name,omitempty"`
+	Telecom      []ContactPoint `json:"telecom,omitempty"`
+	Gender       string         `json:"gender,omitempty"`
+}
+
+type PractitionerRole struct {
+	ResourceType string            `json:"resourceType"`
+	ID           string            `json:"id"`
+	Meta         *Meta             `json:"meta,omitempty"`
+	Active       bool              `json:"active"`
+	Practitioner Reference         `json:"practitioner"`
+	Code         []CodeableConcept `json:"code,omitempty"`
+	Specialty    []CodeableConcept `json:"specialty,omitempty"`
+}
+
+// Bundle is a searchset: one page of matches, the total and the links to
+// the neighbouring pages.
+type Bundle struct {
+	ResourceType string        `json:"resourceType"`
+	Type         string        `json:"type"`
+	Total        int64         `json:"total"`
+	Link         []BundleLink  `json:"link"`
+	Entry        []BundleEntry `json:"entry,omitempty"`
+}
+
+type BundleLink struct {
+	Relation string `json:"relation"`
+	URL      string `json:"url"`
+}
+
+type BundleEntry struct {
+	FullURL  string             `json:"fullUrl"`
+	Resource any                `json:"resource"`
+	Search   *BundleEntrySearch `json:"search,omitempty"`
+}
+
+type BundleEntrySearch struct {
+	Mode string `json:"mode"`
+}
+
+// OperationOutcome reports why a request failed.
+type OperationOutcome struct {
+	ResourceType string                  `json:"resourceType"`
+	Issue        []OperationOutcomeIssue `json:"issue"`
+}
+
+type OperationOutcomeIssue struct {
+	Severity    string `json:"severity"`
+	Code        string `json:"code"`
+	Diagnostics string `json:"diagnostics,omitempty"`
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"user-service/pkg/apperr"
+	contextUtils "user-service/pkg/context"
+	"user-service/pkg/dto"
+	"user-service/pkg/middleware"
+	response "user-service/pkg/response"
+	service "user-service/pkg/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AccountHandler struct {
+	accountService *service.AccountService
+}
+
+func NewAccountHandler(accountService *service.AccountService) *AccountHandler {
+	return &AccountHandler{
+		accountService: accountService}
+}
+
+// RequestAccountDeletion godoc
+// @Summary Request account deletion
+// @Description Ask for the authenticated patient's account to be closed. An admin must approve the request; personal data is anonymized after the retention period.
+// @Tags patients
+// @Accept  json
+// @Produce  json
+// @Security ApiKeyAuth
+// @Param body body dto.RequestAccountDeletionRequestDto true "Optional reason"
+// @Success 201 {object} response.BaseResponse{data=dto.AccountDeletionRequestResponseDto} "Deletion requested"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 409 {object} response.ErrorResponse "Deletion already requested"
+// @Router /api/user/v1/patient/deletion-request [post]
+func (h *AccountHandler) RequestAccountDeletion(c *fiber.Ctx) error {
+	var body dto.RequestAccountDeletionRequestDto
+	// the body is optional for these endpoints
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&body); err != nil {
+			return apperr.WriteError(c, apperr.InvalidBody(err))
+		}
+	}
+
+	middleware.SetAuditStatus(c, fiber.StatusCreated)
+	ctx := contextUtils.GetContext(c)
+	res, err := h.accountService.RequestDeletion(ctx, &body)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+	return response.Created(c, res)
+}
+
+// ListDeletionRequests godoc
+// @Summary List account deletion requests
+// @Description List account deletion requests, newest first. Admins only.
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Security ApiKeyAuth
+// @Param status query string false "Filter by status (pending, approved, rejected)"
+// @Param page query int false "Page number (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Success 200 {object} response.BaseResponse{data=[]dto.AccountDeletionRequestResponseDto} "Deletion requests"
+// @Failure 400 {object} response.ErrorResponse "Invalid status filter"
+// @Failure 403 {object} response.ErrorResponse "Insufficient permissions"
+// @Router /api/user/v1/admin/deletion-requests [get]
+func (h *AccountHandler) ListDeletionRequests(c *fiber.Ctx) error {
+	var query dto.PageQueryDto
+	if err := c.QueryParser(&query); err != nil {
+		return apperr.WriteError(c, apperr.New(apperr.CodeBadRequest, "invalid query parameters", err))
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.accountService.ListDeletionRequests(ctx, c.Query("status"), &query)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+	ids := make([]string, 0, len(res.Items))
+	for _, item := range res.Items {
+		ids = append(ids, item.UserID)
+	}
+	middleware.SetAuditTargets(c, ids...)
+	return response.Paginated(c, res.Items, response.Pagination{Page: res.Page, PageSize: res.PageSize, Total: res.Total})
+}
+
+// ApproveDeletionRequest godoc
+// @Summary Approve an account deletion request
+// @Description Approve a pending request and soft-delete the patient's account. Admins only.
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Security ApiKeyAuth
+// @Param id path string true "Deletion request ID"
+// @Param body body dto.ReviewAccountDeletionRequestDto true "Optional review note"
+// @Success 200 {object} response.BaseResponse{data=dto.AccountDeletionRequestResponseDto} "Request approved"
+// @Failure 404 {object} response.ErrorResponse "Deletion request not found"
+// @Failure 409 {object} response.ErrorResponse "Request already reviewed"
+// @Router /api/user/v1/admin/deletion-requests/{id}/approve [post]
+func (h *AccountHandler) ApproveDeletionRequest(c *fiber.Ctx) error {
+	var body dto.ReviewAccountDeletionRequestDto
+	// the body is optional for these endpoints
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&body); err != nil {
+			return apperr.WriteError(c, apperr.InvalidBody(err))
+		}
+	}
+
+	// :id is the request, not the patient
+	middleware.SetAuditTargets(c)
+	ctx := contextUtils.GetContext(c)
+	res, err := h.accountService.ApproveDeletion(ctx, c.Params("id"), &body)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+	middleware.SetAuditTargets(c, res.UserID)
+	return response.OK(c, res)
+}
+
+// RejectDeletionRequest godoc
+// @Summary Reject an account deletion request
+// @Description Reject a pending request; the account stays active. Admins only.
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Security ApiKeyAuth
+// @Param id path string true "Deletion request ID"
+// @Param body body dto.ReviewAccountDeletionRequestDto true "Optional review note"
+// @Success 200 {object} response.BaseResponse{data=dto.AccountDeletionRequestResponseDto} "Request rejected"
+// @Failure 404 {object} response.ErrorResponse "Deletion request not found"
+// @Failure 409 {object} response.ErrorResponse "Request already reviewed"
+// @Router /api/user/v1/admin/deletion-requests/{id}/reject [post]
+func (h *AccountHandler) RejectDeletionRequest(c *fiber.Ctx) error {
+	var body dto.ReviewAccountDeletionRequestDto
+	// the body is optional for these endpoints
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&body); err != nil {
+			return apperr.WriteError(c, apperr.InvalidBody(err))
+		}
+	}
+
+	// :id is the request, not the patient
+	middleware.SetAuditTargets(c)
+	ctx := contextUtils.GetContext(c)
+	res, err := h.accountService.RejectDeletion(ctx, c.Params("id"), &body)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+	middleware.SetAuditTargets(c, res.UserID)
+	return response.OK(c, res)
+}
+
+// AnonymizeExpiredAccounts godoc
+// @Summary Anonymize closed accounts past retention
+// @Description Run the erasure job now instead of waiting for the background worker. Admins only.
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Security ApiKeyAuth
+// @Success 200 {object} response.BaseResponse{data=dto.AnonymizeAccountsResponseDto} "Number of accounts anonymized"
+// @Failure 403 {object} response.ErrorResponse "Insufficient permissions"
+// @Failure 500 {object} response.ErrorResponse "Anonymization failed"
+// @Router /api/user/v1/admin/deletion-requests/anonymize [post]
+func (h *AccountHandler) AnonymizeExpiredAccounts(c *fiber.Ctx) error {
+	ctx := contextUtils.GetContext(c)
+	count, err := h.accountService.AnonymizeExpired(ctx)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+	return response.OK(c, dto.AnonymizeAccountsResponseDto{Anonymized: count})
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"bufio"
+	"user-service/pkg/apperr"
+	contextUtils "user-service/pkg/context"
+	"user-service/pkg/dto"
+	"user-service/pkg/middleware"
+	response "user-service/pkg/response"
+	service "user-service/pkg/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AuditHandler struct {
+	auditService *service.AuditService
+}
+
+func NewAuditHandler(auditService *service.AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService}
+}
+
+// ListAuditLog godoc
+// @Summary Query the audit log
+// @Description List audit log entries, newest first. Admins only.
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Security ApiKeyAuth
+// @Param actor_id query string false "Only entries by this user"
+// @Param patient_id query string false "Only entries targeting this patient"
+// @Param action query string false "Only entries for this action, e.g. patient.read"
+// @Param from query string false "Only entries at or after this RFC 3339 time"
+// @Param to query string false "Only entries before this RFC 3339 time"
+// @Param page query int false "Page number (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Success 200 {object} response.BaseResponse{data=[]dto.AuditEntryResponseDto} "Audit log entries"
+// @Failure 400 {object} response.ErrorResponse "Invalid filter"
+// @Failure 403 {object} response.ErrorResponse "Insufficient permissions"
+// @Router /api/user/v1/admin/audit-log [get]
+func (h *AuditHandler) ListAuditLog(c *fiber.Ctx) error {
+	var query dto.AuditLogQueryDto
+	if err := c.QueryParser(&query); err != nil {
+		return apperr.WriteError(c, apperr.New(apperr.CodeBadRequest, "invalid query parameters", err))
+	}
+	if query.PatientID != "" {
+		middleware.SetAuditTargets(c, query.PatientID)
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.auditService.List(ctx, &query)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+	return response.Paginated(c, res.Items, response.Pagination{Page: res.Page, PageSize: res.PageSize, Total: res.Total})
+}
+
+// ExportAuditLog godoc
+// @Summary Export the audit log
+// @Description Download matching audit log entries, oldest first, as JSON lines including their hashes. Admins only.
+// @Tags admin
+// @Produce  application/x-ndjson
+// @Security ApiKeyAuth
+// @Param actor_id query string false "Only entries by this user"
+// @Param patient_id query string false "Only entries targeting this patient"
+// @Param action query string false "Only entries for this action, e.g. patient.read"
+// @Param from query string false "Only entries at or after this RFC 3339 time"
+// @Param to query string false "Only entries before this RFC 3339 time"
+// @Success 200 {string} string "One dto.AuditEntryResponseDto per line"
+// @Failure 400 {object} response.ErrorResponse "Invalid filter"
+// @Failure 403 {object} response.ErrorResponse "Insufficient permissions"
+// @Router /api/user/v1/admin/audit-log/export [get]
+func (h *AuditHandler) ExportAuditLog(c *fiber.Ctx) error {
+	var query dto.AuditLogQueryDto
+	if err := c.QueryParser(&query); err != nil {
+		return apperr.WriteError(c, apperr.New(apperr.CodeBadRequest, "invalid query parameters", err))
+	}
+	if query.PatientID != "" {
+		middleware.SetAuditTargets(c, query.PatientID)
+	}
+
+	ctx := contextUtils.GetContext(c)
+	w := bufio.NewWriter(c.Response().BodyWriter())
+	if err := h.auditService.Export(ctx, &query, w); err != nil {
+		c.Response().ResetBody()
+		return apperr.WriteError(c, err)
+	}
+	if err := w.Flush(); err != nil {
+		return apperr.WriteError(c, apperr.New(apperr.CodeInternal, "export audit log failed", err))
+	}
+	c.Set(fiber.HeaderContentType, "application/x-ndjson")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="audit-log.ndjson"`)
+	return nil
+}
+
+// VerifyAuditLog godoc
+// @Summary Verify the audit log
+// @Description Recompute the hash chain of the whole audit log and report the first entry that does not match. Admins only.
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Security ApiKeyAuth
+// @Success 200 {object} response.BaseResponse{data=dto.AuditVerifyResponseDto} "Verification result"
+// @Failure 403 {object} response.ErrorResponse "Insufficient permissions"
+// @Router /api/user/v1/admin/audit-log/verify [get]
+func (h *AuditHandler) VerifyAuditLog(c *fiber.Ctx) error {
+	ctx := contextUtils.GetContext(c)
+	res, err := h.auditService.Verify(ctx)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+	return response.OK(c, res)
+}
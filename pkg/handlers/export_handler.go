@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"bufio"
+	"user-service/pkg/apperr"
+	contextUtils "user-service/pkg/context"
+	"user-service/pkg/dto"
+	response "user-service/pkg/response"
+	service "user-service/pkg/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ExportHandler struct {
+	exportService *service.ExportService
+}
+
+func NewExportHandler(exportService *service.ExportService) *ExportHandler {
+	return &ExportHandler{
+		exportService: exportService}
+}
+
+// ExportData godoc
+// @Summary Export my data
+// @Description Get a copy of everything the service holds about the authenticated patient: account, patient record, healthcare entitlements, profile changes, deletion requests and the audit entries about them. With format=zip the export is a download holding data.json and a readable summary.txt. The service keeps no consent records or sessions.
+// @Tags patients
+// @Produce  json
+// @Produce  application/zip
+// @Security ApiKeyAuth
+// @Param format query string false "json (default) or zip"
+// @Success 200 {object} response.BaseResponse{data=dto.DataExportDto} "The patient's data"
+// @Failure 400 {object} response.ErrorResponse "Invalid format"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} response.ErrorResponse "Insufficient permissions"
+// @Router /api/user/v1/patient/me/export [get]
+func (h *ExportHandler) ExportData(c *fiber.Ctx) error {
+	var query dto.DataExportQueryDto
+	if err := c.QueryParser(&query); err != nil {
+		return apperr.WriteError(c, apperr.New(apperr.CodeBadRequest, "invalid query parameters", err))
+	}
+	if query.Format != "" && query.Format != "json" && query.Format != "zip" {
+		return apperr.WriteError(c, apperr.New(apperr.CodeBadRequest, "format must be json or zip", nil))
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.exportService.Export(ctx)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+	if query.Format != "zip" {
+		return response.OK(c, res)
+	}
+
+	w := bufio.NewWriter(c.Response().BodyWriter())
+	if err := h.exportService.WriteBundle(w, res); err != nil {
+		c.Response().ResetBody()
+		return apperr.WriteError(c, err)
+	}
+	if err := w.Flush(); err != nil {
+		return apperr.WriteError(c, apperr.New(apperr.CodeInternal, "write export failed", err))
+	}
+	c.Set(fiber.HeaderContentType, "application/zip")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="my-data.zip"`)
+	return nil
+}
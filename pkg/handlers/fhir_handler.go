@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"user-service/pkg/apperr"
+	contextUtils "user-service/pkg/context"
+	"user-service/pkg/dto"
+	"user-service/pkg/fhir"
+	"user-service/pkg/middleware"
+	service "user-service/pkg/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// FHIRHandler serves the FHIR R4 API. Its responses are bare FHIR resources
+// rather than the response envelope, and its errors are OperationOutcomes.
+type FHIRHandler struct {
+	fhirService *service.FHIRService
+}
+
+func NewFHIRHandler(fhirService *service.FHIRService) *FHIRHandler {
+	return &FHIRHandler{
+		fhirService: fhirService}
+}
+
+// ReadPatient godoc
+// @Summary Read a FHIR Patient
+// @Description Get a patient as a FHIR R4 Patient, identified by hospital number and Thai national ID, with the emergency contact as a contact. Doctors and admins only.
+// @Tags fhir
+// @Produce  application/fhir+json
+// @Security ApiKeyAuth
+// @Param id path string true "Patient ID"
+// @Success 200 {object} fhir.Patient "The patient"
+// @Failure 401 {object} fhir.OperationOutcome "Missing or invalid token"
+// @Failure 403 {object} fhir.OperationOutcome "Insufficient permissions"
+// @Failure 404 {object} fhir.OperationOutcome "Patient not found"
+// @Router /api/user/v1/fhir/Patient/{id} [get]
+func (h *FHIRHandler) ReadPatient(c *fiber.Ctx) error {
+	ctx := contextUtils.GetContext(c)
+	res, err := h.fhirService.ReadPatient(ctx, c.Params("id"))
+	if err != nil {
+		return WriteOperationOutcome(c, err)
+	}
+	return writeFHIR(c, res)
+}
+
+// SearchPatients godoc
+// @Summary Search FHIR Patients
+// @Description Search patients, returning a FHIR R4 searchset Bundle. identifier is [system|]value, where the system is the Thai national ID system or the configured hospital number system; name matches the start of a first or last name. Doctors and admins only.
+// @Tags fhir
+// @Produce  application/fhir+json
+// @Security ApiKeyAuth
+// @Param _id query string false "Patient IDs, comma-separated"
+// @Param identifier query string false "Hospital number or national ID, as [system|]value"
+// @Param name query string false "Start of a first or last name"
+// @Param _count query int false "Page size (default 20, max 100)"
+// @Param _offset query int false "Number of matches to skip"
+// @Success 200 {object} fhir.Bundle "Matching patients"
+// @Failure 400 {object} fhir.OperationOutcome "Invalid search parameters"
+// @Failure 401 {object} fhir.OperationOutcome "Missing or invalid token"
+// @Failure 403 {object} fhir.OperationOutcome "Insufficient permissions"
+// @Router /api/user/v1/fhir/Patient [get]
+func (h *FHIRHandler) SearchPatients(c *fiber.Ctx) error {
+	var query dto.FHIRSearchQueryDto
+	if err := c.QueryParser(&query); err != nil {
+		return WriteOperationOutcome(c, apperr.New(apperr.CodeBadRequest, "invalid search parameters", err))
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.fhirService.SearchPatients(ctx, fhirBase(c), &query)
+	if err != nil {
+		return WriteOperationOutcome(c, err)
+	}
+	ids := make([]string, 0, len(res.Entry))
+	for _, entry := range res.Entry {
+		ids = append(ids, entry.Resource.(*fhir.Patient).ID)
+	}
+	middleware.SetAuditTargets(c, ids...)
+	return writeFHIR(c, res)
+}
+
+// ReadPractitioner godoc
+// @Summary Read a FHIR Practitioner
+// @Description Get a doctor as a FHIR R4 Practitioner, identified by username.
+// @Tags fhir
+// @Produce  application/fhir+json
+// @Security ApiKeyAuth
+// @Param id path string true "Doctor ID"
+// @Success 200 {object} fhir.Practitioner "The doctor"
+// @Failure 401 {object} fhir.OperationOutcome "Missing or invalid token"
+// @Failure 404 {object} fhir.OperationOutcome "Doctor not found"
+// @Router /api/user/v1/fhir/Practitioner/{id} [get]
+func (h *FHIRHandler) ReadPractitioner(c *fiber.Ctx) error {
+	ctx := contextUtils.GetContext(c)
+	res, err := h.fhirService.ReadPractitioner(ctx, c.Params("id"))
+	if err != nil {
+		return WriteOperationOutcome(c, err)
+	}
+	return writeFHIR(c, res)
+}
+
+// SearchPractitioners godoc
+// @Summary Search FHIR Practitioners
+// @Description Search doctors, returning a FHIR R4 searchset Bundle. identifier is [system|]username, where the system is the configured practitioner system; name matches the start of a first or last name.
+// @Tags fhir
+// @Produce  application/fhir+json
+// @Security ApiKeyAuth
+// @Param _id query string false "Doctor IDs, comma-separated"
+// @Param identifier query string false "Username, as [system|]value"
+// @Param name query string false "Start of a first or last name"
+// @Param _count query int false "Page size (default 20, max 100)"
+// @Param _offset query int false "Number of matches to skip"
+// @Success 200 {object} fhir.Bundle "Matching doctors"
+// @Failure 400 {object} fhir.OperationOutcome "Invalid search parameters"
+// @Failure 401 {object} fhir.OperationOutcome "Missing or invalid token"
+// @Router /api/user/v1/fhir/Practitioner [get]
+func (h *FHIRHandler) SearchPractitioners(c *fiber.Ctx) error {
+	var query dto.FHIRSearchQueryDto
+	if err := c.QueryParser(&query); err != nil {
+		return WriteOperationOutcome(c, apperr.New(apperr.CodeBadRequest, "invalid search parameters", err))
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.fhirService.SearchPractitioners(ctx, fhirBase(c), &query)
+	if err != nil {
+		return WriteOperationOutcome(c, err)
+	}
+	return writeFHIR(c, res)
+}
+
+// ReadPractitionerRole godoc
+// @Summary Read a FHIR PractitionerRole
+// @Description Get a doctor's role and specialty as a FHIR R4 PractitionerRole. It has the ID of the doctor's Practitioner.
+// @Tags fhir
+// @Produce  application/fhir+json
+// @Security ApiKeyAuth
+// @Param id path string true "Doctor ID"
+// @Success 200 {object} fhir.PractitionerRole "The doctor's role"
+// @Failure 401 {object} fhir.OperationOutcome "Missing or invalid token"
+// @Failure 404 {object} fhir.OperationOutcome "Doctor not found"
+// @Router /api/user/v1/fhir/PractitionerRole/{id} [get]
+func (h *FHIRHandler) ReadPractitionerRole(c *fiber.Ctx) error {
+	ctx := contextUtils.GetContext(c)
+	res, err := h.fhirService.ReadPractitionerRole(ctx, c.Params("id"))
+	if err != nil {
+		return WriteOperationOutcome(c, err)
+	}
+	return writeFHIR(c, res)
+}
+
+// SearchPractitionerRoles godoc
+// @Summary Search FHIR PractitionerRoles
+// @Description Search doctors' roles, returning a FHIR R4 searchset Bundle. Roles have no identifier or name of their own, so those parameters are rejected.
+// @Tags fhir
+// @Produce  application/fhir+json
+// @Security ApiKeyAuth
+// @Param _id query string false "Role IDs, comma-separated"
+// @Param practitioner query string false "Practitioner references or IDs, comma-separated"
+// @Param _count query int false "Page size (default 20, max 100)"
+// @Param _offset query int false "Number of matches to skip"
+// @Success 200 {object} fhir.Bundle "Matching roles"
+// @Failure 400 {object} fhir.OperationOutcome "Invalid search parameters, or identifier or name"
+// @Failure 401 {object} fhir.OperationOutcome "Missing or invalid token"
+// @Router /api/user/v1/fhir/PractitionerRole [get]
+func (h *FHIRHandler) SearchPractitionerRoles(c *fiber.Ctx) error {
+	var query dto.FHIRSearchQueryDto
+	if err := c.QueryParser(&query); err != nil {
+		return WriteOperationOutcome(c, apperr.New(apperr.CodeBadRequest, "invalid search parameters", err))
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.fhirService.SearchPractitionerRoles(ctx, fhirBase(c), &query)
+	if err != nil {
+		return WriteOperationOutcome(c, err)
+	}
+	return writeFHIR(c, res)
+}
+
+// fhirBase returns the URL of the FHIR API from a search request, whose path
+// is the base followed by the resource type.
+func fhirBase(c *fiber.Ctx) string {
+	path := strings.TrimSuffix(c.Path(), "/")
+	return c.BaseURL() + path[:strings.LastIndex(path, "/")]
+}
+
+func writeFHIR(c *fiber.Ctx, resource any) error {
+	return c.Status(fiber.StatusOK).JSON(resource, fhir.MIMEType)
+}
+
+// fhirIssueCodes maps error codes to OperationOutcome issue types.
+var fhirIssueCodes = map[apperr.Code]string{
+	apperr.CodeBadRequest:   "invalid",
+	apperr.CodeUnauthorized: "login",
+	apperr.CodeForbidden:    "forbidden",
+	apperr.CodeNotFound:     "not-found",
+}
+
+// WriteOperationOutcome is apperr.WriteError for the FHIR API. It is also
+// the error writer of the FHIR routes' authentication and role checks.
+func WriteOperationOutcome(c *fiber.Ctx, err error) error {
+	code := apperr.CodeInternal
+	msg := "internal error"
+	var ae *apperr.Error
+	if errors.As(err, &ae) {
+		code = ae.Code
+		msg = ae.Msg
+	}
+	if code == apperr.CodeInternal {
+		slog.ErrorContext(c.UserContext(), msg, "error", err)
+	}
+	issue, ok := fhirIssueCodes[code]
+	if !ok {
+		issue = "exception"
+	}
+	return c.Status(code.Status()).JSON(fhir.OperationOutcome{
+		ResourceType: "OperationOutcome",
+		Issue:        []fhir.OperationOutcomeIssue{{Severity: "error", Code: issue, Diagnostics: msg}},
+	}, fhir.MIMEType)
+}
@@ -1,10 +1,10 @@
 package handlers
 
 import (
-	"fmt"
 	"user-service/pkg/apperr"
 	contextUtils "user-service/pkg/context"
 	"user-service/pkg/dto"
+	"user-service/pkg/middleware"
 	response "user-service/pkg/response"
 	service "user-service/pkg/services"
 
@@ -28,22 +28,23 @@ func NewUserHandler(userService *service.UserService) *UserHandler {
 // @Accept  json
 // @Produce  json
 // @Param patient body dto.PatientRegisterPatientRequestDto true "Patient registration data"
-// @Success 201 {object} dto.PatientRegisterResponseDto "Patient registered successfully"
+// @Success 201 {object} response.BaseResponse{data=dto.PatientRegisterResponseDto} "Patient registered successfully"
 // @Failure 400 {object} response.ErrorResponse "Invalid request body"
 // @Failure 500 {object} response.ErrorResponse "Failed to register user"
 // @Router /api/user/v1/patient/register [post]
 func (h *UserHandler) PatientRegister(c *fiber.Ctx) error {
-	fmt.Println("Register endpoint hit")
 	var body dto.PatientRegisterPatientRequestDto
 	if err := c.BodyParser(&body); err != nil {
-		return response.BadRequest(c, "Invalid request body "+err.Error())
+		return apperr.WriteError(c, apperr.InvalidBody(err))
 	}
 
+	middleware.SetAuditStatus(c, fiber.StatusCreated)
 	ctx := contextUtils.GetContext(c)
 	res, err := h.userService.Register(ctx, &body)
 	if err != nil {
 		return apperr.WriteError(c, err)
 	}
+	middleware.SetAuditTargets(c, res.UserID)
 
 	return response.Created(c, res)
 }
@@ -55,14 +56,14 @@ func (h *UserHandler) PatientRegister(c *fiber.Ctx) error {
 // @Accept  json
 // @Produce  json
 // @Param patient body dto.PatientLoginRequestDto true "Patient login credentials"
-// @Success 200 {object} dto.PatientLoginResponseDto "Patient logged in successfully"
+// @Success 200 {object} response.BaseResponse{data=dto.PatientLoginResponseDto} "Patient logged in successfully"
 // @Failure 400 {object} response.ErrorResponse "Invalid request body"
 // @Failure 401 {object} response.ErrorResponse "Invalid credentials"
 // @Router /api/user/v1/patient/login [post]
 func (h *UserHandler) PatientLogin(c *fiber.Ctx) error {
 	var body dto.PatientLoginRequestDto
 	if err := c.BodyParser(&body); err != nil {
-		return response.BadRequest(c, "Invalid request body "+err.Error())
+		return apperr.WriteError(c, apperr.InvalidBody(err))
 	}
 
 	ctx := contextUtils.GetContext(c)
@@ -88,14 +89,14 @@ func (h *UserHandler) PatientLogin(c *fiber.Ctx) error {
 // @Accept  json
 // @Produce  json
 // @Param doctor body dto.DoctorLoginRequestDto true "Doctor login credentials"
-// @Success 200 {object} dto.DoctorLoginResponseDto "Doctor logged in successfully"
+// @Success 200 {object} response.BaseResponse{data=dto.DoctorLoginResponseDto} "Doctor logged in successfully"
 // @Failure 400 {object} response.ErrorResponse "Invalid request body"
 // @Failure 401 {object} response.ErrorResponse "Invalid credentials"
 // @Router /api/user/v1/doctor/login [post]
 func (h *UserHandler) DoctorLogin(c *fiber.Ctx) error {
 	var body dto.DoctorLoginRequestDto
 	if err := c.BodyParser(&body); err != nil {
-		return response.BadRequest(c, "Invalid request body "+err.Error())
+		return apperr.WriteError(c, apperr.InvalidBody(err))
 	}
 	ctx := contextUtils.GetContext(c)
 	res, err := h.userService.DoctorLogin(ctx, &body)
@@ -112,6 +113,37 @@ func (h *UserHandler) DoctorLogin(c *fiber.Ctx) error {
 	return response.OK(c, res)
 }
 
+// AdminLogin godoc
+// @Summary Login an admin
+// @Description Authenticate an admin and return access token with cookie
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Param admin body dto.AdminLoginRequestDto true "Admin login credentials"
+// @Success 200 {object} response.BaseResponse{data=dto.AdminLoginResponseDto} "Admin logged in successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body"
+// @Failure 401 {object} response.ErrorResponse "Invalid credentials"
+// @Router /api/user/v1/admin/login [post]
+func (h *UserHandler) AdminLogin(c *fiber.Ctx) error {
+	var body dto.AdminLoginRequestDto
+	if err := c.BodyParser(&body); err != nil {
+		return apperr.WriteError(c, apperr.InvalidBody(err))
+	}
+	ctx := contextUtils.GetContext(c)
+	res, err := h.userService.AdminLogin(ctx, &body)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+	// set token in cookie
+	c.Cookie(&fiber.Cookie{
+		Name:     "access_token",
+		Value:    res.AccessToken,
+		HTTPOnly: true,
+		SameSite: "None",
+	})
+	return response.OK(c, res)
+}
+
 // Profile godoc
 // @Summary Get patient profile
 // @Description Get the profile information of the authenticated patient
@@ -119,7 +151,8 @@ func (h *UserHandler) DoctorLogin(c *fiber.Ctx) error {
 // @Accept  json
 // @Produce  json
 // @Security ApiKeyAuth
-// @Success 200 {object} dto.GetProfileResponseDto "Profile retrieved successfully"
+// @Success 200 {object} response.BaseResponse{data=dto.GetProfileResponseDto} "Profile retrieved successfully"
+// @Header 200 {string} ETag "Version of the profile, to send as If-Match when updating it"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized - Invalid or missing token"
 // @Failure 500 {object} response.ErrorResponse "Failed to get user profile"
 // @Router /api/user/v1/patient/me [get]
@@ -129,6 +162,7 @@ func (h *UserHandler) Profile(c *fiber.Ctx) error {
 	if err != nil {
 		return apperr.WriteError(c, err)
 	}
+	c.Set(fiber.HeaderETag, user.ETag)
 	return response.OK(c, user)
 }
 
@@ -139,27 +173,28 @@ func (h *UserHandler) Profile(c *fiber.Ctx) error {
 // @Accept  json
 // @Produce  json
 // @Security ApiKeyAuth
+// @Param If-Match header string true "ETag of the profile as last read"
 // @Param patient body dto.UpdatePatientProfileRequestDto true "Patient profile update data"
-// @Success 200 {object} dto.UpdatePatientProfileResponseDto "Profile updated successfully"
+// @Success 200 {object} response.BaseResponse{data=dto.UpdatePatientProfileResponseDto} "Profile updated successfully"
+// @Header 200 {string} ETag "Version of the updated profile"
 // @Failure 400 {object} response.ErrorResponse "Invalid request body or user not found"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 412 {object} response.ErrorResponse "Profile was modified since the If-Match ETag was read"
+// @Failure 428 {object} response.ErrorResponse "If-Match header is missing"
 // @Failure 500 {object} response.ErrorResponse "Failed to update user profile"
 // @Router /api/user/v1/patient [patch]
 func (h *UserHandler) UpdatePatientProfile(c *fiber.Ctx) error {
 	var body dto.UpdatePatientProfileRequestDto
-	fmt.Println("Hello from updatepatient profile")
 	if err := c.BodyParser(&body); err != nil {
-		return response.BadRequest(c, "Invalid request body "+err.Error())
+		return apperr.WriteError(c, apperr.InvalidBody(err))
 	}
-	fmt.Println("geting context")
 	ctx := contextUtils.GetContext(c)
-	// print ctx for debugging
-	fmt.Println("UpdatePatientProfile endpoint hit, ctx:", ctx)
 
-	res, err := h.userService.UpdateProfileByID(ctx, &body)
+	res, err := h.userService.UpdateProfileByID(ctx, c.Get(fiber.HeaderIfMatch), &body)
 	if err != nil {
 		return apperr.WriteError(c, err)
 	}
+	c.Set(fiber.HeaderETag, res.ETag)
 	return response.OK(c, res)
 }
 
@@ -170,14 +205,14 @@ func (h *UserHandler) UpdatePatientProfile(c *fiber.Ctx) error {
 // @Accept  json
 // @Produce  json
 // @Param body body dto.GetDoctorsByIDsRequestDto true "Doctor IDs"
-// @Success 200 {object} []dto.GetDoctorProfileResponseDto "Doctor profiles retrieved successfully"
+// @Success 200 {object} response.BaseResponse{data=[]dto.GetDoctorProfileResponseDto} "Doctor profiles retrieved successfully"
 // @Failure 404 {object} response.ErrorResponse "Doctors not found"
 // @Failure 500 {object} response.ErrorResponse "Failed to get doctor profiles"
 // @Router /api/user/v1/doctors [post]
 func (h *UserHandler) GetDoctorByIDs(c *fiber.Ctx) error {
 	var body dto.GetDoctorsByIDsRequestDto
 	if err := c.BodyParser(&body); err != nil {
-		return response.BadRequest(c, "Invalid request body "+err.Error())
+		return apperr.WriteError(c, apperr.InvalidBody(err))
 	}
 
 	ctx := contextUtils.GetContext(c)
@@ -195,14 +230,14 @@ func (h *UserHandler) GetDoctorByIDs(c *fiber.Ctx) error {
 // @Accept  json
 // @Produce  json
 // @Param body body dto.GetPatientsByIDsRequestDto true "Patient IDs"
-// @Success 200 {object} []dto.GetProfileResponseDto "Patient profiles retrieved successfully"
+// @Success 200 {object} response.BaseResponse{data=[]dto.GetProfileResponseDto} "Patient profiles retrieved successfully"
 // @Failure 404 {object} response.ErrorResponse "Patients not found"
 // @Failure 500 {object} response.ErrorResponse "Failed to get patient profiles"
 // @Router /api/user/v1/patients [post]
 func (h *UserHandler) GetPatientByIDs(c *fiber.Ctx) error {
 	var body dto.GetPatientsByIDsRequestDto
 	if err := c.BodyParser(&body); err != nil {
-		return response.BadRequest(c, "Invalid request body "+err.Error())
+		return apperr.WriteError(c, apperr.InvalidBody(err))
 	}
 
 	ctx := contextUtils.GetContext(c)
@@ -210,6 +245,7 @@ func (h *UserHandler) GetPatientByIDs(c *fiber.Ctx) error {
 	if err != nil {
 		return apperr.WriteError(c, err)
 	}
+	setPatientAuditTargets(c, patients)
 	return response.OK(c, patients)
 }
 
@@ -219,7 +255,7 @@ func (h *UserHandler) GetPatientByIDs(c *fiber.Ctx) error {
 // @Tags doctors
 // @Accept  json
 // @Produce  json
-// @Success 200 {object} []dto.GetDoctorProfileResponseDto "Doctor profiles retrieved successfully"
+// @Success 200 {object} response.BaseResponse{data=[]dto.GetDoctorProfileResponseDto} "Doctor profiles retrieved successfully"
 // @Failure 500 {object} response.ErrorResponse "Failed to get doctor profiles"
 // @Router /api/user/v1/doctors [get]
 func (h *UserHandler) GetAllDoctors(c *fiber.Ctx) error {
@@ -230,3 +266,129 @@ func (h *UserHandler) GetAllDoctors(c *fiber.Ctx) error {
 	}
 	return response.OK(c, doctors)
 }
+
+// SearchDoctors godoc
+// @Summary Search doctors
+// @Description Fuzzy search doctors by name, username, specialty or bio, best match first
+// @Tags doctors
+// @Accept  json
+// @Produce  json
+// @Security ApiKeyAuth
+// @Param q query string true "Search term (at least 2 characters)"
+// @Param limit query int false "Maximum number of results (default 20, max 100)"
+// @Success 200 {object} response.BaseResponse{data=[]dto.GetDoctorProfileResponseDto} "Matching doctor profiles"
+// @Failure 400 {object} response.ErrorResponse "Invalid search query"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 500 {object} response.ErrorResponse "Failed to search doctors"
+// @Router /api/user/v1/doctors/search [get]
+func (h *UserHandler) SearchDoctors(c *fiber.Ctx) error {
+	var query dto.SearchRequestDto
+	if err := c.QueryParser(&query); err != nil {
+		return apperr.WriteError(c, apperr.New(apperr.CodeBadRequest, "invalid query parameters", err))
+	}
+
+	ctx := contextUtils.GetContext(c)
+	doctors, err := h.userService.SearchDoctors(ctx, &query)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+	return response.OK(c, doctors)
+}
+
+// SearchPatients godoc
+// @Summary Search patients
+// @Description Fuzzy search patients by first/last name or hospital ID, best match first. Doctors and admins only.
+// @Tags patients
+// @Accept  json
+// @Produce  json
+// @Security ApiKeyAuth
+// @Param q query string true "Search term (at least 2 characters)"
+// @Param limit query int false "Maximum number of results (default 20, max 100)"
+// @Success 200 {object} response.BaseResponse{data=[]dto.GetProfileResponseDto} "Matching patient profiles"
+// @Failure 400 {object} response.ErrorResponse "Invalid search query"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} response.ErrorResponse "Insufficient permissions"
+// @Failure 500 {object} response.ErrorResponse "Failed to search patients"
+// @Router /api/user/v1/patients/search [get]
+func (h *UserHandler) SearchPatients(c *fiber.Ctx) error {
+	var query dto.SearchRequestDto
+	if err := c.QueryParser(&query); err != nil {
+		return apperr.WriteError(c, apperr.New(apperr.CodeBadRequest, "invalid query parameters", err))
+	}
+
+	ctx := contextUtils.GetContext(c)
+	patients, err := h.userService.SearchPatients(ctx, &query)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+	setPatientAuditTargets(c, patients)
+	return response.OK(c, patients)
+}
+
+// GetPatientProfileHistory godoc
+// @Summary Get patient profile history
+// @Description List the changes made to a patient's profile, one entry per field, newest first. Doctors and admins may read any patient's history, patients only their own.
+// @Tags patients
+// @Accept  json
+// @Produce  json
+// @Security ApiKeyAuth
+// @Param id path string true "Patient ID"
+// @Param page query int false "Page number (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Success 200 {object} response.BaseResponse{data=[]dto.ProfileChangeResponseDto} "Profile changes"
+// @Failure 400 {object} response.ErrorResponse "Invalid patient ID"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - Invalid or missing token"
+// @Failure 403 {object} response.ErrorResponse "History of another patient"
+// @Failure 404 {object} response.ErrorResponse "Patient not found"
+// @Router /api/user/v1/patients/{id}/history [get]
+func (h *UserHandler) GetPatientProfileHistory(c *fiber.Ctx) error {
+	var query dto.PageQueryDto
+	if err := c.QueryParser(&query); err != nil {
+		return apperr.WriteError(c, apperr.New(apperr.CodeBadRequest, "invalid query parameters", err))
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.userService.GetProfileHistory(ctx, c.Params("id"), &query)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+	return response.Paginated(c, res.Items, response.Pagination{Page: res.Page, PageSize: res.PageSize, Total: res.Total})
+}
+
+// SetPatientPassword godoc
+// @Summary Set a patient's password
+// @Description Set or reset a patient's password, for patients imported without one or who have lost theirs. Admins only.
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Security ApiKeyAuth
+// @Param id path string true "Patient ID"
+// @Param body body dto.SetPatientPasswordRequestDto true "New password"
+// @Success 200 {object} response.BaseResponse{data=dto.SetPatientPasswordResponseDto} "Password set"
+// @Failure 400 {object} response.ErrorResponse "Invalid patient ID or password"
+// @Failure 403 {object} response.ErrorResponse "Insufficient permissions"
+// @Failure 404 {object} response.ErrorResponse "Patient not found"
+// @Router /api/user/v1/admin/patients/{id}/password [put]
+func (h *UserHandler) SetPatientPassword(c *fiber.Ctx) error {
+	var body dto.SetPatientPasswordRequestDto
+	if err := c.BodyParser(&body); err != nil {
+		return apperr.WriteError(c, apperr.InvalidBody(err))
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.userService.SetPatientPassword(ctx, c.Params("id"), &body)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+	return response.OK(c, res)
+}
+
+// setPatientAuditTargets records the patients in a response as the targets of
+// the request's audit entry.
+func setPatientAuditTargets(c *fiber.Ctx, patients []*dto.GetProfileResponseDto) {
+	ids := make([]string, 0, len(patients))
+	for _, patient := range patients {
+		ids = append(ids, patient.ID)
+	}
+	middleware.SetAuditTargets(c, ids...)
+}
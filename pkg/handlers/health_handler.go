@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"user-service/pkg/apperr"
+	contextUtils "user-service/pkg/context"
+	"user-service/pkg/dto"
+	response "user-service/pkg/response"
+	service "user-service/pkg/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type HealthHandler struct {
+	healthService *service.HealthService
+}
+
+func NewHealthHandler(healthService *service.HealthService) *HealthHandler {
+	return &HealthHandler{
+		healthService: healthService}
+}
+
+// Healthz godoc
+// @Summary Liveness probe
+// @Description Reports that the process is up. It checks no dependencies.
+// @Tags health
+// @Produce  json
+// @Success 200 {object} response.BaseResponse{data=dto.HealthResponseDto} "Process is up"
+// @Router /healthz [get]
+func (h *HealthHandler) Healthz(c *fiber.Ctx) error {
+	return response.OK(c, dto.HealthResponseDto{Status: "ok"})
+}
+
+// Readyz godoc
+// @Summary Readiness probe
+// @Description Reports whether the service can serve traffic: the database answers, every migration is applied and the encryption keys work.
+// @Tags health
+// @Produce  json
+// @Success 200 {object} response.BaseResponse{data=dto.ReadinessResponseDto} "Ready"
+// @Failure 503 {object} response.ErrorResponse "Not ready; details name the failed checks"
+// @Router /readyz [get]
+func (h *HealthHandler) Readyz(c *fiber.Ctx) error {
+	ctx := contextUtils.GetContext(c)
+	res, err := h.healthService.Ready(ctx)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+	return response.OK(c, res)
+}
+
+// Version godoc
+// @Summary Build and schema version
+// @Description Reports the version and commit of the running binary and the migration version of the database.
+// @Tags health
+// @Produce  json
+// @Success 200 {object} response.BaseResponse{data=dto.VersionResponseDto} "Version information"
+// @Router /version [get]
+func (h *HealthHandler) Version(c *fiber.Ctx) error {
+	ctx := contextUtils.GetContext(c)
+	return response.OK(c, h.healthService.Version(ctx))
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"bytes"
+	"io"
+	"user-service/pkg/apperr"
+	contextUtils "user-service/pkg/context"
+	"user-service/pkg/dto"
+	"user-service/pkg/middleware"
+	response "user-service/pkg/response"
+	service "user-service/pkg/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ImportHandler struct {
+	importService *service.ImportService
+}
+
+func NewImportHandler(importService *service.ImportService) *ImportHandler {
+	return &ImportHandler{
+		importService: importService}
+}
+
+// ImportPatients godoc
+// @Summary Import patients from CSV
+// @Description Register patients in bulk from a CSV file, sent as the "file" field of a multipart form or as a text/csv body. The header names the columns: hospital_id, first_name, last_name and gender are required; phone_number, birth_date (YYYY-MM-DD), id_card_number, blood_type, healthcare_entitlements (separated by ";"), address, allergies, emergency_contact and password are optional. Rows are checked like a registration; rows that fail are reported by line and skipped. Patients imported without a password cannot sign in until an admin sets one with PUT /api/user/v1/admin/patients/{id}/password. Admins only.
+// @Tags admin
+// @Accept  mpfd
+// @Accept  text/csv
+// @Produce  json
+// @Security ApiKeyAuth
+// @Param file formData file false "CSV file"
+// @Param dry_run query bool false "Check every row and report the outcome without importing anything"
+// @Param batch_size query int false "Rows written per transaction (default 100, max 1000)"
+// @Success 200 {object} response.BaseResponse{data=dto.PatientImportResponseDto} "Import report"
+// @Failure 400 {object} response.ErrorResponse "Unreadable file, unknown or missing column, or invalid batch size"
+// @Failure 403 {object} response.ErrorResponse "Insufficient permissions"
+// @Router /api/user/v1/admin/patients/import [post]
+func (h *ImportHandler) ImportPatients(c *fiber.Ctx) error {
+	var query dto.PatientImportQueryDto
+	if err := c.QueryParser(&query); err != nil {
+		return apperr.WriteError(c, apperr.New(apperr.CodeBadRequest, "invalid query parameters", err))
+	}
+
+	var file io.Reader = bytes.NewReader(c.Body())
+	if form, err := c.MultipartForm(); err == nil {
+		headers := form.File["file"]
+		if len(headers) != 1 {
+			return apperr.WriteError(c, apperr.New(apperr.CodeBadRequest, "send one CSV file in the file field", nil))
+		}
+		f, err := headers[0].Open()
+		if err != nil {
+			return apperr.WriteError(c, apperr.New(apperr.CodeBadRequest, "cannot read the uploaded file", err))
+		}
+		defer f.Close()
+		file = f
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.importService.ImportPatients(ctx, file, &query)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+	middleware.SetAuditTargets(c, res.UserIDs...)
+	return response.OK(c, res)
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"user-service/pkg/apperr"
+	contextUtils "user-service/pkg/context"
+	"user-service/pkg/dto"
+	"user-service/pkg/middleware"
+	response "user-service/pkg/response"
+	service "user-service/pkg/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type RetentionHandler struct {
+	retentionService *service.RetentionService
+}
+
+func NewRetentionHandler(retentionService *service.RetentionService) *RetentionHandler {
+	return &RetentionHandler{
+		retentionService: retentionService}
+}
+
+// ListDeletedUsers godoc
+// @Summary List soft-deleted users
+// @Description List soft-deleted users, most recently deleted first. Admins only.
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Security ApiKeyAuth
+// @Param role query string false "Filter by role (patient, doctor, admin)"
+// @Param page query int false "Page number (default 1)"
+// @Param page_size query int false "Page size (default 20, max 100)"
+// @Success 200 {object} response.BaseResponse{data=[]dto.DeletedUserResponseDto} "Soft-deleted users"
+// @Failure 400 {object} response.ErrorResponse "Invalid role filter"
+// @Failure 403 {object} response.ErrorResponse "Insufficient permissions"
+// @Router /api/user/v1/admin/users/deleted [get]
+func (h *RetentionHandler) ListDeletedUsers(c *fiber.Ctx) error {
+	var query dto.PageQueryDto
+	if err := c.QueryParser(&query); err != nil {
+		return apperr.WriteError(c, apperr.New(apperr.CodeBadRequest, "invalid query parameters", err))
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.retentionService.ListDeletedUsers(ctx, c.Query("role"), &query)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+	ids := make([]string, 0, len(res.Items))
+	for _, item := range res.Items {
+		ids = append(ids, item.ID)
+	}
+	middleware.SetAuditTargets(c, ids...)
+	return response.Paginated(c, res.Items, response.Pagination{Page: res.Page, PageSize: res.PageSize, Total: res.Total})
+}
+
+// RestoreUser godoc
+// @Summary Restore a soft-deleted user
+// @Description Restore a user and their patient or doctor row in one transaction. Admins only.
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Security ApiKeyAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} response.BaseResponse{data=dto.RestoreUserResponseDto} "User restored successfully"
+// @Failure 404 {object} response.ErrorResponse "User not found"
+// @Failure 409 {object} response.ErrorResponse "User is not deleted or was closed at the patient's request"
+// @Router /api/user/v1/admin/users/{id}/restore [post]
+func (h *RetentionHandler) RestoreUser(c *fiber.Ctx) error {
+	ctx := contextUtils.GetContext(c)
+	res, err := h.retentionService.RestoreUser(ctx, c.Params("id"))
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+	return response.OK(c, res)
+}
@@ -0,0 +1,78 @@
+// Package logging builds the service's structured logger: log/slog with the
+// request ID taken from the context and personal data redacted.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type Config struct {
+	// Level is debug, info, warn or error.
+	Level string
+	// Format is json or text.
+	Format string
+}
+
+// New returns a logger that writes to w. Every record logged with a context
+// carries that context's request ID and trace ID, and attributes that may hold personal
+// data are redacted (see Redact).
+func New(w io.Writer, cfg Config) (*slog.Logger, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q", cfg.Level)
+	}
+
+	opts := &slog.HandlerOptions{Level: level, ReplaceAttr: Redact}
+	var handler slog.Handler
+	switch strings.ToLower(cfg.Format) {
+	case "json", "":
+		handler = slog.NewJSONHandler(w, opts)
+	case "text":
+		handler = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("invalid log format %q", cfg.Format)
+	}
+	return slog.New(&contextHandler{Handler: handler}), nil
+}
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the request ID.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID carried by ctx, or "".
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// contextHandler adds the request ID and trace ID of the record's context.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,81 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+	"user-service/pkg/logging"
+)
+
+// logOne logs one record through a JSON logger and returns it decoded.
+func logOne(t *testing.T, ctx context.Context, msg string, args ...any) map[string]any {
+	t.Helper()
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, logging.Config{Level: "debug", Format: "json"})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	logger.InfoContext(ctx, msg, args...)
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("decode %q: %v", buf.String(), err)
+	}
+	return record
+}
+
+func TestLoggerAddsRequestID(t *testing.T) {
+	ctx := logging.WithRequestID(context.Background(), "req-1")
+
+	record := logOne(t, ctx, "hello")
+	if record["request_id"] != "req-1" {
+		t.Errorf("request_id = %v, want req-1", record["request_id"])
+	}
+
+	record = logOne(t, context.Background(), "hello")
+	if _, ok := record["request_id"]; ok {
+		t.Error("request_id logged without one in the context")
+	}
+}
+
+func TestLoggerRedactsPersonalData(t *testing.T) {
+	record := logOne(t, context.Background(), "patient 1-2345-67890-12-3 registered",
+		"password", "hunter2",
+		"Phone-Number", "0812345678",
+		slog.Group("body", "id_card_number", "1234567890123"),
+		"error", errors.New("duplicate key: phone 081-234-5678"),
+		"sql", `SELECT * FROM "patients" WHERE id_card_blind_index = $1`,
+		"user_id", "01234567-89ab-7def-8123-456789abcdef",
+	)
+
+	want := map[string]any{
+		"msg":          "patient [REDACTED] registered",
+		"password":     "[REDACTED]",
+		"Phone-Number": "[REDACTED]",
+		"error":        "duplicate key: phone [REDACTED]",
+		"sql":          `SELECT * FROM "patients" WHERE id_card_blind_index = $1`,
+		"user_id":      "01234567-89ab-7def-8123-456789abcdef",
+	}
+	for key, value := range want {
+		if record[key] != value {
+			t.Errorf("%s = %v, want %v", key, record[key], value)
+		}
+	}
+	if body, _ := record["body"].(map[string]any); body["id_card_number"] != "[REDACTED]" {
+		t.Errorf("body.id_card_number = %v, want [REDACTED]", body["id_card_number"])
+	}
+}
+
+func TestNewRejectsInvalidConfig(t *testing.T) {
+	for _, cfg := range []logging.Config{
+		{Level: "verbose", Format: "json"},
+		{Level: "info", Format: "xml"},
+	} {
+		if _, err := logging.New(&bytes.Buffer{}, cfg); err == nil {
+			t.Errorf("New(%+v) succeeded, want an error", cfg)
+		}
+	}
+}
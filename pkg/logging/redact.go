@@ -0,0 +1,56 @@
+package logging
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveKeys are attribute keys whose values are never logged.
+var sensitiveKeys = map[string]bool{
+	"password":          true,
+	"id_card_number":    true,
+	"phone_number":      true,
+	"phone":             true,
+	"emergency_contact": true,
+	"access_token":      true,
+	"token":             true,
+	"authorization":     true,
+	"cookie":            true,
+	"secret":            true,
+}
+
+var (
+	// Thai national ID numbers: 13 digits, optionally as 1-2345-67890-12-3.
+	idCardPattern = regexp.MustCompile(`\b\d-?\d{4}-?\d{5}-?\d{2}-?\d\b`)
+	// Thai phone numbers: 0X-XXX-XXXX, 0XX-XXX-XXXX or +66 in place of the 0.
+	phonePattern = regexp.MustCompile(`(?:\+66-?|\b0)\d{1,2}-?\d{3}-?\d{4}\b`)
+)
+
+// Redact is a slog ReplaceAttr function. It hides the value of sensitive
+// keys and masks ID card and phone numbers inside other strings and errors,
+// such as SQL or messages that quote user input.
+func Redact(_ []string, a slog.Attr) slog.Attr {
+	key := strings.ReplaceAll(strings.ToLower(a.Key), "-", "_")
+	if sensitiveKeys[key] {
+		return slog.String(a.Key, redacted)
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(RedactString(a.Value.String()))
+	case slog.KindAny:
+		if err, ok := a.Value.Any().(error); ok {
+			a.Value = slog.StringValue(RedactString(err.Error()))
+		}
+	}
+	return a
+}
+
+// RedactString masks ID card and phone numbers in s.
+func RedactString(s string) string {
+	s = idCardPattern.ReplaceAllString(s, redacted)
+	return phonePattern.ReplaceAllString(s, redacted)
+}
@@ -0,0 +1,112 @@
+// Package metrics defines the service's Prometheus metrics. They are
+// registered with the default registry, which /metrics serves.
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"user-service/pkg/apperr"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "user_service"
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route pattern and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method and route pattern.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	logins = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_total",
+		Help:      "Login attempts by role, outcome and failure reason.",
+	}, []string{"role", "outcome", "reason"})
+
+	registrations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "registrations_total",
+		Help:      "Registrations by role, outcome and failure reason.",
+	}, []string{"role", "outcome", "reason"})
+
+	clientRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "client_requests_total",
+		Help:      "Outbound requests by client, method, path and status code.",
+	}, []string{"client", "method", "path", "status"})
+
+	clientDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "client_request_duration_seconds",
+		Help:      "Outbound request latency by client, method and path.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"client", "method", "path"})
+)
+
+// ObserveHTTPRequest records a served request. route is the route pattern,
+// such as /patients/:id, so that IDs do not become label values.
+func ObserveHTTPRequest(method, route string, status int, elapsed time.Duration) {
+	httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	httpDuration.WithLabelValues(method, route).Observe(elapsed.Seconds())
+}
+
+// ObserveLogin records a login attempt; err is what the login returned.
+func ObserveLogin(role string, err error) {
+	logins.WithLabelValues(role, outcome(err), reason(err)).Inc()
+}
+
+// ObserveRegistration records a registration attempt; err is what the
+// registration returned.
+func ObserveRegistration(role string, err error) {
+	registrations.WithLabelValues(role, outcome(err), reason(err)).Inc()
+}
+
+// ObserveClientRequest records an outbound request. status is 0 when no
+// response was received, recorded as status "error".
+func ObserveClientRequest(client, method, path string, status int, elapsed time.Duration) {
+	label := "error"
+	if status != 0 {
+		label = strconv.Itoa(status)
+	}
+	clientRequests.WithLabelValues(client, method, path, label).Inc()
+	clientDuration.WithLabelValues(client, method, path).Observe(elapsed.Seconds())
+}
+
+// RegisterDB exports the connection pool statistics of db.
+func RegisterDB(db *sql.DB, dbName string) error {
+	return prometheus.Register(collectors.NewDBStatsCollector(db, dbName))
+}
+
+func outcome(err error) string {
+	if err != nil {
+		return "failure"
+	}
+	return "success"
+}
+
+// reason is the apperr code of a failure in lower case, such as
+// "unauthorized" for bad credentials, and "" on success.
+func reason(err error) string {
+	if err == nil {
+		return ""
+	}
+	var ae *apperr.Error
+	if errors.As(err, &ae) {
+		return strings.ToLower(ae.Code.String())
+	}
+	return strings.ToLower(apperr.CodeInternal.String())
+}
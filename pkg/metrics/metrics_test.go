@@ -0,0 +1,30 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"user-service/pkg/apperr"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestObserveLoginLabelsFailureReason(t *testing.T) {
+	ObserveLogin("patient", nil)
+	ObserveLogin("patient", apperr.New(apperr.CodeUnauthorized, "invalid credentials", nil))
+	ObserveLogin("patient", apperr.Wrap(errors.New("connection refused"), apperr.CodeInternal, "failed to find user"))
+	ObserveLogin("patient", errors.New("unexpected"))
+
+	for _, tc := range []struct {
+		outcome, reason string
+		want            float64
+	}{
+		{"success", "", 1},
+		{"failure", "unauthorized", 1},
+		{"failure", "internal_server_error", 2},
+	} {
+		got := testutil.ToFloat64(logins.WithLabelValues("patient", tc.outcome, tc.reason))
+		if got != tc.want {
+			t.Errorf("logins{outcome=%q, reason=%q} = %v, want %v", tc.outcome, tc.reason, got, tc.want)
+		}
+	}
+}
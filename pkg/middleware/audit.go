@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"errors"
+	"log/slog"
+	"user-service/pkg/apperr"
+	"user-service/pkg/models"
+	service "user-service/pkg/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const auditTargetsKey = "auditTargets"
+
+// SetAuditTargets records the IDs of the patients a request read or wrote,
+// for the entry written by Audit. Without it the entry targets the :id route
+// parameter, if any.
+func SetAuditTargets(c *fiber.Ctx, ids ...string) {
+	c.Locals(auditTargetsKey, ids)
+}
+
+const auditEntryKey = "auditEntry"
+
+// SetAuditStatus sets the status recorded when the request's changes are
+// written, for handlers that answer a successful write with another status
+// than 200 OK. Call it before the service.
+func SetAuditStatus(c *fiber.Ctx, status int) {
+	if entry, ok := c.Locals(auditEntryKey).(*models.AuditEntry); ok {
+		entry.Status = status
+	}
+}
+
+// Audit records an audit log entry for action, whatever the outcome. Put it
+// before RequireRole so that refused requests are recorded too. Services
+// that change patient data append the entry in the transaction that makes
+// the change; otherwise it is appended once the rest of the chain has run.
+// If it cannot be written then the response is replaced with an error:
+// access to patient data must not go unrecorded.
+func Audit(auditService *service.AuditService, action string) fiber.Handler {
+	return audit(auditService, apperr.WriteError, action, false)
+}
+
+// AuditWith is Audit for APIs with their own error format, which writeError
+// produces when the entry cannot be written.
+func AuditWith(auditService *service.AuditService, writeError ErrorWriter, action string) fiber.Handler {
+	return audit(auditService, writeError, action, false)
+}
+
+// AuditSelf is Audit for routes where patients act on their own data: the
+// entry targets the caller unless the handler says otherwise.
+func AuditSelf(auditService *service.AuditService, action string) fiber.Handler {
+	return audit(auditService, apperr.WriteError, action, true)
+}
+
+func audit(auditService *service.AuditService, writeError ErrorWriter, action string, self bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if self {
+			if userID := stringLocal(c, "userID"); userID != "" {
+				SetAuditTargets(c, userID)
+			}
+		}
+		entry := &models.AuditEntry{
+			Action:    action,
+			TargetIDs: auditTargets(c),
+			RequestID: c.GetRespHeader(fiber.HeaderXRequestID),
+			IP:        c.IP(),
+			Status:    fiber.StatusOK,
+		}
+		if id, parseErr := uuid.Parse(stringLocal(c, "userID")); parseErr == nil {
+			entry.ActorID = &id
+		}
+		entry.ActorRole = stringLocal(c, "role")
+		c.Locals(auditEntryKey, entry)
+		ctx := service.WithPendingAudit(c.UserContext(), entry)
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+		if service.AuditRecorded(ctx) {
+			return err
+		}
+
+		status := c.Response().StatusCode()
+		var fe *fiber.Error
+		if errors.As(err, &fe) {
+			status = fe.Code
+		} else if err != nil {
+			status = apperr.CodeInternal.Status()
+			var ae *apperr.Error
+			if errors.As(err, &ae) {
+				status = ae.Code.Status()
+			}
+		}
+		entry.TargetIDs = auditTargets(c)
+		entry.Outcome = auditOutcome(status)
+		entry.Status = status
+
+		if auditErr := auditService.Record(ctx, entry); auditErr != nil {
+			slog.ErrorContext(ctx, "audit log write failed", "action", action, "error", auditErr)
+			c.Response().Header.Del(fiber.HeaderETag)
+			return writeError(c, apperr.New(apperr.CodeInternal, "audit log unavailable", auditErr))
+		}
+		return err
+	}
+}
+
+func auditTargets(c *fiber.Ctx) models.IDList {
+	if ids, ok := c.Locals(auditTargetsKey).([]string); ok {
+		return ids
+	}
+	if id := c.Params("id"); id != "" {
+		return models.IDList{id}
+	}
+	return models.IDList{}
+}
+
+func auditOutcome(status int) models.AuditOutcome {
+	switch {
+	case status == fiber.StatusUnauthorized || status == fiber.StatusForbidden:
+		return models.AuditDenied
+	case status >= fiber.StatusBadRequest:
+		return models.AuditFailure
+	}
+	return models.AuditSuccess
+}
+
+func stringLocal(c *fiber.Ctx, key string) string {
+	s, _ := c.Locals(key).(string)
+	return s
+}
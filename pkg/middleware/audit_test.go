@@ -0,0 +1,56 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"user-service/pkg/apperr"
+	"user-service/pkg/middleware"
+	"user-service/pkg/models"
+	"user-service/pkg/repository"
+	service "user-service/pkg/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// unavailableAuditLog fails every append.
+type unavailableAuditLog struct {
+	repository.AuditRepository
+}
+
+func (unavailableAuditLog) Append(ctx context.Context, entry *models.AuditEntry) error {
+	return errors.New("connection refused")
+}
+
+func TestAuditWriteFailure(t *testing.T) {
+	auditService := service.NewAuditService(unavailableAuditLog{})
+	outcome := func(c *fiber.Ctx, err error) error {
+		var ae *apperr.Error
+		if !errors.As(err, &ae) {
+			t.Errorf("error = %v, want an *apperr.Error", err)
+		}
+		return c.Status(ae.Code.Status()).SendString("outcome: " + ae.Msg)
+	}
+
+	app := fiber.New()
+	ok := func(c *fiber.Ctx) error { return c.SendString("patient data") }
+	app.Get("/envelope", middleware.Audit(auditService, "patient.read"), ok)
+	app.Get("/custom", middleware.AuditWith(auditService, outcome, "patient.read"), ok)
+
+	for path, want := range map[string]string{
+		"/envelope": `"code":"INTERNAL_SERVER_ERROR"`,
+		"/custom":   "outcome: audit log unavailable",
+	} {
+		res, err := app.Test(httptest.NewRequest(fiber.MethodGet, path, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(res.Body)
+		if res.StatusCode != fiber.StatusInternalServerError || !strings.Contains(string(body), want) || strings.Contains(string(body), "patient data") {
+			t.Errorf("%s = %d %s, want 500 with %s", path, res.StatusCode, body, want)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"log/slog"
+	"time"
+	"user-service/pkg/logging"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequestLogger puts the request ID in the user context, where
+// contextUtils.GetContext and the logger pick it up, and logs one line per
+// request once the error handler has written the response. Only the path is
+// logged: query strings can hold search terms such as names.
+func RequestLogger() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		ctx := logging.WithRequestID(c.UserContext(), c.GetRespHeader(fiber.HeaderXRequestID))
+		c.SetUserContext(ctx)
+
+		handleError(c, c.Next())
+
+		status := c.Response().StatusCode()
+		level := slog.LevelInfo
+		if status >= fiber.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		slog.Log(ctx, level, "request",
+			"method", c.Method(),
+			"path", c.Path(),
+			"status", status,
+			"elapsed", time.Since(start),
+			"ip", c.IP(),
+		)
+		return nil
+	}
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"strings"
+	"time"
+	"user-service/pkg/metrics"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Metrics records the count and latency of requests by route pattern.
+// Requests that match no route are recorded under the pattern of the last
+// middleware they passed through.
+func Metrics() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		handleError(c, c.Next())
+		// c.Method() aliases fasthttp's buffer, which is reused after the
+		// request; the label value outlives it.
+		metrics.ObserveHTTPRequest(strings.Clone(c.Method()), c.Route().Path, c.Response().StatusCode(), time.Since(start))
+		return nil
+	}
+}
+
+// handleError writes the response for an error returned further down the
+// chain, so that middleware wrapping the chain sees the final status.
+func handleError(c *fiber.Ctx, err error) {
+	if err == nil {
+		return
+	}
+	if handlerErr := c.App().ErrorHandler(c, err); handlerErr != nil {
+		_ = c.SendStatus(fiber.StatusInternalServerError)
+	}
+}
@@ -1,26 +1,35 @@
 package middleware
 
 import (
+	"errors"
+	"user-service/pkg/apperr"
 	"user-service/pkg/jwt"
+	"user-service/pkg/response"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+// ErrorWriter writes the response of a request a middleware refused or could
+// not complete. err is an *apperr.Error.
+type ErrorWriter func(c *fiber.Ctx, err error) error
+
 func JwtMiddleware(jwtService *jwt.JwtService) fiber.Handler {
+	return JwtMiddlewareWith(jwtService, writeAuthError)
+}
+
+// JwtMiddlewareWith is JwtMiddleware for APIs with their own error format,
+// such as FHIR's OperationOutcome.
+func JwtMiddlewareWith(jwtService *jwt.JwtService, writeError ErrorWriter) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 
 		token := c.Cookies("access_token")
 		if token == "" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Missing or malformed JWT",
-			})
+			return writeError(c, apperr.New(apperr.CodeUnauthorized, "Missing or malformed JWT", nil))
 		}
 
 		claims, err := jwtService.Parse(token)
 		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Invalid token",
-			})
+			return writeError(c, apperr.New(apperr.CodeUnauthorized, "Invalid token", err))
 		}
 
 		c.Locals("userID", claims.UserID)
@@ -29,3 +38,30 @@ func JwtMiddleware(jwtService *jwt.JwtService) fiber.Handler {
 		return c.Next()
 	}
 }
+
+func RequireRole(roles ...string) fiber.Handler {
+	return RequireRoleWith(writeAuthError, roles...)
+}
+
+// RequireRoleWith is RequireRole for APIs with their own error format.
+func RequireRoleWith(writeError ErrorWriter, roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role, _ := c.Locals("role").(string)
+		for _, r := range roles {
+			if role == r {
+				return c.Next()
+			}
+		}
+		return writeError(c, apperr.New(apperr.CodeForbidden, "Insufficient permissions", nil))
+	}
+}
+
+// writeAuthError writes the response envelope with a code derived from the
+// status, as these middlewares always have.
+func writeAuthError(c *fiber.Ctx, err error) error {
+	var ae *apperr.Error
+	if !errors.As(err, &ae) {
+		return apperr.WriteError(c, err)
+	}
+	return response.Failed(c, ae.Code.Status(), ae.Msg)
+}
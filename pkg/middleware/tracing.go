@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"strings"
+	"user-service/pkg/tracing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing starts a server span for each request, continuing the trace in the
+// W3C traceparent header if there is one, and puts it in the user context
+// for the handlers, services and outbound calls below.
+func Tracing() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), headerCarrier{&c.Request().Header})
+		// Spans are exported after the request, when fasthttp has reused the
+		// buffers that c.Method() and c.Path() alias.
+		method := strings.Clone(c.Method())
+		ctx, span := tracing.Start(ctx, method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(method),
+				semconv.URLPath(strings.Clone(c.Path())),
+			),
+		)
+		defer span.End()
+		c.SetUserContext(ctx)
+
+		handleError(c, c.Next())
+
+		// The route is only known once the router has matched it.
+		route := c.Route().Path
+		status := c.Response().StatusCode()
+		span.SetName(method + " " + route)
+		span.SetAttributes(semconv.HTTPRoute(route), semconv.HTTPResponseStatusCode(status))
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, "")
+		}
+		return nil
+	}
+}
+
+// headerCarrier adapts request headers for the propagator.
+type headerCarrier struct {
+	header *fasthttp.RequestHeader
+}
+
+func (h headerCarrier) Get(key string) string {
+	return string(h.header.Peek(key))
+}
+
+func (h headerCarrier) Set(key, value string) {
+	h.header.Set(key, value)
+}
+
+func (h headerCarrier) Keys() []string {
+	var keys []string
+	h.header.VisitAll(func(key, _ []byte) {
+		keys = append(keys, string(key))
+	})
+	return keys
+}
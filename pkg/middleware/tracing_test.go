@@ -0,0 +1,83 @@
+package middleware_test
+
+import (
+	"net/http/httptest"
+	"testing"
+	"user-service/pkg/db"
+	"user-service/pkg/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+const (
+	traceID      = "4bf92f3577b34da6a3ce929d0e0e4736"
+	parentSpanID = "00f067aa0ba902b7"
+)
+
+func TestTracingContinuesTraceIntoQueries(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	// A dry run builds each statement and runs the callbacks around it
+	// without a database.
+	gdb, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := gdb.Use(db.TracingPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	app := fiber.New()
+	app.Use(middleware.Tracing())
+	app.Get("/users/:id", func(c *fiber.Ctx) error {
+		var user struct {
+			ID        string
+			DeletedAt gorm.DeletedAt
+		}
+		gdb.WithContext(c.UserContext()).Where("id = ?", c.Params("id")).Table("users").Take(&user)
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	req := httptest.NewRequest(fiber.MethodGet, "/users/0199f0a4-0000-7000-8000-000000000001", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-"+parentSpanID+"-01")
+	res, err := app.Test(req)
+	if err != nil || res.StatusCode != fiber.StatusOK {
+		t.Fatalf("request = %v (%v), want 200", res, err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("ended %d spans, want the request and one query", len(spans))
+	}
+	query, server := spans[0], spans[1]
+	if server.Name() != "GET /users/:id" || server.SpanKind() != trace.SpanKindServer {
+		t.Errorf("server span = %s (%v), want GET /users/:id", server.Name(), server.SpanKind())
+	}
+	if server.SpanContext().TraceID().String() != traceID || server.Parent().SpanID().String() != parentSpanID || !server.Parent().IsRemote() {
+		t.Errorf("server span trace %s, parent %s, want the traceparent's %s and %s",
+			server.SpanContext().TraceID(), server.Parent().SpanID(), traceID, parentSpanID)
+	}
+	if query.Name() != "gorm.query" || query.SpanKind() != trace.SpanKindClient ||
+		query.Parent().SpanID() != server.SpanContext().SpanID() || query.SpanContext().TraceID().String() != traceID {
+		t.Errorf("query span = %s, parent %s, want gorm.query under the server span", query.Name(), query.Parent().SpanID())
+	}
+	queryText := ""
+	for _, attr := range query.Attributes() {
+		if attr.Key == semconv.DBQueryTextKey {
+			queryText = attr.Value.AsString()
+		}
+	}
+	if queryText != `SELECT * FROM "users" WHERE id = $1 AND "users"."deleted_at" IS NULL LIMIT $2` {
+		t.Errorf("query text = %q, want the statement with its placeholder", queryText)
+	}
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DeletionRequestStatus string
+
+const (
+	DeletionRequestPending  DeletionRequestStatus = "pending"
+	DeletionRequestApproved DeletionRequestStatus = "approved"
+	DeletionRequestRejected DeletionRequestStatus = "rejected"
+)
+
+type AccountDeletionRequest struct {
+	ID           uuid.UUID             `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID       uuid.UUID             `json:"user_id" gorm:"type:uuid;not null"`
+	Status       DeletionRequestStatus `json:"status" gorm:"type:deletion_request_status;not null;default:pending"`
+	Reason       *string               `json:"reason,omitempty"`
+	ReviewedBy   *uuid.UUID            `json:"reviewed_by,omitempty" gorm:"type:uuid"`
+	ReviewNote   *string               `json:"review_note,omitempty"`
+	RequestedAt  time.Time             `json:"requested_at" gorm:"default:now()"`
+	ReviewedAt   *time.Time            `json:"reviewed_at,omitempty"`
+	AnonymizedAt *time.Time            `json:"anonymized_at,omitempty"`
+	CreatedAt    time.Time             `json:"created_at" gorm:"default:now()"`
+	UpdatedAt    time.Time             `json:"updated_at" gorm:"default:now()"`
+
+	User User `json:"user" gorm:"foreignKey:UserID;references:ID"`
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AuditOutcome string
+
+const (
+	AuditSuccess AuditOutcome = "success"
+	AuditDenied  AuditOutcome = "denied"
+	AuditFailure AuditOutcome = "failure"
+)
+
+// AuditEntry records one request that read or wrote patient data. Entries
+// are chained: Hash covers the entry's content and PrevHash, the Hash of the
+// entry before it (empty for the first entry).
+type AuditEntry struct {
+	ID         int64        `json:"id" gorm:"primaryKey"`
+	OccurredAt time.Time    `json:"occurred_at" gorm:"not null"`
+	ActorID    *uuid.UUID   `json:"actor_id" gorm:"type:uuid"`
+	ActorRole  string       `json:"actor_role"`
+	Action     string       `json:"action" gorm:"not null"`
+	TargetIDs  IDList       `json:"target_ids" gorm:"type:jsonb;not null"`
+	RequestID  string       `json:"request_id"`
+	IP         string       `json:"ip"`
+	Outcome    AuditOutcome `json:"outcome" gorm:"not null"`
+	Status     int          `json:"status" gorm:"not null"`
+	PrevHash   string       `json:"prev_hash" gorm:"not null"`
+	Hash       string       `json:"hash" gorm:"not null"`
+}
+
+func (AuditEntry) TableName() string { return "audit_log" }
+
+// ComputeHash returns the hex SHA-256 of the entry's content and PrevHash.
+// OccurredAt is hashed in UTC at the microsecond precision Postgres keeps, so
+// the hash of a stored entry can be recomputed after reading it back.
+func (e *AuditEntry) ComputeHash() string {
+	actorID := ""
+	if e.ActorID != nil {
+		actorID = e.ActorID.String()
+	}
+	targetIDs := []string{}
+	targetIDs = append(targetIDs, e.TargetIDs...)
+	content, _ := json.Marshal([]any{
+		e.PrevHash,
+		e.OccurredAt.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano),
+		actorID,
+		e.ActorRole,
+		e.Action,
+		targetIDs,
+		e.RequestID,
+		e.IP,
+		e.Outcome,
+		e.Status,
+	})
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// IDList is a list of IDs stored as a JSON array.
+type IDList []string
+
+func (l IDList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal([]string(l))
+	return string(b), err
+}
+
+func (l *IDList) Scan(src any) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, l)
+	case string:
+		return json.Unmarshal([]byte(v), l)
+	case nil:
+		*l = nil
+		return nil
+	}
+	return fmt.Errorf("IDList: cannot scan %T", src)
+}
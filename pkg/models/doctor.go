@@ -13,6 +13,7 @@ type Doctor struct {
 	Specialty       *string        `json:"specialty,omitempty"`
 	Bio             *string        `json:"bio,omitempty"`
 	YearsExperience *int           `json:"years_experience,omitempty" gorm:"check:years_experience IS NULL OR years_experience >= 0"`
+	Version         int64          `json:"-" gorm:"not null;default:1"`
 	CreatedAt       time.Time      `json:"created_at" gorm:"default:now()"`
 	UpdatedAt       time.Time      `json:"updated_at" gorm:"default:now()"`
 	DeletedAt       gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
@@ -2,6 +2,7 @@ package models
 
 import (
 	"time"
+	"user-service/pkg/encryption"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -11,11 +12,13 @@ type Patient struct {
 	UserID           uuid.UUID      `json:"user_id" gorm:"primaryKey;type:uuid"`
 	HospitalID       string         `json:"hospital_id" gorm:"unique,not null"`
 	BirthDate        *time.Time     `json:"birth_date,omitempty"`
-	IDCardNumber     *string        `json:"id_card_number,omitempty" gorm:"size:13"`
-	Address          *string        `json:"address,omitempty"`
-	Allergies        *string        `json:"allergies,omitempty"`
-	EmergencyContact *string        `json:"emergency_contact,omitempty"`
+	IDCardNumber     *string        `json:"id_card_number,omitempty" gorm:"serializer:encrypted"`
+	IDCardBlindIndex *string        `json:"-" gorm:"column:id_card_blind_index"`
+	Address          *string        `json:"address,omitempty" gorm:"serializer:encrypted"`
+	Allergies        *string        `json:"allergies,omitempty" gorm:"serializer:encrypted"`
+	EmergencyContact *string        `json:"emergency_contact,omitempty" gorm:"serializer:encrypted"`
 	BloodType        *string        `json:"blood_type,omitempty" gorm:"size:5"`
+	Version          int64          `json:"-" gorm:"not null;default:1"`
 	CreatedAt        time.Time      `json:"created_at" gorm:"default:now()"`
 	UpdatedAt        time.Time      `json:"updated_at" gorm:"default:now()"`
 	DeletedAt        gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
@@ -23,3 +26,8 @@ type Patient struct {
 	User                   User                    `json:"user" gorm:"foreignKey:UserID;references:ID"`
 	HealthcareEntitlements []HealthcareEntitlement `json:"healthcare_entitlements,omitempty" gorm:"many2many:user_healthcare_entitlement;foreignKey:UserID;joinForeignKey:PatientID;References:Name;joinReferences:HealthcareEntitlement"`
 }
+
+// AfterFind decrypts the encrypted fields read before the primary key.
+func (p *Patient) AfterFind(tx *gorm.DB) error {
+	return encryption.Open(tx.Statement.Context, p, tx.NamingStrategy)
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+	"user-service/pkg/encryption"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ProfileChange records one field of a user's profile, on the users or the
+// patients row, changing value: through a profile update, an erasure (one
+// entry per anonymized field, with neither value, on behalf of the admin who
+// approved the closure) or a restore (a deleted_at entry). Key rotation
+// re-encrypts values without changing them and is not recorded.
+type ProfileChange struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;not null"`
+	Field     string     `json:"field" gorm:"not null"`
+	OldValue  *string    `json:"old_value,omitempty" gorm:"serializer:encrypted"`
+	NewValue  *string    `json:"new_value,omitempty" gorm:"serializer:encrypted"`
+	ActorID   *uuid.UUID `json:"actor_id,omitempty" gorm:"type:uuid"`
+	ActorRole Role       `json:"actor_role" gorm:"type:roles;not null"`
+	ChangedAt time.Time  `json:"changed_at" gorm:"not null;default:now()"`
+}
+
+// AfterFind decrypts the encrypted fields read before the primary key.
+func (c *ProfileChange) AfterFind(tx *gorm.DB) error {
+	return encryption.Open(tx.Statement.Context, c, tx.NamingStrategy)
+}
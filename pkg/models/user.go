@@ -28,6 +28,7 @@ type User struct {
 	Gender      string         `json:"gender" gorm:"type:gender_enum;not null"`
 	PhoneNumber string         `json:"phone_number" gorm:"not null"`
 	Role        Role           `json:"role" gorm:"type:roles;not null"`
+	Version     int64          `json:"-" gorm:"not null;default:1"`
 	CreatedAt   time.Time      `json:"created_at" gorm:"default:now()"`
 	UpdatedAt   time.Time      `json:"updated_at" gorm:"default:now()"`
 	DeletedAt   gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
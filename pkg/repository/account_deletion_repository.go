@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"user-service/pkg/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AccountDeletionRepository persists account deletion requests.
+type AccountDeletionRepository interface {
+	Create(ctx context.Context, request *models.AccountDeletionRequest) error
+	Update(ctx context.Context, request *models.AccountDeletionRequest) error
+	// FindByID locks the request it returns until the transaction ends, so
+	// two admins cannot review the same request at once.
+	FindByID(ctx context.Context, id string) (*models.AccountDeletionRequest, error)
+	FindOpenByUserID(ctx context.Context, userID string) (*models.AccountDeletionRequest, error)
+	FindByUserID(ctx context.Context, userID string) ([]*models.AccountDeletionRequest, error)
+	FindAll(ctx context.Context, status string, offset, limit int) ([]*models.AccountDeletionRequest, int64, error)
+	FindDueForAnonymization(ctx context.Context, cutoff time.Time) ([]*models.AccountDeletionRequest, error)
+}
+
+type accountDeletionRepository struct {
+	db *gorm.DB
+}
+
+func NewAccountDeletionRepository(db *gorm.DB) AccountDeletionRepository {
+	return &accountDeletionRepository{
+		db: db,
+	}
+}
+
+func (r *accountDeletionRepository) Create(ctx context.Context, request *models.AccountDeletionRequest) error {
+	if err := r.db.WithContext(ctx).Create(request).Error; err != nil {
+		return translateError(err, "deletion request")
+	}
+	return nil
+}
+
+func (r *accountDeletionRepository) Update(ctx context.Context, request *models.AccountDeletionRequest) error {
+	if err := r.db.WithContext(ctx).Save(request).Error; err != nil {
+		return translateError(err, "deletion request")
+	}
+	return nil
+}
+
+func (r *accountDeletionRepository) FindByID(ctx context.Context, id string) (*models.AccountDeletionRequest, error) {
+	var request models.AccountDeletionRequest
+	if err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", id).
+		First(&request).Error; err != nil {
+		return nil, translateError(err, "deletion request")
+	}
+	return &request, nil
+}
+
+// FindOpenByUserID returns the user's pending or approved request, if any.
+func (r *accountDeletionRepository) FindOpenByUserID(ctx context.Context, userID string) (*models.AccountDeletionRequest, error) {
+	var request models.AccountDeletionRequest
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Where("status IN ?", []models.DeletionRequestStatus{models.DeletionRequestPending, models.DeletionRequestApproved}).
+		First(&request).Error; err != nil {
+		return nil, translateError(err, "deletion request")
+	}
+	return &request, nil
+}
+
+// FindByUserID returns every request of a user, oldest first.
+func (r *accountDeletionRepository) FindByUserID(ctx context.Context, userID string) ([]*models.AccountDeletionRequest, error) {
+	var requests []*models.AccountDeletionRequest
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("requested_at").Find(&requests).Error; err != nil {
+		return nil, translateError(err, "deletion request")
+	}
+	return requests, nil
+}
+
+// FindAll lists a page of requests, newest first, optionally filtered by
+// status, together with the total count.
+func (r *accountDeletionRepository) FindAll(ctx context.Context, status string, offset, limit int) ([]*models.AccountDeletionRequest, int64, error) {
+	var requests []*models.AccountDeletionRequest
+	var total int64
+	query := r.db.WithContext(ctx).Model(&models.AccountDeletionRequest{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, translateError(err, "deletion request")
+	}
+	if err := query.Order("requested_at DESC").Offset(offset).Limit(limit).Find(&requests).Error; err != nil {
+		return nil, 0, translateError(err, "deletion request")
+	}
+	return requests, total, nil
+}
+
+// FindDueForAnonymization returns approved requests reviewed before the
+// cutoff whose accounts have not been anonymized yet.
+func (r *accountDeletionRepository) FindDueForAnonymization(ctx context.Context, cutoff time.Time) ([]*models.AccountDeletionRequest, error) {
+	var requests []*models.AccountDeletionRequest
+	if err := r.db.WithContext(ctx).
+		Where("status = ?", models.DeletionRequestApproved).
+		Where("anonymized_at IS NULL").
+		Where("reviewed_at < ?", cutoff).
+		Order("reviewed_at").
+		Find(&requests).Error; err != nil {
+		return nil, translateError(err, "deletion request")
+	}
+	return requests, nil
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"user-service/pkg/models"
+
+	"gorm.io/gorm"
+)
+
+// AdminRepository looks up admin accounts.
+type AdminRepository interface {
+	FindByUsername(ctx context.Context, username string) (*models.Admin, error)
+	Create(ctx context.Context, admin *models.Admin) error
+}
+
+type adminRepository struct {
+	db *gorm.DB
+}
+
+func NewAdminRepository(db *gorm.DB) AdminRepository {
+	return &adminRepository{
+		db: db,
+	}
+}
+
+func (r *adminRepository) FindByUsername(ctx context.Context, username string) (*models.Admin, error) {
+	var admin models.Admin
+	if err := r.db.WithContext(ctx).Where("username = ?", username).First(&admin).Error; err != nil {
+		return nil, translateError(err, "admin")
+	}
+	return &admin, nil
+}
+
+func (r *adminRepository) Create(ctx context.Context, admin *models.Admin) error {
+	if err := r.db.WithContext(ctx).Create(admin).Error; err != nil {
+		return translateError(err, "admin")
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+	"user-service/pkg/models"
+
+	"gorm.io/gorm"
+)
+
+// auditLockKey is the transaction-level advisory lock that serializes
+// appends, so that each entry is chained to the one committed before it.
+const auditLockKey = 7_420_139_001
+
+// AuditFilter narrows audit log queries; zero fields match everything.
+type AuditFilter struct {
+	ActorID   string
+	PatientID string
+	Action    string
+	From      *time.Time
+	To        *time.Time
+}
+
+// AuditRepository persists the append-only, hash-chained audit log.
+type AuditRepository interface {
+	// Append chains entry to the last entry, setting PrevHash and Hash, and
+	// stores it.
+	Append(ctx context.Context, entry *models.AuditEntry) error
+	// Find lists a page of matching entries, newest first, with the total.
+	Find(ctx context.Context, filter AuditFilter, offset, limit int) ([]*models.AuditEntry, int64, error)
+	// FindAfter lists up to limit matching entries with an ID above afterID,
+	// oldest first, for exports and chain verification.
+	FindAfter(ctx context.Context, filter AuditFilter, afterID int64, limit int) ([]*models.AuditEntry, error)
+}
+
+type auditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &auditRepository{
+		db: db,
+	}
+}
+
+func (r *auditRepository) Append(ctx context.Context, entry *models.AuditEntry) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", auditLockKey).Error; err != nil {
+			return err
+		}
+		var last []string
+		if err := tx.Model(&models.AuditEntry{}).Order("id DESC").Limit(1).Pluck("hash", &last).Error; err != nil {
+			return err
+		}
+		entry.PrevHash = ""
+		if len(last) > 0 {
+			entry.PrevHash = last[0]
+		}
+		entry.Hash = entry.ComputeHash()
+		return tx.Create(entry).Error
+	})
+	if err != nil {
+		return translateError(err, "audit entry")
+	}
+	return nil
+}
+
+func (r *auditRepository) Find(ctx context.Context, filter AuditFilter, offset, limit int) ([]*models.AuditEntry, int64, error) {
+	var entries []*models.AuditEntry
+	var total int64
+	query := r.filter(ctx, filter)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, translateError(err, "audit entry")
+	}
+	if err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&entries).Error; err != nil {
+		return nil, 0, translateError(err, "audit entry")
+	}
+	return entries, total, nil
+}
+
+func (r *auditRepository) FindAfter(ctx context.Context, filter AuditFilter, afterID int64, limit int) ([]*models.AuditEntry, error) {
+	var entries []*models.AuditEntry
+	if err := r.filter(ctx, filter).Where("id > ?", afterID).Order("id").Limit(limit).Find(&entries).Error; err != nil {
+		return nil, translateError(err, "audit entry")
+	}
+	return entries, nil
+}
+
+func (r *auditRepository) filter(ctx context.Context, filter AuditFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&models.AuditEntry{})
+	if filter.ActorID != "" {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.PatientID != "" {
+		target, _ := json.Marshal([]string{filter.PatientID})
+		query = query.Where("target_ids @> ?::jsonb", string(target))
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.From != nil {
+		query = query.Where("occurred_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("occurred_at < ?", *filter.To)
+	}
+	return query
+}
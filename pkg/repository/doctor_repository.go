@@ -2,85 +2,168 @@ package repository
 
 import (
 	"context"
+	"time"
 	"user-service/pkg/models"
 
 	"gorm.io/gorm"
 )
 
-type DoctorRepository struct {
-	db *gorm.DB
+// DoctorRepository persists doctor profiles. Update writes only the named
+// columns and fails if the row's version moved on since it was read.
+type DoctorRepository interface {
+	FindByUserID(ctx context.Context, userID string) (*models.Doctor, error)
+	FindByUsername(ctx context.Context, username string) (*models.Doctor, error)
+	Create(ctx context.Context, doctor *models.Doctor) error
+	Update(ctx context.Context, doctor *models.Doctor, columns ...string) error
+	Restore(ctx context.Context, userID string) error
+	PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, error)
+	FindManyByIDs(ctx context.Context, doctorIDs []string) ([]*models.Doctor, error)
+	FindAll(ctx context.Context) ([]*models.Doctor, error)
+	Search(ctx context.Context, query string, limit int) ([]*models.Doctor, error)
+	// FindPage lists a page of active doctors matching filter, with their
+	// users, ordered by ID, and the total number of matches.
+	FindPage(ctx context.Context, filter DoctorFilter, offset, limit int) ([]*models.Doctor, int64, error)
 }
 
-func NewDoctorRepository(db *gorm.DB) *DoctorRepository {
-	return &DoctorRepository{
-		db: db,
-	}
+// DoctorFilter narrows FindPage; zero fields match everything, and set
+// fields must all match.
+type DoctorFilter struct {
+	UserIDs  []string
+	Username string
+	// Name matches first or last names that start with it, ignoring case.
+	Name string
 }
 
-func (r *DoctorRepository) Transaction(ctx context.Context, fn func(repo *DoctorRepository) (interface{}, error)) (interface{}, error) {
-	tx := r.db.Begin()
-	if tx.Error != nil {
-		return nil, tx.Error
-	}
-	repoWithTx := r.withTx(tx)
-
-	result, err := fn(repoWithTx)
-	if err != nil {
-		tx.Rollback()
-		return nil, err
-	}
-	if err := tx.Commit().Error; err != nil {
-		return nil, err
-	}
-	return result, nil
+type doctorRepository struct {
+	db *gorm.DB
 }
 
-func (r *DoctorRepository) withTx(tx *gorm.DB) *DoctorRepository {
-	return &DoctorRepository{db: tx}
+func NewDoctorRepository(db *gorm.DB) DoctorRepository {
+	return &doctorRepository{
+		db: db,
+	}
 }
 
-func (r *DoctorRepository) FindByUserID(ctx context.Context, userID string) (*models.Doctor, error) {
+func (r *doctorRepository) FindByUserID(ctx context.Context, userID string) (*models.Doctor, error) {
 	var doctor models.Doctor
 	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&doctor).Error; err != nil {
-		return nil, err
+		return nil, translateError(err, "doctor")
 	}
 	return &doctor, nil
 }
 
-func (r *DoctorRepository) FindByUsername(ctx context.Context, username string) (*models.Doctor, error) {
+func (r *doctorRepository) FindByUsername(ctx context.Context, username string) (*models.Doctor, error) {
 	var doctor models.Doctor
 	if err := r.db.WithContext(ctx).Where("username = ?", username).First(&doctor).Error; err != nil {
-		return nil, err
+		return nil, translateError(err, "doctor")
 	}
 	return &doctor, nil
 }
 
-func (r *DoctorRepository) Create(ctx context.Context, doctor *models.Doctor) error {
+func (r *doctorRepository) Create(ctx context.Context, doctor *models.Doctor) error {
 	if err := r.db.WithContext(ctx).Create(doctor).Error; err != nil {
-		return err
+		return translateError(err, "doctor")
 	}
 	return nil
 }
 
-func (r *DoctorRepository) Update(ctx context.Context, doctor *models.Doctor) error {
-	if err := r.db.WithContext(ctx).Save(doctor).Error; err != nil {
-		return err
+// Update writes the named columns of doctor if it is still at
+// doctor.Version, and fails with a precondition error otherwise.
+func (r *doctorRepository) Update(ctx context.Context, doctor *models.Doctor, columns ...string) error {
+	return updateVersioned(r.db.WithContext(ctx), doctor, &doctor.Version, &doctor.UpdatedAt, "doctor", columns)
+}
+
+func (r *doctorRepository) Restore(ctx context.Context, userID string) error {
+	if err := r.db.WithContext(ctx).Unscoped().Model(&models.Doctor{}).Where("user_id = ?", userID).Update("deleted_at", nil).Error; err != nil {
+		return translateError(err, "doctor")
 	}
 	return nil
 }
 
-func (r *DoctorRepository) FindManyByIDs(ctx context.Context, doctorIDs []string) ([]*models.Doctor, error) {
+// PurgeDeleted hard-deletes doctor rows soft-deleted before cutoff, except for
+// users with an account deletion request.
+func (r *doctorRepository) PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Unscoped().
+		Where("deleted_at < ?", cutoff).
+		Where("NOT EXISTS (SELECT 1 FROM account_deletion_requests r WHERE r.user_id = doctors.user_id)").
+		Delete(&models.Doctor{})
+	if result.Error != nil {
+		return 0, translateError(result.Error, "doctor")
+	}
+	return result.RowsAffected, nil
+}
+
+func (r *doctorRepository) FindManyByIDs(ctx context.Context, doctorIDs []string) ([]*models.Doctor, error) {
 	var doctors []*models.Doctor
 	if err := r.db.WithContext(ctx).Where("user_id IN ?", doctorIDs).Find(&doctors).Error; err != nil {
-		return nil, err
+		return nil, translateError(err, "doctor")
 	}
 	return doctors, nil
 }
 
-func (r *DoctorRepository) FindAll(ctx context.Context) ([]*models.Doctor, error) {
+func (r *doctorRepository) FindAll(ctx context.Context) ([]*models.Doctor, error) {
 	var doctors []*models.Doctor
 	if err := r.db.WithContext(ctx).Preload("User").Find(&doctors).Error; err != nil {
-		return nil, err
+		return nil, translateError(err, "doctor")
 	}
 	return doctors, nil
 }
+
+const doctorSearchCondition = `(users.first_name % @q OR users.last_name % @q
+	OR doctors.username % @q OR doctors.specialty % @q OR @q <% doctors.bio
+	OR users.first_name ILIKE @pattern OR users.last_name ILIKE @pattern
+	OR doctors.username ILIKE @pattern OR doctors.specialty ILIKE @pattern
+	OR ` + userNameTSVector + ` @@ ` + searchTSQuery + `
+	OR ` + doctorProfileTSVector + ` @@ ` + searchTSQuery + `)`
+
+const doctorSearchRank = `GREATEST(
+	similarity(users.first_name, @q),
+	similarity(users.last_name, @q),
+	similarity(doctors.username, @q),
+	similarity(coalesce(doctors.specialty, ''), @q),
+	word_similarity(@q, coalesce(doctors.bio, ''))
+) + ts_rank(` + userNameTSVector + `, ` + searchTSQuery + `)
+  + ts_rank(` + doctorProfileTSVector + `, ` + searchTSQuery + `)`
+
+// Search returns doctors matching query by name, username, specialty or bio,
+// ordered by trigram similarity and full-text rank.
+func (r *doctorRepository) Search(ctx context.Context, query string, limit int) ([]*models.Doctor, error) {
+	args := searchArgs(query)
+	var doctors []*models.Doctor
+	if err := r.db.WithContext(ctx).
+		Preload("User").
+		Joins("JOIN users ON users.id = doctors.user_id AND users.deleted_at IS NULL").
+		Where(doctorSearchCondition, args).
+		Order(orderByRank(doctorSearchRank, args)).
+		Limit(limit).
+		Find(&doctors).Error; err != nil {
+		return nil, translateError(err, "doctor")
+	}
+	return doctors, nil
+}
+
+func (r *doctorRepository) FindPage(ctx context.Context, filter DoctorFilter, offset, limit int) ([]*models.Doctor, int64, error) {
+	query := r.db.WithContext(ctx).
+		Model(&models.Doctor{}).
+		Joins("JOIN users ON users.id = doctors.user_id AND users.deleted_at IS NULL")
+	if len(filter.UserIDs) > 0 {
+		query = query.Where("doctors.user_id IN ?", filter.UserIDs)
+	}
+	if filter.Username != "" {
+		query = query.Where("doctors.username = ?", filter.Username)
+	}
+	if filter.Name != "" {
+		pattern := likeEscaper.Replace(filter.Name) + "%"
+		query = query.Where("(users.first_name ILIKE ? OR users.last_name ILIKE ?)", pattern, pattern)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, translateError(err, "doctor")
+	}
+	var doctors []*models.Doctor
+	if err := query.Preload("User").Order("doctors.user_id").Offset(offset).Limit(limit).Find(&doctors).Error; err != nil {
+		return nil, 0, translateError(err, "doctor")
+	}
+	return doctors, total, nil
+}
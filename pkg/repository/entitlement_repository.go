@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"user-service/pkg/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EntitlementRepository reads the healthcare entitlement master data and the
+// entitlements held by each patient.
+type EntitlementRepository interface {
+	FindAll(ctx context.Context) ([]*models.HealthcareEntitlement, error)
+	FindByPatientID(ctx context.Context, patientID string) ([]*models.HealthcareEntitlement, error)
+	AssignToPatient(ctx context.Context, patientID string, names []string) error
+	EnsureExists(ctx context.Context, names []string) (int64, error)
+}
+
+type entitlementRepository struct {
+	db *gorm.DB
+}
+
+func NewEntitlementRepository(db *gorm.DB) EntitlementRepository {
+	return &entitlementRepository{
+		db: db,
+	}
+}
+
+func (r *entitlementRepository) FindAll(ctx context.Context) ([]*models.HealthcareEntitlement, error) {
+	var entitlements []*models.HealthcareEntitlement
+	if err := r.db.WithContext(ctx).Order("healthcare_entitlement").Find(&entitlements).Error; err != nil {
+		return nil, translateError(err, "healthcare entitlement")
+	}
+	return entitlements, nil
+}
+
+func (r *entitlementRepository) FindByPatientID(ctx context.Context, patientID string) ([]*models.HealthcareEntitlement, error) {
+	var entitlements []*models.HealthcareEntitlement
+	if err := r.db.WithContext(ctx).
+		Joins("JOIN user_healthcare_entitlement ON user_healthcare_entitlement.healthcare_entitlement = healthcare_entitlements.healthcare_entitlement").
+		Where("user_healthcare_entitlement.patient_id = ?", patientID).
+		Order("healthcare_entitlements.healthcare_entitlement").
+		Find(&entitlements).Error; err != nil {
+		return nil, translateError(err, "healthcare entitlement")
+	}
+	return entitlements, nil
+}
+
+// AssignToPatient grants the named entitlements to a patient. Entitlements the
+// patient already holds are left as they are.
+func (r *entitlementRepository) AssignToPatient(ctx context.Context, patientID string, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	rows := make([]map[string]interface{}, 0, len(names))
+	for _, name := range names {
+		rows = append(rows, map[string]interface{}{
+			"patient_id":             patientID,
+			"healthcare_entitlement": name,
+		})
+	}
+	if err := r.db.WithContext(ctx).
+		Table("user_healthcare_entitlement").
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(rows).Error; err != nil {
+		return translateError(err, "healthcare entitlement")
+	}
+	return nil
+}
+
+// EnsureExists adds the named entitlements to the catalog and reports how many
+// were new. Entitlements already in the catalog are left as they are.
+func (r *entitlementRepository) EnsureExists(ctx context.Context, names []string) (int64, error) {
+	if len(names) == 0 {
+		return 0, nil
+	}
+	rows := make([]*models.HealthcareEntitlement, 0, len(names))
+	for _, name := range names {
+		rows = append(rows, &models.HealthcareEntitlement{Name: name})
+	}
+	res := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(rows)
+	if res.Error != nil {
+		return 0, translateError(res.Error, "healthcare entitlement")
+	}
+	return res.RowsAffected, nil
+}
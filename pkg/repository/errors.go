@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"user-service/pkg/apperr"
+	"user-service/pkg/validation"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// Postgres SQLSTATE codes translated into domain errors.
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+	pgCheckViolation      = "23514"
+	pgInvalidTextRepr     = "22P02"
+)
+
+// constraintFields names the request field behind constraints whose column
+// is not what clients send (e.g. the ID card blind index).
+var constraintFields = map[string]string{
+	"idx_patients_id_card_blind_index":   "id_card_number",
+	"idx_account_deletion_requests_open": "account_deletion_request",
+	"doctors_years_experience_check":     "years_experience",
+}
+
+// translateError maps GORM and Postgres errors to apperr errors so services
+// can tell "not found" and constraint violations apart from real failures.
+// entity names the model in messages, e.g. "patient not found". Errors that
+// are not recognised are returned unchanged.
+func translateError(err error, entity string) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return notFound(entity, err)
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+	field := offendingField(pgErr)
+	switch pgErr.Code {
+	case pgUniqueViolation:
+		return duplicate(field, err)
+	case pgForeignKeyViolation:
+		return missingReference(field, err)
+	case pgCheckViolation:
+		return apperr.New(apperr.CodeBadRequest, field+" is invalid", err).
+			WithField(field, validation.NewFieldError(field, "invalid", "", reflect.String))
+	case pgInvalidTextRepr:
+		return apperr.New(apperr.CodeBadRequest, "invalid "+entity+" identifier", err)
+	}
+	return err
+}
+
+// NotFoundError is the error repositories return when no entity matches.
+func NotFoundError(entity string) error {
+	return notFound(entity, nil)
+}
+
+// DuplicateError is the error repositories return when field would no longer
+// be unique.
+func DuplicateError(field string) error {
+	return duplicate(field, nil)
+}
+
+// MissingReferenceError is the error repositories return when field refers to
+// a row that does not exist.
+func MissingReferenceError(field string) error {
+	return missingReference(field, nil)
+}
+
+// StaleError is the error repositories return when a versioned update finds
+// the entity changed since it was read.
+func StaleError(entity string) error {
+	return apperr.New(apperr.CodePreconditionFailed, entity+" was modified by another request", nil)
+}
+
+func notFound(entity string, err error) error {
+	return apperr.New(apperr.CodeNotFound, entity+" not found", err)
+}
+
+func duplicate(field string, err error) error {
+	return apperr.New(apperr.CodeConflict, field+" already exists", err).
+		WithField(field, validation.NewFieldError(field, "unique", "", reflect.String))
+}
+
+func missingReference(field string, err error) error {
+	return apperr.New(apperr.CodeBadRequest, field+" references a record that does not exist", err).
+		WithField(field, validation.NewFieldError(field, "exists", "", reflect.String))
+}
+
+// offendingField works out which field a constraint violation is about: an
+// explicit mapping first, then the column Postgres reports, then the
+// "Key (column)=(value)" detail of unique and foreign key violations.
+func offendingField(pgErr *pgconn.PgError) string {
+	if field, ok := constraintFields[pgErr.ConstraintName]; ok {
+		return field
+	}
+	if pgErr.ColumnName != "" {
+		return pgErr.ColumnName
+	}
+	if _, rest, ok := strings.Cut(pgErr.Detail, "Key ("); ok {
+		if column, _, ok := strings.Cut(rest, ")="); ok && !strings.Contains(column, ",") {
+			return column
+		}
+	}
+	if pgErr.ConstraintName != "" {
+		return pgErr.ConstraintName
+	}
+	return "value"
+}
@@ -0,0 +1,129 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+	"user-service/pkg/models"
+	"user-service/pkg/repository"
+)
+
+type accountDeletionRepository struct {
+	s *Store
+}
+
+func (r *accountDeletionRepository) Create(ctx context.Context, request *models.AccountDeletionRequest) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.deletionRequests[request.ID.String()]; ok {
+		return repository.DuplicateError("id")
+	}
+	if err := r.s.checkOneOpenRequest(request); err != nil {
+		return err
+	}
+	now := time.Now()
+	if request.RequestedAt.IsZero() {
+		request.RequestedAt = now
+	}
+	request.CreatedAt = now
+	request.UpdatedAt = now
+	r.s.putDeletionRequest(request)
+	return nil
+}
+
+func (r *accountDeletionRepository) Update(ctx context.Context, request *models.AccountDeletionRequest) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if err := r.s.checkOneOpenRequest(request); err != nil {
+		return err
+	}
+	request.UpdatedAt = time.Now()
+	r.s.putDeletionRequest(request)
+	return nil
+}
+
+func (r *accountDeletionRepository) FindByID(ctx context.Context, id string) (*models.AccountDeletionRequest, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	request, ok := r.s.deletionRequests[id]
+	if !ok {
+		return nil, repository.NotFoundError("deletion request")
+	}
+	return &request, nil
+}
+
+func (r *accountDeletionRepository) FindOpenByUserID(ctx context.Context, userID string) (*models.AccountDeletionRequest, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	for _, request := range r.s.deletionRequests {
+		if request.UserID.String() == userID && isOpen(request.Status) {
+			return &request, nil
+		}
+	}
+	return nil, repository.NotFoundError("deletion request")
+}
+
+func (r *accountDeletionRepository) FindByUserID(ctx context.Context, userID string) ([]*models.AccountDeletionRequest, error) {
+	requests := r.findAll(func(request *models.AccountDeletionRequest) bool {
+		return request.UserID.String() == userID
+	})
+	sort.Slice(requests, func(i, j int) bool { return requests[i].RequestedAt.Before(requests[j].RequestedAt) })
+	return requests, nil
+}
+
+func (r *accountDeletionRepository) FindAll(ctx context.Context, status string, offset, limit int) ([]*models.AccountDeletionRequest, int64, error) {
+	requests := r.findAll(func(request *models.AccountDeletionRequest) bool {
+		return status == "" || string(request.Status) == status
+	})
+	sort.Slice(requests, func(i, j int) bool { return requests[i].RequestedAt.After(requests[j].RequestedAt) })
+	return paginate(requests, offset, limit), int64(len(requests)), nil
+}
+
+func (r *accountDeletionRepository) FindDueForAnonymization(ctx context.Context, cutoff time.Time) ([]*models.AccountDeletionRequest, error) {
+	requests := r.findAll(func(request *models.AccountDeletionRequest) bool {
+		return request.Status == models.DeletionRequestApproved &&
+			request.AnonymizedAt == nil &&
+			request.ReviewedAt != nil && request.ReviewedAt.Before(cutoff)
+	})
+	sort.Slice(requests, func(i, j int) bool { return requests[i].ReviewedAt.Before(*requests[j].ReviewedAt) })
+	return requests, nil
+}
+
+func (r *accountDeletionRepository) findAll(match func(*models.AccountDeletionRequest) bool) []*models.AccountDeletionRequest {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	var requests []*models.AccountDeletionRequest
+	for _, request := range r.s.deletionRequests {
+		if match(&request) {
+			request := request
+			requests = append(requests, &request)
+		}
+	}
+	return requests
+}
+
+// checkOneOpenRequest mirrors idx_account_deletion_requests_open: a user has
+// at most one pending or approved request. The caller holds the lock.
+func (s *Store) checkOneOpenRequest(request *models.AccountDeletionRequest) error {
+	if !isOpen(request.Status) {
+		return nil
+	}
+	for id, other := range s.deletionRequests {
+		if id != request.ID.String() && other.UserID == request.UserID && isOpen(other.Status) {
+			return repository.DuplicateError("account_deletion_request")
+		}
+	}
+	return nil
+}
+
+// putDeletionRequest stores a copy of request without its user. The caller
+// holds the write lock.
+func (s *Store) putDeletionRequest(request *models.AccountDeletionRequest) {
+	row := *request
+	row.User = models.User{}
+	s.deletionRequests[row.ID.String()] = row
+}
+
+func isOpen(status models.DeletionRequestStatus) bool {
+	return status == models.DeletionRequestPending || status == models.DeletionRequestApproved
+}
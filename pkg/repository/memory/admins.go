@@ -0,0 +1,37 @@
+package memory
+
+import (
+	"context"
+	"user-service/pkg/models"
+	"user-service/pkg/repository"
+)
+
+type adminRepository struct {
+	s *Store
+}
+
+func (r *adminRepository) FindByUsername(ctx context.Context, username string) (*models.Admin, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	for _, admin := range r.s.admins {
+		if admin.Username == username {
+			return &admin, nil
+		}
+	}
+	return nil, repository.NotFoundError("admin")
+}
+
+func (r *adminRepository) Create(ctx context.Context, admin *models.Admin) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.admins[admin.UserID.String()]; ok {
+		return repository.DuplicateError("user_id")
+	}
+	for _, existing := range r.s.admins {
+		if existing.Username == admin.Username {
+			return repository.DuplicateError("username")
+		}
+	}
+	r.s.admins[admin.UserID.String()] = *admin
+	return nil
+}
@@ -0,0 +1,58 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"user-service/pkg/models"
+	"user-service/pkg/repository"
+)
+
+type auditRepository struct {
+	s *Store
+}
+
+func (r *auditRepository) Append(ctx context.Context, entry *models.AuditEntry) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	entry.ID = int64(len(r.s.auditLog)) + 1
+	entry.PrevHash = ""
+	if n := len(r.s.auditLog); n > 0 {
+		entry.PrevHash = r.s.auditLog[n-1].Hash
+	}
+	entry.Hash = entry.ComputeHash()
+	stored := *entry
+	stored.TargetIDs = slices.Clone(entry.TargetIDs)
+	r.s.auditLog = append(r.s.auditLog, stored)
+	return nil
+}
+
+func (r *auditRepository) Find(ctx context.Context, filter repository.AuditFilter, offset, limit int) ([]*models.AuditEntry, int64, error) {
+	entries := r.matching(filter, 0)
+	slices.Reverse(entries)
+	return paginate(entries, offset, limit), int64(len(entries)), nil
+}
+
+func (r *auditRepository) FindAfter(ctx context.Context, filter repository.AuditFilter, afterID int64, limit int) ([]*models.AuditEntry, error) {
+	return paginate(r.matching(filter, afterID), 0, limit), nil
+}
+
+// matching returns copies of the entries after afterID that match filter,
+// oldest first.
+func (r *auditRepository) matching(filter repository.AuditFilter, afterID int64) []*models.AuditEntry {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	entries := []*models.AuditEntry{}
+	for _, entry := range r.s.auditLog {
+		if entry.ID <= afterID ||
+			filter.ActorID != "" && (entry.ActorID == nil || entry.ActorID.String() != filter.ActorID) ||
+			filter.PatientID != "" && !slices.Contains(entry.TargetIDs, filter.PatientID) ||
+			filter.Action != "" && entry.Action != filter.Action ||
+			filter.From != nil && entry.OccurredAt.Before(*filter.From) ||
+			filter.To != nil && !entry.OccurredAt.Before(*filter.To) {
+			continue
+		}
+		entry.TargetIDs = slices.Clone(entry.TargetIDs)
+		entries = append(entries, &entry)
+	}
+	return entries
+}
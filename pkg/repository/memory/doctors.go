@@ -0,0 +1,187 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+	"user-service/pkg/models"
+	"user-service/pkg/repository"
+
+	"gorm.io/gorm"
+)
+
+type doctorRepository struct {
+	s *Store
+}
+
+func (r *doctorRepository) FindByUserID(ctx context.Context, userID string) (*models.Doctor, error) {
+	return r.findOne(func(d *models.Doctor) bool { return d.UserID.String() == userID })
+}
+
+func (r *doctorRepository) FindByUsername(ctx context.Context, username string) (*models.Doctor, error) {
+	return r.findOne(func(d *models.Doctor) bool { return d.Username == username })
+}
+
+func (r *doctorRepository) findOne(match func(*models.Doctor) bool) (*models.Doctor, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	for _, doctor := range r.s.doctors {
+		if !doctor.DeletedAt.Valid && match(&doctor) {
+			return &doctor, nil
+		}
+	}
+	return nil, repository.NotFoundError("doctor")
+}
+
+func (r *doctorRepository) Create(ctx context.Context, doctor *models.Doctor) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.doctors[doctor.UserID.String()]; ok {
+		return repository.DuplicateError("user_id")
+	}
+	if err := r.s.checkDoctorUnique(doctor); err != nil {
+		return err
+	}
+	now := time.Now()
+	if doctor.CreatedAt.IsZero() {
+		doctor.CreatedAt = now
+	}
+	if doctor.UpdatedAt.IsZero() {
+		doctor.UpdatedAt = now
+	}
+	if doctor.Version == 0 {
+		doctor.Version = 1
+	}
+	r.s.putDoctor(doctor)
+	return nil
+}
+
+// Update stores the whole of doctor; see userRepository.Update.
+func (r *doctorRepository) Update(ctx context.Context, doctor *models.Doctor, columns ...string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if len(columns) == 0 {
+		return nil
+	}
+	if stored, ok := r.s.doctors[doctor.UserID.String()]; !ok || stored.DeletedAt.Valid || stored.Version != doctor.Version {
+		return repository.StaleError("doctor")
+	}
+	if err := r.s.checkDoctorUnique(doctor); err != nil {
+		return err
+	}
+	doctor.Version++
+	doctor.UpdatedAt = time.Now()
+	r.s.putDoctor(doctor)
+	return nil
+}
+
+func (r *doctorRepository) Restore(ctx context.Context, userID string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if doctor, ok := r.s.doctors[userID]; ok {
+		doctor.DeletedAt = gorm.DeletedAt{}
+		r.s.doctors[userID] = doctor
+	}
+	return nil
+}
+
+func (r *doctorRepository) PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var count int64
+	for userID, doctor := range r.s.doctors {
+		if !doctor.DeletedAt.Valid || !doctor.DeletedAt.Time.Before(cutoff) || r.s.hasDeletionRequest(userID) {
+			continue
+		}
+		delete(r.s.doctors, userID)
+		count++
+	}
+	return count, nil
+}
+
+func (r *doctorRepository) FindManyByIDs(ctx context.Context, doctorIDs []string) ([]*models.Doctor, error) {
+	return r.findAll(func(d *models.Doctor, _ *models.User) bool {
+		return contains(doctorIDs, d.UserID.String())
+	}, false), nil
+}
+
+func (r *doctorRepository) FindAll(ctx context.Context) ([]*models.Doctor, error) {
+	return r.findAll(func(*models.Doctor, *models.User) bool { return true }, true), nil
+}
+
+// Search matches a case-insensitive substring of the name, username,
+// specialty or bio, standing in for the trigram and full-text search.
+func (r *doctorRepository) Search(ctx context.Context, query string, limit int) ([]*models.Doctor, error) {
+	doctors := r.findAll(func(d *models.Doctor, u *models.User) bool {
+		if u == nil || u.DeletedAt.Valid {
+			return false
+		}
+		return matches(query, u.FirstName, u.LastName, d.Username, deref(d.Specialty), deref(d.Bio))
+	}, true)
+	return paginate(doctors, 0, limit), nil
+}
+
+func (r *doctorRepository) FindPage(ctx context.Context, filter repository.DoctorFilter, offset, limit int) ([]*models.Doctor, int64, error) {
+	doctors := r.findAll(func(d *models.Doctor, u *models.User) bool {
+		if u == nil || u.DeletedAt.Valid {
+			return false
+		}
+		return (len(filter.UserIDs) == 0 || contains(filter.UserIDs, d.UserID.String())) &&
+			(filter.Username == "" || d.Username == filter.Username) &&
+			(filter.Name == "" || hasPrefix(filter.Name, u.FirstName, u.LastName))
+	}, true)
+	sort.Slice(doctors, func(i, j int) bool { return doctors[i].UserID.String() < doctors[j].UserID.String() })
+	return paginate(doctors, offset, limit), int64(len(doctors)), nil
+}
+
+// findAll returns active doctors accepted by match, ordered by username,
+// with their user attached if preloadUser is set.
+func (r *doctorRepository) findAll(match func(*models.Doctor, *models.User) bool, preloadUser bool) []*models.Doctor {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	var doctors []*models.Doctor
+	for userID, doctor := range r.s.doctors {
+		if doctor.DeletedAt.Valid {
+			continue
+		}
+		var user *models.User
+		if u, ok := r.s.users[userID]; ok {
+			user = &u
+		}
+		if !match(&doctor, user) {
+			continue
+		}
+		doctor := doctor
+		if preloadUser && user != nil {
+			doctor.User = *user
+		}
+		doctors = append(doctors, &doctor)
+	}
+	sort.Slice(doctors, func(i, j int) bool { return doctors[i].Username < doctors[j].Username })
+	return doctors
+}
+
+// checkDoctorUnique enforces the unique username. The caller holds the lock.
+func (s *Store) checkDoctorUnique(doctor *models.Doctor) error {
+	for userID, other := range s.doctors {
+		if userID != doctor.UserID.String() && other.Username == doctor.Username {
+			return repository.DuplicateError("username")
+		}
+	}
+	return nil
+}
+
+// putDoctor stores a copy of doctor without its user. The caller holds the
+// write lock.
+func (s *Store) putDoctor(doctor *models.Doctor) {
+	row := *doctor
+	row.User = models.User{}
+	s.doctors[row.UserID.String()] = row
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
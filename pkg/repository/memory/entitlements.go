@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"user-service/pkg/models"
+	"user-service/pkg/repository"
+)
+
+type entitlementRepository struct {
+	s *Store
+}
+
+func (r *entitlementRepository) FindAll(ctx context.Context) ([]*models.HealthcareEntitlement, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	return sortedEntitlements(r.s.entitlements), nil
+}
+
+func (r *entitlementRepository) FindByPatientID(ctx context.Context, patientID string) ([]*models.HealthcareEntitlement, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	return sortedEntitlements(r.s.patientEntitled[patientID]), nil
+}
+
+func (r *entitlementRepository) AssignToPatient(ctx context.Context, patientID string, names []string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.patients[patientID]; !ok {
+		return repository.MissingReferenceError("patient_id")
+	}
+	for _, name := range names {
+		if _, ok := r.s.entitlements[name]; !ok {
+			return repository.MissingReferenceError("healthcare_entitlement")
+		}
+	}
+	if r.s.patientEntitled[patientID] == nil {
+		r.s.patientEntitled[patientID] = map[string]struct{}{}
+	}
+	for _, name := range names {
+		r.s.patientEntitled[patientID][name] = struct{}{}
+	}
+	return nil
+}
+
+func (r *entitlementRepository) EnsureExists(ctx context.Context, names []string) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var added int64
+	for _, name := range names {
+		if _, ok := r.s.entitlements[name]; !ok {
+			r.s.entitlements[name] = struct{}{}
+			added++
+		}
+	}
+	return added, nil
+}
+
+func sortedEntitlements(names map[string]struct{}) []*models.HealthcareEntitlement {
+	entitlements := make([]*models.HealthcareEntitlement, 0, len(names))
+	for name := range names {
+		entitlements = append(entitlements, &models.HealthcareEntitlement{Name: name})
+	}
+	sort.Slice(entitlements, func(i, j int) bool { return entitlements[i].Name < entitlements[j].Name })
+	return entitlements
+}
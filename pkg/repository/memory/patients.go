@@ -0,0 +1,295 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+	"user-service/pkg/models"
+	"user-service/pkg/repository"
+
+	"gorm.io/gorm"
+)
+
+type patientRepository struct {
+	s *Store
+}
+
+func (r *patientRepository) FindByUserID(ctx context.Context, userID string) (*models.Patient, error) {
+	return r.findOne(func(p *models.Patient) bool { return p.UserID.String() == userID })
+}
+
+// FindByUserIDForUpdate is FindByUserID; the store has no row locks.
+func (r *patientRepository) FindByUserIDForUpdate(ctx context.Context, userID string) (*models.Patient, error) {
+	return r.FindByUserID(ctx, userID)
+}
+
+func (r *patientRepository) FindByHospitalID(ctx context.Context, hospitalID string) (*models.Patient, error) {
+	return r.findOne(func(p *models.Patient) bool { return p.HospitalID == hospitalID })
+}
+
+func (r *patientRepository) FindByIDCardNumber(ctx context.Context, idCardNumber string) (*models.Patient, error) {
+	return r.findOne(func(p *models.Patient) bool {
+		return p.IDCardNumber != nil && *p.IDCardNumber == idCardNumber
+	})
+}
+
+func (r *patientRepository) findOne(match func(*models.Patient) bool) (*models.Patient, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	for _, patient := range r.s.patients {
+		if !patient.DeletedAt.Valid && match(&patient) {
+			return &patient, nil
+		}
+	}
+	return nil, repository.NotFoundError("patient")
+}
+
+func (r *patientRepository) Create(ctx context.Context, patient *models.Patient) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.patients[patient.UserID.String()]; ok {
+		return repository.DuplicateError("user_id")
+	}
+	if err := r.s.checkPatientUnique(patient); err != nil {
+		return err
+	}
+	now := time.Now()
+	if patient.CreatedAt.IsZero() {
+		patient.CreatedAt = now
+	}
+	if patient.UpdatedAt.IsZero() {
+		patient.UpdatedAt = now
+	}
+	if patient.Version == 0 {
+		patient.Version = 1
+	}
+	r.s.putPatient(patient)
+	return nil
+}
+
+// Update stores the whole of patient; see userRepository.Update.
+func (r *patientRepository) Update(ctx context.Context, patient *models.Patient, columns ...string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if len(columns) == 0 {
+		return nil
+	}
+	if stored, ok := r.s.patients[patient.UserID.String()]; !ok || stored.DeletedAt.Valid || stored.Version != patient.Version {
+		return repository.StaleError("patient")
+	}
+	if err := r.s.checkPatientUnique(patient); err != nil {
+		return err
+	}
+	patient.Version++
+	patient.UpdatedAt = time.Now()
+	r.s.putPatient(patient)
+	return nil
+}
+
+func (r *patientRepository) Delete(ctx context.Context, userID string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if patient, ok := r.s.patients[userID]; ok && !patient.DeletedAt.Valid {
+		patient.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+		r.s.patients[userID] = patient
+	}
+	return nil
+}
+
+func (r *patientRepository) Anonymize(ctx context.Context, userID string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if patient, ok := r.s.patients[userID]; ok {
+		patient.IDCardNumber = nil
+		patient.IDCardBlindIndex = nil
+		patient.Address = nil
+		patient.EmergencyContact = nil
+		patient.Version++
+		patient.UpdatedAt = time.Now()
+		r.s.patients[userID] = patient
+	}
+	return nil
+}
+
+func (r *patientRepository) Restore(ctx context.Context, userID string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if patient, ok := r.s.patients[userID]; ok {
+		patient.DeletedAt = gorm.DeletedAt{}
+		r.s.patients[userID] = patient
+	}
+	return nil
+}
+
+func (r *patientRepository) PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var count int64
+	for userID, patient := range r.s.patients {
+		if !patient.DeletedAt.Valid || !patient.DeletedAt.Time.Before(cutoff) || r.s.hasDeletionRequest(userID) {
+			continue
+		}
+		delete(r.s.patients, userID)
+		delete(r.s.patientEntitled, userID)
+		count++
+	}
+	return count, nil
+}
+
+// FindCiphertexts returns the stored values, which are plaintext here.
+func (r *patientRepository) FindCiphertexts(ctx context.Context, afterUserID string, limit int) ([]*repository.PatientCiphertexts, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	var rows []*repository.PatientCiphertexts
+	for userID, patient := range r.s.patients {
+		if userID <= afterUserID {
+			continue
+		}
+		rows = append(rows, &repository.PatientCiphertexts{
+			UserID:           userID,
+			IDCardNumber:     patient.IDCardNumber,
+			IDCardBlindIndex: patient.IDCardBlindIndex,
+			Address:          patient.Address,
+			Allergies:        patient.Allergies,
+			EmergencyContact: patient.EmergencyContact,
+		})
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].UserID < rows[j].UserID })
+	return paginate(rows, 0, limit), nil
+}
+
+// Reencrypt only checks the patient exists, as nothing is encrypted here.
+func (r *patientRepository) Reencrypt(ctx context.Context, userID string) error {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	if _, ok := r.s.patients[userID]; !ok {
+		return repository.NotFoundError("patient")
+	}
+	return nil
+}
+
+// FindWithoutBlindIndex finds nothing: ID card numbers are compared in the
+// clear here, so no row needs a blind index.
+func (r *patientRepository) FindWithoutBlindIndex(ctx context.Context, afterUserID string, limit int) ([]string, error) {
+	return nil, nil
+}
+
+func (r *patientRepository) FindManyByIDs(ctx context.Context, patientIDs []string) ([]*models.Patient, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	var patients []*models.Patient
+	for userID, patient := range r.s.patients {
+		if !patient.DeletedAt.Valid && contains(patientIDs, userID) {
+			patient := patient
+			patients = append(patients, &patient)
+		}
+	}
+	return patients, nil
+}
+
+// Search matches a case-insensitive substring of the first or last name or
+// the hospital ID, standing in for the trigram and full-text search.
+func (r *patientRepository) Search(ctx context.Context, query string, limit int) ([]*models.Patient, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	var patients []*models.Patient
+	for userID, patient := range r.s.patients {
+		user, ok := r.s.users[userID]
+		if patient.DeletedAt.Valid || !ok || user.DeletedAt.Valid {
+			continue
+		}
+		if !matches(query, user.FirstName, user.LastName, patient.HospitalID) {
+			continue
+		}
+		patient := patient
+		patient.User = user
+		patients = append(patients, &patient)
+	}
+	sort.Slice(patients, func(i, j int) bool { return patients[i].HospitalID < patients[j].HospitalID })
+	return paginate(patients, 0, limit), nil
+}
+
+// FindPage compares the ID card number in the clear, standing in for the
+// blind index.
+func (r *patientRepository) FindPage(ctx context.Context, filter repository.PatientFilter, offset, limit int) ([]*models.Patient, int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	var patients []*models.Patient
+	for userID, patient := range r.s.patients {
+		user, ok := r.s.users[userID]
+		if patient.DeletedAt.Valid || !ok || user.DeletedAt.Valid {
+			continue
+		}
+		idCard := deref(patient.IDCardNumber)
+		if len(filter.UserIDs) > 0 && !contains(filter.UserIDs, userID) ||
+			filter.HospitalID != "" && patient.HospitalID != filter.HospitalID ||
+			filter.IDCardNumber != "" && idCard != filter.IDCardNumber ||
+			filter.Identifier != "" && patient.HospitalID != filter.Identifier && idCard != filter.Identifier ||
+			filter.Name != "" && !hasPrefix(filter.Name, user.FirstName, user.LastName) {
+			continue
+		}
+		patient := patient
+		patient.User = user
+		patients = append(patients, &patient)
+	}
+	sort.Slice(patients, func(i, j int) bool { return patients[i].UserID.String() < patients[j].UserID.String() })
+	return paginate(patients, offset, limit), int64(len(patients)), nil
+}
+
+// checkPatientUnique enforces the unique hospital ID and the unique ID card
+// number among active patients. The caller holds the lock.
+func (s *Store) checkPatientUnique(patient *models.Patient) error {
+	for userID, other := range s.patients {
+		if userID == patient.UserID.String() {
+			continue
+		}
+		if other.HospitalID == patient.HospitalID {
+			return repository.DuplicateError("hospital_id")
+		}
+		if !other.DeletedAt.Valid && patient.IDCardNumber != nil && other.IDCardNumber != nil &&
+			*other.IDCardNumber == *patient.IDCardNumber {
+			return repository.DuplicateError("id_card_number")
+		}
+	}
+	return nil
+}
+
+// putPatient stores a copy of patient without its associations. The caller
+// holds the write lock.
+func (s *Store) putPatient(patient *models.Patient) {
+	row := *patient
+	row.User = models.User{}
+	row.HealthcareEntitlements = nil
+	s.patients[row.UserID.String()] = row
+}
+
+func contains(ids []string, id string) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+func matches(query string, fields ...string) bool {
+	query = strings.ToLower(query)
+	for _, field := range fields {
+		if strings.Contains(strings.ToLower(field), query) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasPrefix reports whether any field starts with prefix, ignoring case.
+func hasPrefix(prefix string, fields ...string) bool {
+	prefix = strings.ToLower(prefix)
+	for _, field := range fields {
+		if strings.HasPrefix(strings.ToLower(field), prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"user-service/pkg/models"
+	"user-service/pkg/repository"
+)
+
+type profileChangeRepository struct {
+	s *Store
+}
+
+func (r *profileChangeRepository) Create(ctx context.Context, changes []*models.ProfileChange) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, change := range changes {
+		if _, ok := r.s.users[change.UserID.String()]; !ok {
+			return repository.MissingReferenceError("user_id")
+		}
+	}
+	for _, change := range changes {
+		r.s.profileChanges[change.ID.String()] = *change
+	}
+	return nil
+}
+
+func (r *profileChangeRepository) FindByUserID(ctx context.Context, userID string, offset, limit int) ([]*models.ProfileChange, int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	var changes []*models.ProfileChange
+	for _, change := range r.s.profileChanges {
+		if change.UserID.String() == userID {
+			change := change
+			changes = append(changes, &change)
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if !changes[i].ChangedAt.Equal(changes[j].ChangedAt) {
+			return changes[i].ChangedAt.After(changes[j].ChangedAt)
+		}
+		return changes[i].ID.String() > changes[j].ID.String()
+	})
+	return paginate(changes, offset, limit), int64(len(changes)), nil
+}
+
+func (r *profileChangeRepository) Anonymize(ctx context.Context, userID string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for id, change := range r.s.profileChanges {
+		if change.UserID.String() == userID && slices.Contains(repository.AnonymizedFields, change.Field) {
+			change.OldValue, change.NewValue = nil, nil
+			r.s.profileChanges[id] = change
+		}
+	}
+	return nil
+}
+
+// FindCiphertexts returns the stored values, which are plaintext here.
+func (r *profileChangeRepository) FindCiphertexts(ctx context.Context, afterID string, limit int) ([]*repository.ProfileChangeCiphertexts, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	var rows []*repository.ProfileChangeCiphertexts
+	for id, change := range r.s.profileChanges {
+		if id > afterID {
+			rows = append(rows, &repository.ProfileChangeCiphertexts{ID: id, OldValue: change.OldValue, NewValue: change.NewValue})
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].ID < rows[j].ID })
+	return paginate(rows, 0, limit), nil
+}
+
+func (r *profileChangeRepository) Reencrypt(ctx context.Context, id string) error {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	if _, ok := r.s.profileChanges[id]; !ok {
+		return repository.NotFoundError("profile change")
+	}
+	return nil
+}
+
+// purgeProfileChanges mirrors the foreign keys of profile_changes when a user
+// row is hard-deleted: their history is deleted and their actor ID cleared.
+// The caller holds the lock.
+func (s *Store) purgeProfileChanges(userID string) {
+	for id, change := range s.profileChanges {
+		switch {
+		case change.UserID.String() == userID:
+			delete(s.profileChanges, id)
+		case change.ActorID != nil && change.ActorID.String() == userID:
+			change.ActorID = nil
+			s.profileChanges[id] = change
+		}
+	}
+}
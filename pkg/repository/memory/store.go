@@ -0,0 +1,160 @@
+// Package memory implements the repository interfaces on plain maps, so
+// services can be exercised without Postgres. It enforces the same unique
+// constraints as the schema and hides soft-deleted rows like GORM does, and
+// returns the same errors as the GORM repositories. Fields are stored as
+// given; encryption at rest is out of scope here.
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"user-service/pkg/models"
+	"user-service/pkg/repository"
+)
+
+// Store holds every table and implements repository.UnitOfWork. Do takes a
+// snapshot of the tables and restores it if the callback fails, which gives
+// the same all-or-nothing outcome as a transaction (and a savepoint when
+// nested) but no isolation from concurrent callers.
+type Store struct {
+	mu               sync.RWMutex
+	users            map[string]models.User
+	patients         map[string]models.Patient
+	doctors          map[string]models.Doctor
+	admins           map[string]models.Admin
+	deletionRequests map[string]models.AccountDeletionRequest
+	entitlements     map[string]struct{}
+	patientEntitled  map[string]map[string]struct{}
+	profileChanges   map[string]models.ProfileChange
+	auditLog         []models.AuditEntry
+}
+
+var _ repository.UnitOfWork = (*Store)(nil)
+
+func NewStore() *Store {
+	return &Store{
+		users:            map[string]models.User{},
+		patients:         map[string]models.Patient{},
+		doctors:          map[string]models.Doctor{},
+		admins:           map[string]models.Admin{},
+		deletionRequests: map[string]models.AccountDeletionRequest{},
+		entitlements:     map[string]struct{}{},
+		patientEntitled:  map[string]map[string]struct{}{},
+		profileChanges:   map[string]models.ProfileChange{},
+	}
+}
+
+// SeedAdmin adds an admin account; admins are not created through the API.
+func (s *Store) SeedAdmin(admin *models.Admin) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.admins[admin.UserID.String()] = *admin
+}
+
+// SeedEntitlements adds healthcare entitlement master data.
+func (s *Store) SeedEntitlements(names ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, name := range names {
+		s.entitlements[name] = struct{}{}
+	}
+}
+
+func (s *Store) Users() repository.UserRepository       { return &userRepository{s} }
+func (s *Store) Patients() repository.PatientRepository { return &patientRepository{s} }
+func (s *Store) Doctors() repository.DoctorRepository   { return &doctorRepository{s} }
+func (s *Store) Admins() repository.AdminRepository     { return &adminRepository{s} }
+func (s *Store) AccountDeletions() repository.AccountDeletionRepository {
+	return &accountDeletionRepository{s}
+}
+func (s *Store) Entitlements() repository.EntitlementRepository { return &entitlementRepository{s} }
+func (s *Store) ProfileChanges() repository.ProfileChangeRepository {
+	return &profileChangeRepository{s}
+}
+func (s *Store) AuditLog() repository.AuditRepository { return &auditRepository{s} }
+
+func (s *Store) Do(ctx context.Context, fn func(tx repository.UnitOfWork) error) (err error) {
+	snapshot := s.snapshot()
+	defer func() {
+		if r := recover(); r != nil {
+			s.restore(snapshot)
+			panic(r)
+		}
+		if err != nil {
+			s.restore(snapshot)
+		}
+	}()
+	return fn(s)
+}
+
+// Snapshot is Do; the store has no isolation levels.
+func (s *Store) Snapshot(ctx context.Context, fn func(tx repository.UnitOfWork) error) error {
+	return s.Do(ctx, fn)
+}
+
+type tables struct {
+	users            map[string]models.User
+	patients         map[string]models.Patient
+	doctors          map[string]models.Doctor
+	admins           map[string]models.Admin
+	deletionRequests map[string]models.AccountDeletionRequest
+	entitlements     map[string]struct{}
+	patientEntitled  map[string]map[string]struct{}
+	profileChanges   map[string]models.ProfileChange
+	auditLog         []models.AuditEntry
+}
+
+func (s *Store) snapshot() tables {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entitled := make(map[string]map[string]struct{}, len(s.patientEntitled))
+	for patientID, names := range s.patientEntitled {
+		entitled[patientID] = clone(names)
+	}
+	return tables{
+		users:            clone(s.users),
+		patients:         clone(s.patients),
+		doctors:          clone(s.doctors),
+		admins:           clone(s.admins),
+		deletionRequests: clone(s.deletionRequests),
+		entitlements:     clone(s.entitlements),
+		patientEntitled:  entitled,
+		profileChanges:   clone(s.profileChanges),
+		auditLog:         slices.Clone(s.auditLog),
+	}
+}
+
+func (s *Store) restore(t tables) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users = t.users
+	s.patients = t.patients
+	s.doctors = t.doctors
+	s.admins = t.admins
+	s.deletionRequests = t.deletionRequests
+	s.entitlements = t.entitlements
+	s.patientEntitled = t.patientEntitled
+	s.profileChanges = t.profileChanges
+	s.auditLog = t.auditLog
+}
+
+func clone[K comparable, V any](m map[K]V) map[K]V {
+	c := make(map[K]V, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+// paginate applies offset and limit to an already ordered slice.
+func paginate[T any](items []T, offset, limit int) []T {
+	if offset >= len(items) {
+		return []T{}
+	}
+	items = items[offset:]
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
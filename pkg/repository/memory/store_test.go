@@ -0,0 +1,148 @@
+package memory_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"user-service/pkg/apperr"
+	"user-service/pkg/models"
+	"user-service/pkg/repository"
+	"user-service/pkg/repository/memory"
+	"user-service/pkg/utils"
+)
+
+func newUser() *models.User {
+	return &models.User{ID: utils.GenerateUUIDv7(), FirstName: "Somchai", LastName: "Jaidee", Role: models.PatientRole}
+}
+
+func exists(t *testing.T, store *memory.Store, user *models.User) bool {
+	t.Helper()
+	_, err := store.Users().FindByID(context.Background(), user.ID.String())
+	return err == nil
+}
+
+func TestDoRollsBackOnError(t *testing.T) {
+	store := memory.NewStore()
+	user := newUser()
+	failure := errors.New("boom")
+
+	err := store.Do(context.Background(), func(tx repository.UnitOfWork) error {
+		if err := tx.Users().Create(context.Background(), user); err != nil {
+			return err
+		}
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("Do returned %v, want %v", err, failure)
+	}
+	if exists(t, store, user) {
+		t.Error("user created in a failed unit of work was kept")
+	}
+}
+
+func TestDoRePanicsAfterRollback(t *testing.T) {
+	store := memory.NewStore()
+	user := newUser()
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("panic was swallowed")
+		}
+		if exists(t, store, user) {
+			t.Error("user created before the panic was kept")
+		}
+	}()
+	_ = store.Do(context.Background(), func(tx repository.UnitOfWork) error {
+		_ = tx.Users().Create(context.Background(), user)
+		panic("boom")
+	})
+}
+
+func TestNestedDoOnlyUndoesInnerWrites(t *testing.T) {
+	store := memory.NewStore()
+	outer, inner := newUser(), newUser()
+
+	err := store.Do(context.Background(), func(tx repository.UnitOfWork) error {
+		if err := tx.Users().Create(context.Background(), outer); err != nil {
+			return err
+		}
+		_ = tx.Do(context.Background(), func(tx repository.UnitOfWork) error {
+			_ = tx.Users().Create(context.Background(), inner)
+			return errors.New("inner failure")
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !exists(t, store, outer) {
+		t.Error("outer write was lost")
+	}
+	if exists(t, store, inner) {
+		t.Error("inner write survived its rollback")
+	}
+}
+
+func TestSoftDeleteAndUniqueness(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	first, second := newUser(), newUser()
+	for _, user := range []*models.User{first, second} {
+		if err := store.Users().Create(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+	}
+	idCard := "1101700203450"
+	if err := store.Patients().Create(ctx, &models.Patient{UserID: first.ID, HospitalID: "HN0001", IDCardNumber: &idCard}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := store.Patients().Create(ctx, &models.Patient{UserID: second.ID, HospitalID: "HN0001"})
+	if !apperr.IsCode(err, apperr.CodeConflict) {
+		t.Fatalf("duplicate hospital id: got %v", err)
+	}
+	err = store.Patients().Create(ctx, &models.Patient{UserID: second.ID, HospitalID: "HN0002", IDCardNumber: &idCard})
+	if !apperr.IsCode(err, apperr.CodeConflict) {
+		t.Fatalf("duplicate id card number: got %v", err)
+	}
+
+	// The ID card number is only unique among active patients.
+	if err := store.Patients().Delete(ctx, first.ID.String()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Patients().FindByUserID(ctx, first.ID.String()); err == nil {
+		t.Error("soft-deleted patient is still found")
+	}
+	if err := store.Patients().Create(ctx, &models.Patient{UserID: second.ID, HospitalID: "HN0002", IDCardNumber: &idCard}); err != nil {
+		t.Errorf("id card number of a deleted patient was not released: %v", err)
+	}
+}
+
+func TestUpdateRejectsStaleVersion(t *testing.T) {
+	store := memory.NewStore()
+	ctx := context.Background()
+	user := newUser()
+	if err := store.Users().Create(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := store.Users().FindByID(ctx, user.ID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := store.Users().FindByID(ctx, user.ID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.FirstName = "Somying"
+	if err := store.Users().Update(ctx, first, "first_name"); err != nil {
+		t.Fatalf("first update: %v", err)
+	}
+	if first.Version != 2 {
+		t.Errorf("version after update = %d, want 2", first.Version)
+	}
+	second.LastName = "Overwritten"
+	if err := store.Users().Update(ctx, second, "last_name"); !apperr.IsCode(err, apperr.CodePreconditionFailed) {
+		t.Fatalf("stale update returned %v, want a precondition error", err)
+	}
+}
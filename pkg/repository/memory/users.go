@@ -0,0 +1,214 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+	"user-service/pkg/models"
+	"user-service/pkg/repository"
+
+	"gorm.io/gorm"
+)
+
+type userRepository struct {
+	s *Store
+}
+
+func (r *userRepository) FindByID(ctx context.Context, id string) (*models.User, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	user, ok := r.s.users[id]
+	if !ok || user.DeletedAt.Valid {
+		return nil, repository.NotFoundError("user")
+	}
+	return &user, nil
+}
+
+// FindByIDForUpdate is FindByID; the store has no row locks.
+func (r *userRepository) FindByIDForUpdate(ctx context.Context, id string) (*models.User, error) {
+	return r.FindByID(ctx, id)
+}
+
+func (r *userRepository) FindByIDUnscoped(ctx context.Context, id string) (*models.User, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	user, ok := r.s.users[id]
+	if !ok {
+		return nil, repository.NotFoundError("user")
+	}
+	return &user, nil
+}
+
+func (r *userRepository) FindDeleted(ctx context.Context, role string, offset, limit int) ([]*models.User, int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	var users []*models.User
+	for _, user := range r.s.users {
+		if !user.DeletedAt.Valid || (role != "" && string(user.Role) != role) {
+			continue
+		}
+		user := user
+		users = append(users, &user)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].DeletedAt.Time.After(users[j].DeletedAt.Time)
+	})
+	return paginate(users, offset, limit), int64(len(users)), nil
+}
+
+// FindByEmail never matches: users have no email column.
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	return nil, repository.NotFoundError("user")
+}
+
+func (r *userRepository) Create(ctx context.Context, user *models.User) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.users[user.ID.String()]; ok {
+		return repository.DuplicateError("id")
+	}
+	now := time.Now()
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = now
+	}
+	if user.UpdatedAt.IsZero() {
+		user.UpdatedAt = now
+	}
+	if user.Version == 0 {
+		user.Version = 1
+	}
+	r.s.putUser(user)
+	return nil
+}
+
+// Update stores the whole of user rather than only columns, which is the same
+// thing as long as callers pass a row they just read and modified.
+func (r *userRepository) Update(ctx context.Context, user *models.User, columns ...string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if len(columns) == 0 {
+		return nil
+	}
+	if stored, ok := r.s.users[user.ID.String()]; !ok || stored.DeletedAt.Valid || stored.Version != user.Version {
+		return repository.StaleError("user")
+	}
+	user.Version++
+	user.UpdatedAt = time.Now()
+	r.s.putUser(user)
+	return nil
+}
+
+func (r *userRepository) Delete(ctx context.Context, id string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if user, ok := r.s.users[id]; ok && !user.DeletedAt.Valid {
+		user.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+		r.s.users[id] = user
+	}
+	return nil
+}
+
+func (r *userRepository) Restore(ctx context.Context, id string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if user, ok := r.s.users[id]; ok {
+		user.DeletedAt = gorm.DeletedAt{}
+		r.s.users[id] = user
+	}
+	return nil
+}
+
+func (r *userRepository) PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var count int64
+	for id, user := range r.s.users {
+		if !user.DeletedAt.Valid || !user.DeletedAt.Time.Before(cutoff) || r.s.hasDeletionRequest(id) {
+			continue
+		}
+		// ON DELETE CASCADE
+		delete(r.s.users, id)
+		delete(r.s.patients, id)
+		delete(r.s.doctors, id)
+		delete(r.s.admins, id)
+		delete(r.s.patientEntitled, id)
+		r.s.purgeProfileChanges(id)
+		count++
+	}
+	return count, nil
+}
+
+func (r *userRepository) Anonymize(ctx context.Context, id string, password string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if user, ok := r.s.users[id]; ok {
+		user.FirstName = "Deleted"
+		user.LastName = "User"
+		user.PhoneNumber = ""
+		user.Password = password
+		user.Version++
+		user.UpdatedAt = time.Now()
+		r.s.users[id] = user
+	}
+	return nil
+}
+
+func (r *userRepository) FindManyByIDs(ctx context.Context, userIDs []string) ([]*models.User, error) {
+	return r.findMany(userIDs, "", nil), nil
+}
+
+func (r *userRepository) FindManyDoctorsByIDs(ctx context.Context, doctorIDs []string) ([]*models.User, error) {
+	return r.findMany(doctorIDs, models.DoctorRole, func(user *models.User) {
+		if doctor, ok := r.s.doctors[user.ID.String()]; ok && !doctor.DeletedAt.Valid {
+			user.Doctor = &doctor
+		}
+	}), nil
+}
+
+func (r *userRepository) FindManyPatientsByIDs(ctx context.Context, patientIDs []string) ([]*models.User, error) {
+	return r.findMany(patientIDs, models.PatientRole, func(user *models.User) {
+		if patient, ok := r.s.patients[user.ID.String()]; ok && !patient.DeletedAt.Valid {
+			user.Patient = &patient
+		}
+	}), nil
+}
+
+// findMany returns the active users among ids, optionally restricted to a
+// role, calling preload on each to attach associations.
+func (r *userRepository) findMany(ids []string, role models.Role, preload func(*models.User)) []*models.User {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	var users []*models.User
+	seen := map[string]bool{}
+	for _, id := range ids {
+		user, ok := r.s.users[id]
+		if !ok || user.DeletedAt.Valid || (role != "" && user.Role != role) || seen[id] {
+			continue
+		}
+		seen[id] = true
+		if preload != nil {
+			preload(&user)
+		}
+		users = append(users, &user)
+	}
+	return users
+}
+
+// putUser stores a copy of user without its associations. The caller holds
+// the write lock.
+func (s *Store) putUser(user *models.User) {
+	row := *user
+	row.Patient, row.Doctor, row.Admin = nil, nil, nil
+	s.users[row.ID.String()] = row
+}
+
+// hasDeletionRequest reports whether the user ever asked for their account to
+// be closed. The caller holds the lock.
+func (s *Store) hasDeletionRequest(userID string) bool {
+	for _, request := range s.deletionRequests {
+		if request.UserID.String() == userID {
+			return true
+		}
+	}
+	return false
+}
@@ -2,77 +2,298 @@ package repository
 
 import (
 	"context"
+	"slices"
+	"time"
+	"user-service/pkg/encryption"
 	"user-service/pkg/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-type PatientRepository struct {
+// PatientRepository persists patients, keeping the ID card blind index in
+// sync with the encrypted ID card number. Update writes only the named
+// columns and fails if the row's version moved on since it was read.
+type PatientRepository interface {
+	FindByUserID(ctx context.Context, userID string) (*models.Patient, error)
+	// FindByUserIDForUpdate is FindByUserID, locking the row until the
+	// transaction ends.
+	FindByUserIDForUpdate(ctx context.Context, userID string) (*models.Patient, error)
+	FindByHospitalID(ctx context.Context, hospitalID string) (*models.Patient, error)
+	FindByIDCardNumber(ctx context.Context, idCardNumber string) (*models.Patient, error)
+	Create(ctx context.Context, patient *models.Patient) error
+	Update(ctx context.Context, patient *models.Patient, columns ...string) error
+	Delete(ctx context.Context, userID string) error
+	Anonymize(ctx context.Context, userID string) error
+	Restore(ctx context.Context, userID string) error
+	PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, error)
+	FindCiphertexts(ctx context.Context, afterUserID string, limit int) ([]*PatientCiphertexts, error)
+	Reencrypt(ctx context.Context, userID string) error
+	FindWithoutBlindIndex(ctx context.Context, afterUserID string, limit int) ([]string, error)
+	FindManyByIDs(ctx context.Context, patientIDs []string) ([]*models.Patient, error)
+	Search(ctx context.Context, query string, limit int) ([]*models.Patient, error)
+	// FindPage lists a page of active patients matching filter, with their
+	// users, ordered by ID, and the total number of matches.
+	FindPage(ctx context.Context, filter PatientFilter, offset, limit int) ([]*models.Patient, int64, error)
+}
+
+// PatientFilter narrows FindPage; zero fields match everything, and set
+// fields must all match.
+type PatientFilter struct {
+	UserIDs      []string
+	HospitalID   string
+	IDCardNumber string
+	// Identifier matches either the hospital ID or the ID card number.
+	Identifier string
+	// Name matches first or last names that start with it, ignoring case.
+	Name string
+}
+
+type patientRepository struct {
 	db *gorm.DB
 }
 
-func NewPatientRepository(db *gorm.DB) *PatientRepository {
-	return &PatientRepository{
+func NewPatientRepository(db *gorm.DB) PatientRepository {
+	return &patientRepository{
 		db: db,
 	}
 }
 
-func (r *PatientRepository) Transaction(ctx context.Context, fn func(repo *PatientRepository) (interface{}, error)) (interface{}, error) {
-	tx := r.db.Begin()
-	if tx.Error != nil {
-		return nil, tx.Error
-	}
-	repoWithTx := r.withTx(tx)
-
-	result, err := fn(repoWithTx)
-	if err != nil {
-		tx.Rollback()
-		return nil, err
-	}
-	if err := tx.Commit().Error; err != nil {
-		return nil, err
+func (r *patientRepository) FindByUserID(ctx context.Context, userID string) (*models.Patient, error) {
+	var patient models.Patient
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&patient).Error; err != nil {
+		return nil, translateError(err, "patient")
 	}
-	return result, nil
+	return &patient, nil
 }
 
-func (r *PatientRepository) withTx(tx *gorm.DB) *PatientRepository {
-	return &PatientRepository{db: tx}
+func (r *patientRepository) FindByUserIDForUpdate(ctx context.Context, userID string) (*models.Patient, error) {
+	var patient models.Patient
+	if err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ?", userID).
+		First(&patient).Error; err != nil {
+		return nil, translateError(err, "patient")
+	}
+	return &patient, nil
 }
 
-func (r *PatientRepository) FindByUserID(ctx context.Context, userID string) (*models.Patient, error) {
+func (r *patientRepository) FindByHospitalID(ctx context.Context, hospitalID string) (*models.Patient, error) {
 	var patient models.Patient
-	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&patient).Error; err != nil {
-		return nil, err
+	if err := r.db.WithContext(ctx).Where("hospital_id = ?", hospitalID).First(&patient).Error; err != nil {
+		return nil, translateError(err, "patient")
 	}
 	return &patient, nil
 }
 
-func (r *PatientRepository) FindByHospitalID(ctx context.Context, hospitalID string) (*models.Patient, error) {
+// FindByIDCardNumber looks a patient up through the blind index, since the
+// ID card number column itself is encrypted.
+func (r *patientRepository) FindByIDCardNumber(ctx context.Context, idCardNumber string) (*models.Patient, error) {
 	var patient models.Patient
-	if err := r.db.WithContext(ctx).Where("hospital_id = ?", hospitalID).First(&patient).Error; err != nil {
-		return nil, err
+	if err := r.db.WithContext(ctx).Where("id_card_blind_index = ?", encryption.BlindIndex(idCardNumber)).First(&patient).Error; err != nil {
+		return nil, translateError(err, "patient")
 	}
 	return &patient, nil
 }
 
-func (r *PatientRepository) Create(ctx context.Context, patient *models.Patient) error {
+func (r *patientRepository) Create(ctx context.Context, patient *models.Patient) error {
+	setBlindIndex(patient)
 	if err := r.db.WithContext(ctx).Create(patient).Error; err != nil {
-		return err
+		return translateError(err, "patient")
+	}
+	return nil
+}
+
+// Update writes the named columns of patient if it is still at
+// patient.Version, and fails with a precondition error otherwise.
+func (r *patientRepository) Update(ctx context.Context, patient *models.Patient, columns ...string) error {
+	if slices.Contains(columns, "id_card_number") {
+		setBlindIndex(patient)
+		columns = append(columns, "id_card_blind_index")
+	}
+	return updateVersioned(r.db.WithContext(ctx), patient, &patient.Version, &patient.UpdatedAt, "patient", columns)
+}
+
+func (r *patientRepository) Delete(ctx context.Context, userID string) error {
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&models.Patient{}).Error; err != nil {
+		return translateError(err, "patient")
 	}
 	return nil
 }
 
-func (r *PatientRepository) Update(ctx context.Context, patient *models.Patient) error {
-	if err := r.db.WithContext(ctx).Save(patient).Error; err != nil {
-		return err
+// Anonymize clears identifying patient fields, including on a soft-deleted
+// row. Clinical fields and the hospital ID are kept for medical history.
+func (r *patientRepository) Anonymize(ctx context.Context, userID string) error {
+	if err := r.db.WithContext(ctx).Unscoped().Model(&models.Patient{}).Where("user_id = ?", userID).Updates(map[string]interface{}{
+		"id_card_number":      nil,
+		"id_card_blind_index": nil,
+		"address":             nil,
+		"emergency_contact":   nil,
+		"version":             gorm.Expr("version + 1"),
+		"updated_at":          time.Now(),
+	}).Error; err != nil {
+		return translateError(err, "patient")
 	}
 	return nil
 }
 
-func (r *PatientRepository) FindManyByIDs(ctx context.Context, patientIDs []string) ([]*models.Patient, error) {
+func (r *patientRepository) Restore(ctx context.Context, userID string) error {
+	if err := r.db.WithContext(ctx).Unscoped().Model(&models.Patient{}).Where("user_id = ?", userID).Update("deleted_at", nil).Error; err != nil {
+		return translateError(err, "patient")
+	}
+	return nil
+}
+
+// PurgeDeleted hard-deletes patient rows soft-deleted before cutoff, except for
+// users with an account deletion request.
+func (r *patientRepository) PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Unscoped().
+		Where("deleted_at < ?", cutoff).
+		Where("NOT EXISTS (SELECT 1 FROM account_deletion_requests r WHERE r.user_id = patients.user_id)").
+		Delete(&models.Patient{})
+	if result.Error != nil {
+		return 0, translateError(result.Error, "patient")
+	}
+	return result.RowsAffected, nil
+}
+
+// PatientCiphertexts holds the encrypted columns of a patient exactly as
+// stored, bypassing the decrypting serializer.
+type PatientCiphertexts struct {
+	UserID           string
+	IDCardNumber     *string
+	IDCardBlindIndex *string
+	Address          *string
+	Allergies        *string
+	EmergencyContact *string
+}
+
+// FindCiphertexts pages through all patients, including soft-deleted ones,
+// ordered by user ID and starting after afterUserID.
+func (r *patientRepository) FindCiphertexts(ctx context.Context, afterUserID string, limit int) ([]*PatientCiphertexts, error) {
+	var rows []*PatientCiphertexts
+	query := r.db.WithContext(ctx).Table("patients").
+		Select("user_id", "id_card_number", "id_card_blind_index", "address", "allergies", "emergency_contact").
+		Order("user_id").
+		Limit(limit)
+	if afterUserID != "" {
+		query = query.Where("user_id > ?", afterUserID)
+	}
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, translateError(err, "patient")
+	}
+	return rows, nil
+}
+
+// Reencrypt rewrites a patient's encrypted columns with the active key and
+// recomputes the blind index.
+func (r *patientRepository) Reencrypt(ctx context.Context, userID string) error {
+	var patient models.Patient
+	if err := r.db.WithContext(ctx).Unscoped().Where("user_id = ?", userID).First(&patient).Error; err != nil {
+		return translateError(err, "patient")
+	}
+	setBlindIndex(&patient)
+	if err := r.db.WithContext(ctx).Unscoped().Model(&patient).
+		Select("id_card_number", "id_card_blind_index", "address", "allergies", "emergency_contact").
+		Updates(&patient).Error; err != nil {
+		return translateError(err, "patient")
+	}
+	return nil
+}
+
+// FindWithoutBlindIndex pages through the IDs of patients, including
+// soft-deleted ones, that have an ID card number but no blind index: rows
+// written before the index existed.
+func (r *patientRepository) FindWithoutBlindIndex(ctx context.Context, afterUserID string, limit int) ([]string, error) {
+	var ids []string
+	query := r.db.WithContext(ctx).Table("patients").
+		Where("id_card_number IS NOT NULL AND id_card_blind_index IS NULL").
+		Order("user_id").
+		Limit(limit)
+	if afterUserID != "" {
+		query = query.Where("user_id > ?", afterUserID)
+	}
+	if err := query.Pluck("user_id", &ids).Error; err != nil {
+		return nil, translateError(err, "patient")
+	}
+	return ids, nil
+}
+
+func (r *patientRepository) FindManyByIDs(ctx context.Context, patientIDs []string) ([]*models.Patient, error) {
 	var patients []*models.Patient
 	if err := r.db.WithContext(ctx).Where("user_id IN ?", patientIDs).Find(&patients).Error; err != nil {
-		return nil, err
+		return nil, translateError(err, "patient")
 	}
 	return patients, nil
 }
+
+const patientSearchCondition = `(users.first_name % @q OR users.last_name % @q
+	OR patients.hospital_id % @q
+	OR users.first_name ILIKE @pattern OR users.last_name ILIKE @pattern
+	OR patients.hospital_id ILIKE @pattern
+	OR ` + userNameTSVector + ` @@ ` + searchTSQuery + `)`
+
+const patientSearchRank = `GREATEST(
+	similarity(users.first_name, @q),
+	similarity(users.last_name, @q),
+	similarity(patients.hospital_id, @q)
+) + ts_rank(` + userNameTSVector + `, ` + searchTSQuery + `)`
+
+// Search returns patients matching query by first/last name or hospital ID,
+// ordered by trigram similarity and full-text rank.
+func (r *patientRepository) Search(ctx context.Context, query string, limit int) ([]*models.Patient, error) {
+	args := searchArgs(query)
+	var patients []*models.Patient
+	if err := r.db.WithContext(ctx).
+		Preload("User").
+		Joins("JOIN users ON users.id = patients.user_id AND users.deleted_at IS NULL").
+		Where(patientSearchCondition, args).
+		Order(orderByRank(patientSearchRank, args)).
+		Limit(limit).
+		Find(&patients).Error; err != nil {
+		return nil, translateError(err, "patient")
+	}
+	return patients, nil
+}
+
+func (r *patientRepository) FindPage(ctx context.Context, filter PatientFilter, offset, limit int) ([]*models.Patient, int64, error) {
+	query := r.db.WithContext(ctx).
+		Model(&models.Patient{}).
+		Joins("JOIN users ON users.id = patients.user_id AND users.deleted_at IS NULL")
+	if len(filter.UserIDs) > 0 {
+		query = query.Where("patients.user_id IN ?", filter.UserIDs)
+	}
+	if filter.HospitalID != "" {
+		query = query.Where("patients.hospital_id = ?", filter.HospitalID)
+	}
+	if filter.IDCardNumber != "" {
+		query = query.Where("patients.id_card_blind_index = ?", encryption.BlindIndex(filter.IDCardNumber))
+	}
+	if filter.Identifier != "" {
+		query = query.Where("(patients.hospital_id = ? OR patients.id_card_blind_index = ?)",
+			filter.Identifier, encryption.BlindIndex(filter.Identifier))
+	}
+	if filter.Name != "" {
+		pattern := likeEscaper.Replace(filter.Name) + "%"
+		query = query.Where("(users.first_name ILIKE ? OR users.last_name ILIKE ?)", pattern, pattern)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, translateError(err, "patient")
+	}
+	var patients []*models.Patient
+	if err := query.Preload("User").Order("patients.user_id").Offset(offset).Limit(limit).Find(&patients).Error; err != nil {
+		return nil, 0, translateError(err, "patient")
+	}
+	return patients, total, nil
+}
+
+func setBlindIndex(patient *models.Patient) {
+	if patient.IDCardNumber == nil {
+		patient.IDCardBlindIndex = nil
+		return
+	}
+	index := encryption.BlindIndex(*patient.IDCardNumber)
+	patient.IDCardBlindIndex = &index
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"user-service/pkg/models"
+
+	"gorm.io/gorm"
+)
+
+// AnonymizedFields are the profile fields that UserRepository.Anonymize and
+// PatientRepository.Anonymize overwrite; their history is erased with them.
+var AnonymizedFields = []string{
+	"first_name",
+	"last_name",
+	"phone_number",
+	"id_card_number",
+	"address",
+	"emergency_contact",
+}
+
+// ProfileChangeRepository persists the per-field history of profile updates.
+// History is append-only apart from erasure and key rotation.
+type ProfileChangeRepository interface {
+	Create(ctx context.Context, changes []*models.ProfileChange) error
+	FindByUserID(ctx context.Context, userID string, offset, limit int) ([]*models.ProfileChange, int64, error)
+	Anonymize(ctx context.Context, userID string) error
+	FindCiphertexts(ctx context.Context, afterID string, limit int) ([]*ProfileChangeCiphertexts, error)
+	Reencrypt(ctx context.Context, id string) error
+}
+
+type profileChangeRepository struct {
+	db *gorm.DB
+}
+
+func NewProfileChangeRepository(db *gorm.DB) ProfileChangeRepository {
+	return &profileChangeRepository{
+		db: db,
+	}
+}
+
+func (r *profileChangeRepository) Create(ctx context.Context, changes []*models.ProfileChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	if err := r.db.WithContext(ctx).Create(changes).Error; err != nil {
+		return translateError(err, "profile change")
+	}
+	return nil
+}
+
+// FindByUserID lists a page of a user's profile changes, newest first,
+// together with the total count.
+func (r *profileChangeRepository) FindByUserID(ctx context.Context, userID string, offset, limit int) ([]*models.ProfileChange, int64, error) {
+	var changes []*models.ProfileChange
+	var total int64
+	query := r.db.WithContext(ctx).Model(&models.ProfileChange{}).Where("user_id = ?", userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, translateError(err, "profile change")
+	}
+	if err := query.Order("changed_at DESC, id DESC").Offset(offset).Limit(limit).Find(&changes).Error; err != nil {
+		return nil, 0, translateError(err, "profile change")
+	}
+	return changes, total, nil
+}
+
+// Anonymize clears the old and new values recorded for the user's
+// identifying fields. The entries themselves, and the values of clinical
+// fields, are kept like the clinical columns of the patient row.
+func (r *profileChangeRepository) Anonymize(ctx context.Context, userID string) error {
+	if err := r.db.WithContext(ctx).Model(&models.ProfileChange{}).
+		Where("user_id = ? AND field IN ?", userID, AnonymizedFields).
+		Updates(map[string]interface{}{"old_value": nil, "new_value": nil}).Error; err != nil {
+		return translateError(err, "profile change")
+	}
+	return nil
+}
+
+// ProfileChangeCiphertexts holds the encrypted values of a profile change
+// exactly as stored, bypassing the decrypting serializer.
+type ProfileChangeCiphertexts struct {
+	ID       string
+	OldValue *string
+	NewValue *string
+}
+
+// FindCiphertexts pages through all profile changes ordered by ID, starting
+// after afterID.
+func (r *profileChangeRepository) FindCiphertexts(ctx context.Context, afterID string, limit int) ([]*ProfileChangeCiphertexts, error) {
+	var rows []*ProfileChangeCiphertexts
+	query := r.db.WithContext(ctx).Table("profile_changes").
+		Select("id", "old_value", "new_value").
+		Order("id").
+		Limit(limit)
+	if afterID != "" {
+		query = query.Where("id > ?", afterID)
+	}
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, translateError(err, "profile change")
+	}
+	return rows, nil
+}
+
+// Reencrypt rewrites a profile change's values with the active key.
+func (r *profileChangeRepository) Reencrypt(ctx context.Context, id string) error {
+	var change models.ProfileChange
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&change).Error; err != nil {
+		return translateError(err, "profile change")
+	}
+	if err := r.db.WithContext(ctx).Model(&change).
+		Select("old_value", "new_value").
+		Updates(&change).Error; err != nil {
+		return translateError(err, "profile change")
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"strings"
+
+	"gorm.io/gorm/clause"
+)
+
+// Shared full-text expressions. They must match the index definitions in
+// 20261019090000_search_indexes.sql for Postgres to use the GIN indexes.
+const (
+	userNameTSVector      = `to_tsvector('simple', users.first_name || ' ' || users.last_name)`
+	doctorProfileTSVector = `to_tsvector('simple', doctors.username || ' ' || coalesce(doctors.specialty, '') || ' ' || coalesce(doctors.bio, ''))`
+	searchTSQuery         = `plainto_tsquery('simple', @q)`
+)
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// searchArgs builds the named arguments used by the search queries: the raw
+// term for similarity and full-text matching, and an escaped ILIKE pattern.
+func searchArgs(query string) map[string]interface{} {
+	return map[string]interface{}{
+		"q":       query,
+		"pattern": "%" + likeEscaper.Replace(query) + "%",
+	}
+}
+
+// orderByRank sorts results by the given rank expression, best match first.
+func orderByRank(rank string, args map[string]interface{}) clause.OrderBy {
+	return clause.OrderBy{Expression: clause.NamedExpr{SQL: rank + " DESC", Vars: []interface{}{args}}}
+}
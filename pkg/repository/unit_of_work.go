@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"gorm.io/gorm"
+)
+
+// UnitOfWork groups every repository over one database handle. The root
+// unit works on the connection pool; Do hands the callback a unit bound to a
+// transaction so several writes commit or roll back together.
+type UnitOfWork interface {
+	Users() UserRepository
+	Patients() PatientRepository
+	Doctors() DoctorRepository
+	Admins() AdminRepository
+	AccountDeletions() AccountDeletionRepository
+	Entitlements() EntitlementRepository
+	ProfileChanges() ProfileChangeRepository
+	AuditLog() AuditRepository
+
+	// Do runs fn in a transaction and commits it if fn returns nil. The
+	// transaction is rolled back if fn returns an error or panics, and the
+	// panic is re-raised after the rollback. Calling Do on the unit passed to
+	// fn nests a savepoint, so an inner failure only undoes the inner writes.
+	Do(ctx context.Context, fn func(tx UnitOfWork) error) error
+	// Snapshot is Do for reads only: fn runs in a read-only, repeatable-read
+	// transaction, so every read sees the database as it was at the first.
+	// Called on a unit passed to fn, it runs fn in the enclosing transaction.
+	Snapshot(ctx context.Context, fn func(tx UnitOfWork) error) error
+}
+
+type unitOfWork struct {
+	db               *gorm.DB
+	users            UserRepository
+	patients         PatientRepository
+	doctors          DoctorRepository
+	admins           AdminRepository
+	accountDeletions AccountDeletionRepository
+	entitlements     EntitlementRepository
+	profileChanges   ProfileChangeRepository
+	auditLog         AuditRepository
+}
+
+func NewUnitOfWork(db *gorm.DB) UnitOfWork {
+	return &unitOfWork{
+		db:               db,
+		users:            NewUserRepository(db),
+		patients:         NewPatientRepository(db),
+		doctors:          NewDoctorRepository(db),
+		admins:           NewAdminRepository(db),
+		accountDeletions: NewAccountDeletionRepository(db),
+		entitlements:     NewEntitlementRepository(db),
+		profileChanges:   NewProfileChangeRepository(db),
+		auditLog:         NewAuditRepository(db),
+	}
+}
+
+func (u *unitOfWork) Users() UserRepository                       { return u.users }
+func (u *unitOfWork) Patients() PatientRepository                 { return u.patients }
+func (u *unitOfWork) Doctors() DoctorRepository                   { return u.doctors }
+func (u *unitOfWork) Admins() AdminRepository                     { return u.admins }
+func (u *unitOfWork) AccountDeletions() AccountDeletionRepository { return u.accountDeletions }
+func (u *unitOfWork) Entitlements() EntitlementRepository         { return u.entitlements }
+func (u *unitOfWork) ProfileChanges() ProfileChangeRepository     { return u.profileChanges }
+func (u *unitOfWork) AuditLog() AuditRepository                   { return u.auditLog }
+
+func (u *unitOfWork) Do(ctx context.Context, fn func(tx UnitOfWork) error) error {
+	return u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(NewUnitOfWork(tx))
+	})
+}
+
+func (u *unitOfWork) Snapshot(ctx context.Context, fn func(tx UnitOfWork) error) error {
+	return u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(NewUnitOfWork(tx))
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+}
@@ -2,108 +2,185 @@ package repository
 
 import (
 	"context"
+	"time"
 	"user-service/pkg/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-type UserRepository struct {
+// UserRepository persists users. Lookups return an apperr NotFound error
+// when no row matches, and constraint violations are translated too. Update
+// writes only the named columns and fails if the row's version moved on since
+// it was read.
+type UserRepository interface {
+	FindByID(ctx context.Context, id string) (*models.User, error)
+	// FindByIDForUpdate is FindByID, locking the row until the transaction
+	// ends.
+	FindByIDForUpdate(ctx context.Context, id string) (*models.User, error)
+	FindByIDUnscoped(ctx context.Context, id string) (*models.User, error)
+	FindDeleted(ctx context.Context, role string, offset, limit int) ([]*models.User, int64, error)
+	FindByEmail(ctx context.Context, email string) (*models.User, error)
+	Create(ctx context.Context, user *models.User) error
+	Update(ctx context.Context, user *models.User, columns ...string) error
+	Delete(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) error
+	PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, error)
+	Anonymize(ctx context.Context, id string, password string) error
+	FindManyByIDs(ctx context.Context, userIDs []string) ([]*models.User, error)
+	FindManyDoctorsByIDs(ctx context.Context, doctorIDs []string) ([]*models.User, error)
+	FindManyPatientsByIDs(ctx context.Context, patientIDs []string) ([]*models.User, error)
+}
+
+type userRepository struct {
 	db *gorm.DB
 }
 
-func NewUserRepository(db *gorm.DB) *UserRepository {
-	return &UserRepository{
+func NewUserRepository(db *gorm.DB) UserRepository {
+	return &userRepository{
 		db: db,
 	}
 }
 
-func (r *UserRepository) Transaction(ctx context.Context, fn func(repo *UserRepository) (interface{}, error)) (interface{}, error) {
-	tx := r.db.Begin()
-	if tx.Error != nil {
-		return nil, tx.Error
-	}
-	repoWithTx := r.withTx(tx)
-
-	result, err := fn(repoWithTx)
-	if err != nil {
-		tx.Rollback()
-		return nil, err
-	}
-	if err := tx.Commit().Error; err != nil {
-		return nil, err
+func (r *userRepository) FindByID(ctx context.Context, id string) (*models.User, error) {
+	var user models.User
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&user).Error; err != nil {
+		return nil, translateError(err, "user")
 	}
-	return result, nil
+	return &user, nil
 }
 
-func (r *UserRepository) withTx(tx *gorm.DB) *UserRepository {
-	return &UserRepository{db: tx}
+func (r *userRepository) FindByIDForUpdate(ctx context.Context, id string) (*models.User, error) {
+	var user models.User
+	if err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", id).
+		First(&user).Error; err != nil {
+		return nil, translateError(err, "user")
+	}
+	return &user, nil
 }
 
-func (r *UserRepository) FindByID(ctx context.Context, id string) (*models.User, error) {
+// FindByIDUnscoped looks a user up regardless of soft deletion.
+func (r *userRepository) FindByIDUnscoped(ctx context.Context, id string) (*models.User, error) {
 	var user models.User
-	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&user).Error; err != nil {
-		return nil, err
+	if err := r.db.WithContext(ctx).Unscoped().Where("id = ?", id).First(&user).Error; err != nil {
+		return nil, translateError(err, "user")
 	}
 	return &user, nil
 }
 
-func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+// FindDeleted lists a page of soft-deleted users, most recently deleted
+// first, optionally filtered by role, together with the total count.
+func (r *userRepository) FindDeleted(ctx context.Context, role string, offset, limit int) ([]*models.User, int64, error) {
+	var users []*models.User
+	var total int64
+	query := r.db.WithContext(ctx).Unscoped().Model(&models.User{}).Where("deleted_at IS NOT NULL")
+	if role != "" {
+		query = query.Where("role = ?", role)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, translateError(err, "user")
+	}
+	if err := query.Order("deleted_at DESC").Offset(offset).Limit(limit).Find(&users).Error; err != nil {
+		return nil, 0, translateError(err, "user")
+	}
+	return users, total, nil
+}
+
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
 	var user models.User
 	if err := r.db.WithContext(ctx).Where("email = ?", email).First(&user).Error; err != nil {
-		return nil, err
+		return nil, translateError(err, "user")
 	}
 	return &user, nil
 }
 
-func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
+func (r *userRepository) Create(ctx context.Context, user *models.User) error {
 	if err := r.db.WithContext(ctx).Create(user).Error; err != nil {
-		return err
+		return translateError(err, "user")
 	}
 	return nil
 }
 
-func (r *UserRepository) Update(ctx context.Context, user *models.User) error {
-	if err := r.db.WithContext(ctx).Save(user).Error; err != nil {
-		return err
+// Update writes the named columns of user if it is still at user.Version,
+// and fails with a precondition error otherwise.
+func (r *userRepository) Update(ctx context.Context, user *models.User, columns ...string) error {
+	return updateVersioned(r.db.WithContext(ctx), user, &user.Version, &user.UpdatedAt, "user", columns)
+}
+
+func (r *userRepository) Delete(ctx context.Context, id string) error {
+	if err := r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.User{}).Error; err != nil {
+		return translateError(err, "user")
 	}
 	return nil
 }
 
-func (r *UserRepository) Delete(ctx context.Context, id string) error {
-	if err := r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.User{}).Error; err != nil {
-		return err
+func (r *userRepository) Restore(ctx context.Context, id string) error {
+	if err := r.db.WithContext(ctx).Unscoped().Model(&models.User{}).Where("id = ?", id).Update("deleted_at", nil).Error; err != nil {
+		return translateError(err, "user")
+	}
+	return nil
+}
+
+// PurgeDeleted hard-deletes users soft-deleted before cutoff; their role rows
+// go with them through ON DELETE CASCADE. Users with an account deletion
+// request are kept, since closed accounts are retained in anonymized form.
+func (r *userRepository) PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Unscoped().
+		Where("deleted_at < ?", cutoff).
+		Where("NOT EXISTS (SELECT 1 FROM account_deletion_requests r WHERE r.user_id = users.id)").
+		Delete(&models.User{})
+	if result.Error != nil {
+		return 0, translateError(result.Error, "user")
+	}
+	return result.RowsAffected, nil
+}
+
+// Anonymize irreversibly overwrites the personal data of a user, including a
+// soft-deleted one. The ID is kept so references held by other services stay valid.
+func (r *userRepository) Anonymize(ctx context.Context, id string, password string) error {
+	if err := r.db.WithContext(ctx).Unscoped().Model(&models.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"first_name":   "Deleted",
+		"last_name":    "User",
+		"phone_number": "",
+		"password":     password,
+		"version":      gorm.Expr("version + 1"),
+		"updated_at":   time.Now(),
+	}).Error; err != nil {
+		return translateError(err, "user")
 	}
 	return nil
 }
 
-func (r *UserRepository) FindManyByIDs(ctx context.Context, userIDs []string) ([]*models.User, error) {
+func (r *userRepository) FindManyByIDs(ctx context.Context, userIDs []string) ([]*models.User, error) {
 	var users []*models.User
 	if err := r.db.WithContext(ctx).Where("id IN ?", userIDs).Find(&users).Error; err != nil {
-		return nil, err
+		return nil, translateError(err, "user")
 	}
 	return users, nil
 }
 
-func (r *UserRepository) FindManyDoctorsByIDs(ctx context.Context, doctorIDs []string) ([]*models.User, error) {
+func (r *userRepository) FindManyDoctorsByIDs(ctx context.Context, doctorIDs []string) ([]*models.User, error) {
 	var users []*models.User
 	if err := r.db.WithContext(ctx).
 		Preload("Doctor").
 		Where("id IN ?", doctorIDs).
 		Where("role = ?", "doctor").
 		Find(&users).Error; err != nil {
-		return nil, err
+		return nil, translateError(err, "user")
 	}
 	return users, nil
 }
 
-func (r *UserRepository) FindManyPatientsByIDs(ctx context.Context, patientIDs []string) ([]*models.User, error) {
+func (r *userRepository) FindManyPatientsByIDs(ctx context.Context, patientIDs []string) ([]*models.User, error) {
 	var users []*models.User
 	if err := r.db.WithContext(ctx).
 		Preload("Patient").
 		Where("id IN ?", patientIDs).
 		Where("role = ?", "patient").
 		Find(&users).Error; err != nil {
-		return nil, err
+		return nil, translateError(err, "user")
 	}
 	return users, nil
 }
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// updateVersioned writes only the named columns of model, which must carry
+// its primary key, and only if the row is still at the version it was read
+// at. On success version is incremented and updatedAt set to match the row;
+// if another write got there first nothing is written and a StaleError is
+// returned. Updates takes a struct rather than a map so serializers such as
+// field encryption still apply.
+func updateVersioned(db *gorm.DB, model any, version *int64, updatedAt *time.Time, entity string, columns []string) error {
+	if len(columns) == 0 {
+		return nil
+	}
+	readVersion, readUpdatedAt := *version, *updatedAt
+	*version = readVersion + 1
+	*updatedAt = time.Now()
+
+	result := db.Model(model).
+		Where("version = ?", readVersion).
+		Select(append(columns, "version", "updated_at")).
+		Updates(model)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = StaleError(entity)
+	}
+	if result.Error != nil {
+		*version, *updatedAt = readVersion, readUpdatedAt
+		return translateError(result.Error, entity)
+	}
+	return nil
+}
@@ -1,55 +1,128 @@
 package response
 
-import "github.com/gofiber/fiber/v2"
+import (
+	"strings"
 
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// MIMEProblemJSON is the RFC 7807 media type. Clients that list it in
+// Accept ahead of application/json receive errors as problem details.
+const MIMEProblemJSON = "application/problem+json"
+
+// BaseResponse is the envelope of every JSON response: data on success,
+// error on failure, and meta on both.
 type BaseResponse struct {
-	Data interface{} `json:"data,omitempty"`
+	Data  interface{} `json:"data,omitempty"`
+	Error *ErrorBody  `json:"error,omitempty"`
+	Meta  *Meta       `json:"meta,omitempty"`
+}
+
+type Meta struct {
+	RequestID  string      `json:"request_id,omitempty"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+}
+
+type Pagination struct {
+	Page     int   `json:"page"`
+	PageSize int   `json:"page_size"`
+	Total    int64 `json:"total"`
 }
 
+type ErrorBody struct {
+	Code    string      `json:"code"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// ErrorResponse documents the failure envelope.
 type ErrorResponse struct {
-	Error string `json:"error"`
+	Error ErrorBody `json:"error"`
+	Meta  *Meta     `json:"meta,omitempty"`
+}
+
+// ProblemDetails is the RFC 7807 representation of an error.
+type ProblemDetails struct {
+	Type      string      `json:"type"`
+	Title     string      `json:"title"`
+	Status    int         `json:"status"`
+	Detail    string      `json:"detail,omitempty"`
+	Instance  string      `json:"instance,omitempty"`
+	Code      string      `json:"code"`
+	RequestID string      `json:"request_id,omitempty"`
+	Errors    interface{} `json:"errors,omitempty"`
 }
 
 func OK[T any](c *fiber.Ctx, data T) error {
-	return c.Status(fiber.StatusOK).JSON(data)
+	return c.Status(fiber.StatusOK).JSON(BaseResponse{Data: data, Meta: meta(c, nil)})
 }
 
 func Created[T any](c *fiber.Ctx, data T) error {
-	return c.Status(fiber.StatusCreated).JSON(data)
+	return c.Status(fiber.StatusCreated).JSON(BaseResponse{Data: data, Meta: meta(c, nil)})
+}
+
+// Paginated writes one page of a list along with its pagination meta.
+func Paginated[T any](c *fiber.Ctx, data T, pagination Pagination) error {
+	return c.Status(fiber.StatusOK).JSON(BaseResponse{Data: data, Meta: meta(c, &pagination)})
 }
 
 func BadRequest(c *fiber.Ctx, message string) error {
-	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-		"error": message,
-	})
+	return Failed(c, fiber.StatusBadRequest, message)
 }
 
 func Unauthorized(c *fiber.Ctx, message string) error {
-	return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-		"error": message,
-	})
+	return Failed(c, fiber.StatusUnauthorized, message)
 }
 
 func Forbidden(c *fiber.Ctx, message string) error {
-	return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-		"error": message,
-	})
+	return Failed(c, fiber.StatusForbidden, message)
 }
 
 func NotFound(c *fiber.Ctx, message string) error {
-	return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-		"error": message,
-	})
+	return Failed(c, fiber.StatusNotFound, message)
 }
 
 func InternalServerError(c *fiber.Ctx, message string) error {
-	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-		"error": message,
-	})
+	return Failed(c, fiber.StatusInternalServerError, message)
 }
 
+// Failed writes an error whose code is derived from the HTTP status,
+// e.g. 404 becomes NOT_FOUND.
 func Failed(c *fiber.Ctx, status int, message string) error {
-	return c.Status(status).JSON(fiber.Map{
-		"error": message,
+	return Error(c, status, codeForStatus(status), message, nil)
+}
+
+// Error writes an error with an explicit machine-readable code, as an
+// envelope or as problem+json depending on the Accept header.
+func Error(c *fiber.Ctx, status int, code, message string, details interface{}) error {
+	m := meta(c, nil)
+	if c.Accepts(fiber.MIMEApplicationJSON, MIMEProblemJSON) == MIMEProblemJSON {
+		c.Status(status)
+		return c.JSON(ProblemDetails{
+			Type:      "about:blank",
+			Title:     utils.StatusMessage(status),
+			Status:    status,
+			Detail:    message,
+			Instance:  c.OriginalURL(),
+			Code:      code,
+			RequestID: m.RequestID,
+			Errors:    details,
+		}, MIMEProblemJSON)
+	}
+	return c.Status(status).JSON(BaseResponse{
+		Error: &ErrorBody{Code: code, Message: message, Details: details},
+		Meta:  m,
 	})
 }
+
+func meta(c *fiber.Ctx, pagination *Pagination) *Meta {
+	return &Meta{
+		RequestID:  c.GetRespHeader(fiber.HeaderXRequestID),
+		Pagination: pagination,
+	}
+}
+
+func codeForStatus(status int) string {
+	return strings.ToUpper(strings.ReplaceAll(utils.StatusMessage(status), " ", "_"))
+}
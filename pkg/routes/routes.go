@@ -4,15 +4,21 @@ import (
 	// "user-service/pkg/context"
 	// "user-service/pkg/dto"
 	_ "user-service/docs"
+	"user-service/pkg/constants"
 	"user-service/pkg/handlers"
 	"user-service/pkg/jwt"
 	"user-service/pkg/middleware"
+	service "user-service/pkg/services"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/swagger"
 )
 
-func SetupRoutes(app *fiber.App, userHandler *handlers.UserHandler, jwtSvc *jwt.JwtService) {
+func SetupRoutes(app *fiber.App, userHandler *handlers.UserHandler, accountHandler *handlers.AccountHandler, retentionHandler *handlers.RetentionHandler, auditHandler *handlers.AuditHandler, importHandler *handlers.ImportHandler, exportHandler *handlers.ExportHandler, fhirHandler *handlers.FHIRHandler, healthHandler *handlers.HealthHandler, auditService *service.AuditService, jwtSvc *jwt.JwtService) {
+
+	app.Get("/healthz", healthHandler.Healthz)
+	app.Get("/readyz", healthHandler.Readyz)
+	app.Get("/version", healthHandler.Version)
 
 	api := app.Group("/api")
 	user := api.Group("/user")
@@ -21,15 +27,92 @@ func SetupRoutes(app *fiber.App, userHandler *handlers.UserHandler, jwtSvc *jwt.
 	v1 := user.Group("/v1")
 
 	v1.Post("/patient/register",
+		middleware.Audit(auditService, "patient.register"),
 		userHandler.PatientRegister) // TODO add validation middleware
 	v1.Post("/patient/login", userHandler.PatientLogin)
 	v1.Post("/doctor/login", userHandler.DoctorLogin)
+	v1.Post("/admin/login", userHandler.AdminLogin)
+
+	// The FHIR API answers refused requests and audit failures with an
+	// OperationOutcome too, so it checks tokens and roles itself and is
+	// registered before v1's JWT middleware.
+	fhir := v1.Group("/fhir", middleware.JwtMiddlewareWith(jwtSvc, handlers.WriteOperationOutcome))
+	fhir.Get("/Patient",
+		middleware.AuditWith(auditService, handlers.WriteOperationOutcome, "patient.fhir.search"),
+		middleware.RequireRoleWith(handlers.WriteOperationOutcome, constants.RoleDoctor, constants.RoleAdmin),
+		fhirHandler.SearchPatients)
+	fhir.Get("/Patient/:id",
+		middleware.AuditWith(auditService, handlers.WriteOperationOutcome, "patient.fhir.read"),
+		middleware.RequireRoleWith(handlers.WriteOperationOutcome, constants.RoleDoctor, constants.RoleAdmin),
+		fhirHandler.ReadPatient)
+	fhir.Get("/Practitioner", fhirHandler.SearchPractitioners)
+	fhir.Get("/Practitioner/:id", fhirHandler.ReadPractitioner)
+	fhir.Get("/PractitionerRole", fhirHandler.SearchPractitionerRoles)
+	fhir.Get("/PractitionerRole/:id", fhirHandler.ReadPractitionerRole)
 
 	v1.Use(middleware.JwtMiddleware(jwtSvc))
-	v1.Get("/patient/me", userHandler.Profile)
-	v1.Patch("/patient", userHandler.UpdatePatientProfile)
+	v1.Get("/patient/me",
+		middleware.AuditSelf(auditService, "patient.read"),
+		userHandler.Profile)
+	v1.Patch("/patient",
+		middleware.AuditSelf(auditService, "patient.update"),
+		userHandler.UpdatePatientProfile)
+	v1.Post("/patient/deletion-request",
+		middleware.AuditSelf(auditService, "patient.deletion_request"),
+		middleware.RequireRole(constants.RolePatient),
+		accountHandler.RequestAccountDeletion)
+	v1.Get("/patient/me/export",
+		middleware.AuditSelf(auditService, "patient.export"),
+		middleware.RequireRole(constants.RolePatient),
+		exportHandler.ExportData)
 
 	v1.Get("/doctors", userHandler.GetAllDoctors)
+	v1.Get("/doctors/search", userHandler.SearchDoctors)
 	v1.Post("/doctors", userHandler.GetDoctorByIDs)
-	v1.Post("/patients", userHandler.GetPatientByIDs)
+	v1.Post("/patients",
+		middleware.Audit(auditService, "patient.read_batch"),
+		userHandler.GetPatientByIDs)
+	v1.Get("/patients/search",
+		middleware.Audit(auditService, "patient.search"),
+		middleware.RequireRole(constants.RoleDoctor, constants.RoleAdmin),
+		userHandler.SearchPatients)
+	v1.Get("/patients/:id/history",
+		middleware.Audit(auditService, "patient.history"),
+		middleware.RequireRole(constants.RolePatient, constants.RoleDoctor, constants.RoleAdmin),
+		userHandler.GetPatientProfileHistory)
+
+	admin := v1.Group("/admin", middleware.RequireRole(constants.RoleAdmin))
+	admin.Get("/deletion-requests",
+		middleware.Audit(auditService, "account.deletion_requests.list"),
+		accountHandler.ListDeletionRequests)
+	admin.Post("/deletion-requests/anonymize",
+		middleware.Audit(auditService, "account.anonymize"),
+		accountHandler.AnonymizeExpiredAccounts)
+	admin.Post("/deletion-requests/:id/approve",
+		middleware.Audit(auditService, "account.deletion_request.approve"),
+		accountHandler.ApproveDeletionRequest)
+	admin.Post("/deletion-requests/:id/reject",
+		middleware.Audit(auditService, "account.deletion_request.reject"),
+		accountHandler.RejectDeletionRequest)
+	admin.Get("/users/deleted",
+		middleware.Audit(auditService, "user.deleted.list"),
+		retentionHandler.ListDeletedUsers)
+	admin.Post("/users/:id/restore",
+		middleware.Audit(auditService, "user.restore"),
+		retentionHandler.RestoreUser)
+	admin.Post("/patients/import",
+		middleware.Audit(auditService, "patient.import"),
+		importHandler.ImportPatients)
+	admin.Put("/patients/:id/password",
+		middleware.Audit(auditService, "patient.password.set"),
+		userHandler.SetPatientPassword)
+	admin.Get("/audit-log",
+		middleware.Audit(auditService, "audit_log.list"),
+		auditHandler.ListAuditLog)
+	admin.Get("/audit-log/export",
+		middleware.Audit(auditService, "audit_log.export"),
+		auditHandler.ExportAuditLog)
+	admin.Get("/audit-log/verify",
+		middleware.Audit(auditService, "audit_log.verify"),
+		auditHandler.VerifyAuditLog)
 }
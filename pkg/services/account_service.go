@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"user-service/pkg/apperr"
+	contextUtils "user-service/pkg/context"
+	"user-service/pkg/dto"
+	"user-service/pkg/models"
+	"user-service/pkg/repository"
+	"user-service/pkg/utils"
+
+	"github.com/google/uuid"
+)
+
+// AccountService implements patient account closure: a patient requests
+// deletion, an admin approves it (soft-deleting the account) and, once the
+// retention period has passed, the personal data is anonymized in place.
+type AccountService struct {
+	uow                repository.UnitOfWork
+	deletionRepository repository.AccountDeletionRepository
+	retention          time.Duration
+}
+
+func NewAccountService(
+	uow repository.UnitOfWork,
+	deletionRepo repository.AccountDeletionRepository,
+	retention time.Duration,
+) *AccountService {
+	return &AccountService{
+		uow:                uow,
+		deletionRepository: deletionRepo,
+		retention:          retention,
+	}
+}
+
+func (s *AccountService) RequestDeletion(ctx context.Context, body *dto.RequestAccountDeletionRequestDto) (*dto.AccountDeletionRequestResponseDto, error) {
+	userID, err := authenticatedUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.deletionRepository.FindOpenByUserID(ctx, userID.String())
+	if err == nil {
+		return nil, apperr.New(apperr.CodeConflict, "account deletion already requested", nil)
+	}
+	if !apperr.IsCode(err, apperr.CodeNotFound) {
+		return nil, apperr.Wrap(err, apperr.CodeInternal, "failed to find deletion request")
+	}
+
+	request := &models.AccountDeletionRequest{
+		ID:          utils.GenerateUUIDv7(),
+		UserID:      userID,
+		Status:      models.DeletionRequestPending,
+		Reason:      body.Reason,
+		RequestedAt: time.Now(),
+	}
+	err = auditedDo(ctx, s.uow, func(tx repository.UnitOfWork) error {
+		if err := tx.AccountDeletions().Create(ctx, request); err != nil {
+			return apperr.Wrap(err, apperr.CodeInternal, "create deletion request failed")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toDeletionRequestDto(request), nil
+}
+
+func (s *AccountService) ListDeletionRequests(ctx context.Context, status string, query *dto.PageQueryDto) (*dto.PageDto[*dto.AccountDeletionRequestResponseDto], error) {
+	switch models.DeletionRequestStatus(status) {
+	case "", models.DeletionRequestPending, models.DeletionRequestApproved, models.DeletionRequestRejected:
+	default:
+		return nil, apperr.New(apperr.CodeBadRequest, "invalid status filter", nil)
+	}
+
+	page, pageSize, offset := normalizePage(query)
+	requests, total, err := s.deletionRepository.FindAll(ctx, status, offset, pageSize)
+	if err != nil {
+		return nil, apperr.Wrap(err, apperr.CodeInternal, "failed to find deletion requests")
+	}
+	items := make([]*dto.AccountDeletionRequestResponseDto, 0, len(requests))
+	for _, request := range requests {
+		items = append(items, toDeletionRequestDto(request))
+	}
+	return &dto.PageDto[*dto.AccountDeletionRequestResponseDto]{Items: items, Page: page, PageSize: pageSize, Total: total}, nil
+}
+
+// ApproveDeletion marks a pending request approved and soft-deletes the
+// patient and user rows in the same transaction.
+func (s *AccountService) ApproveDeletion(ctx context.Context, requestID string, body *dto.ReviewAccountDeletionRequestDto) (*dto.AccountDeletionRequestResponseDto, error) {
+	return s.review(ctx, requestID, body, models.DeletionRequestApproved)
+}
+
+func (s *AccountService) RejectDeletion(ctx context.Context, requestID string, body *dto.ReviewAccountDeletionRequestDto) (*dto.AccountDeletionRequestResponseDto, error) {
+	return s.review(ctx, requestID, body, models.DeletionRequestRejected)
+}
+
+func (s *AccountService) review(ctx context.Context, requestID string, body *dto.ReviewAccountDeletionRequestDto, status models.DeletionRequestStatus) (*dto.AccountDeletionRequestResponseDto, error) {
+	reviewerID, err := authenticatedUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := uuid.Parse(requestID); err != nil {
+		return nil, apperr.New(apperr.CodeBadRequest, "invalid deletion request id", err)
+	}
+
+	var request *models.AccountDeletionRequest
+	err = auditedDo(ctx, s.uow, func(tx repository.UnitOfWork) error {
+		var err error
+		request, err = tx.AccountDeletions().FindByID(ctx, requestID)
+		if err != nil {
+			return apperr.Wrap(err, apperr.CodeInternal, "failed to find deletion request")
+		}
+		setAuditTargets(ctx, request.UserID.String())
+		if request.Status != models.DeletionRequestPending {
+			return apperr.New(apperr.CodeConflict, "deletion request already reviewed", nil)
+		}
+
+		now := time.Now()
+		request.Status = status
+		request.ReviewedBy = &reviewerID
+		request.ReviewedAt = &now
+		request.ReviewNote = body.Note
+		if err := tx.AccountDeletions().Update(ctx, request); err != nil {
+			return apperr.Wrap(err, apperr.CodeInternal, "update deletion request failed")
+		}
+
+		if status != models.DeletionRequestApproved {
+			return nil
+		}
+		if err := tx.Patients().Delete(ctx, request.UserID.String()); err != nil {
+			return apperr.Wrap(err, apperr.CodeInternal, "delete patient failed")
+		}
+		if err := tx.Users().Delete(ctx, request.UserID.String()); err != nil {
+			return apperr.Wrap(err, apperr.CodeInternal, "delete user failed")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toDeletionRequestDto(request), nil
+}
+
+// AnonymizeExpired anonymizes every approved account whose retention period
+// has elapsed and returns how many accounts were processed.
+func (s *AccountService) AnonymizeExpired(ctx context.Context) (int, error) {
+	requests, err := s.deletionRepository.FindDueForAnonymization(ctx, time.Now().Add(-s.retention))
+	if err != nil {
+		return 0, apperr.Wrap(err, apperr.CodeInternal, "failed to find accounts due for anonymization")
+	}
+
+	count := 0
+	for _, request := range requests {
+		if err := s.anonymize(ctx, request); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+func (s *AccountService) anonymize(ctx context.Context, request *models.AccountDeletionRequest) error {
+	// Replace the password with a hash of random bytes nobody knows, so the
+	// row can never be logged into even if it were restored.
+	secret, err := utils.GenerateRandomByte(32)
+	if err != nil {
+		return apperr.New(apperr.CodeInternal, "generate random password failed", err)
+	}
+	password, err := utils.HashPassword(string(secret))
+	if err != nil {
+		return apperr.New(apperr.CodeInternal, "hash password failed", err)
+	}
+
+	userID := request.UserID.String()
+	setAuditTargets(ctx, userID)
+	return auditedDo(ctx, s.uow, func(tx repository.UnitOfWork) error {
+		if err := tx.Users().Anonymize(ctx, userID, password); err != nil {
+			return apperr.Wrap(err, apperr.CodeInternal, "anonymize user failed")
+		}
+		if err := tx.Patients().Anonymize(ctx, userID); err != nil {
+			return apperr.Wrap(err, apperr.CodeInternal, "anonymize patient failed")
+		}
+		if err := tx.ProfileChanges().Anonymize(ctx, userID); err != nil {
+			return apperr.Wrap(err, apperr.CodeInternal, "anonymize profile history failed")
+		}
+		now := time.Now()
+		if err := tx.ProfileChanges().Create(ctx, erasureChanges(request, now)); err != nil {
+			return apperr.Wrap(err, apperr.CodeInternal, "record profile history failed")
+		}
+		request.AnonymizedAt = &now
+		if err := tx.AccountDeletions().Update(ctx, request); err != nil {
+			return apperr.Wrap(err, apperr.CodeInternal, "update deletion request failed")
+		}
+		return nil
+	})
+}
+
+// erasureChanges records the anonymization of each field. The old values
+// are erased rather than recorded, and the worker acts for the admin who
+// approved the request.
+func erasureChanges(request *models.AccountDeletionRequest, now time.Time) []*models.ProfileChange {
+	changes := make([]*models.ProfileChange, 0, len(repository.AnonymizedFields))
+	for _, field := range repository.AnonymizedFields {
+		changes = append(changes, &models.ProfileChange{
+			ID:        utils.GenerateUUIDv7(),
+			UserID:    request.UserID,
+			Field:     field,
+			ActorID:   request.ReviewedBy,
+			ActorRole: models.AdminRole,
+			ChangedAt: now,
+		})
+	}
+	return changes
+}
+
+// RunErasureWorker calls AnonymizeExpired every interval until ctx is done.
+func (s *AccountService) RunErasureWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			count, err := s.AnonymizeExpired(ctx)
+			if err != nil {
+				slog.ErrorContext(ctx, "account erasure failed", "error", err)
+			}
+			if count > 0 {
+				slog.InfoContext(ctx, "anonymized closed accounts", "count", count)
+			}
+		}
+	}
+}
+
+// authenticatedUserID returns the ID of the user the request was made by.
+func authenticatedUserID(ctx context.Context) (uuid.UUID, error) {
+	id, err := uuid.Parse(contextUtils.GetUserId(ctx))
+	if err != nil {
+		return uuid.Nil, apperr.New(apperr.CodeUnauthorized, "invalid token", err)
+	}
+	return id, nil
+}
+
+func toDeletionRequestDto(request *models.AccountDeletionRequest) *dto.AccountDeletionRequestResponseDto {
+	res := &dto.AccountDeletionRequestResponseDto{
+		ID:           request.ID.String(),
+		UserID:       request.UserID.String(),
+		Status:       string(request.Status),
+		Reason:       request.Reason,
+		ReviewNote:   request.ReviewNote,
+		RequestedAt:  request.RequestedAt,
+		ReviewedAt:   request.ReviewedAt,
+		AnonymizedAt: request.AnonymizedAt,
+	}
+	if request.ReviewedBy != nil {
+		reviewedBy := request.ReviewedBy.String()
+		res.ReviewedBy = &reviewedBy
+	}
+	return res
+}
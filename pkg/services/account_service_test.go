@@ -0,0 +1,147 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"user-service/pkg/apperr"
+	"user-service/pkg/dto"
+	"user-service/pkg/models"
+	service "user-service/pkg/services"
+
+	"github.com/google/uuid"
+)
+
+func TestRequestDeletion(t *testing.T) {
+	f := newFixture(t)
+	userID := f.register(t, "HN0001", nil)
+	svc := service.NewAccountService(f.store, f.store.AccountDeletions(), 0)
+
+	request, err := svc.RequestDeletion(asUser(userID), &dto.RequestAccountDeletionRequestDto{Reason: ptr("Moving abroad")})
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	if request.UserID != userID || request.Status != string(models.DeletionRequestPending) || deref(request.Reason) != "Moving abroad" {
+		t.Errorf("request = %+v, want a pending request of %s", request, userID)
+	}
+
+	_, err = svc.RequestDeletion(asUser(userID), &dto.RequestAccountDeletionRequestDto{})
+	assertCode(t, err, apperr.CodeConflict)
+	_, err = svc.RequestDeletion(context.Background(), &dto.RequestAccountDeletionRequestDto{})
+	assertCode(t, err, apperr.CodeUnauthorized)
+}
+
+func TestReviewDeletion(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	approvedID := f.register(t, "HN0001", nil)
+	rejectedID := f.register(t, "HN0002", nil)
+	adminID := uuid.NewString()
+	admin := asRole(adminID, string(models.AdminRole))
+	svc := service.NewAccountService(f.store, f.store.AccountDeletions(), 0)
+
+	toApprove, err := svc.RequestDeletion(asUser(approvedID), &dto.RequestAccountDeletionRequestDto{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	toReject, err := svc.RequestDeletion(asUser(rejectedID), &dto.RequestAccountDeletionRequestDto{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	approved, err := svc.ApproveDeletion(admin, toApprove.ID, &dto.ReviewAccountDeletionRequestDto{Note: ptr("Verified by phone")})
+	if err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	if approved.Status != string(models.DeletionRequestApproved) || deref(approved.ReviewedBy) != adminID || approved.ReviewedAt == nil {
+		t.Errorf("approved = %+v, want approved by %s", approved, adminID)
+	}
+	if _, err := f.store.Users().FindByID(ctx, approvedID); !apperr.IsCode(err, apperr.CodeNotFound) {
+		t.Errorf("approved user lookup = %v, want the user soft-deleted", err)
+	}
+
+	rejected, err := svc.RejectDeletion(admin, toReject.ID, &dto.ReviewAccountDeletionRequestDto{})
+	if err != nil {
+		t.Fatalf("reject: %v", err)
+	}
+	if rejected.Status != string(models.DeletionRequestRejected) {
+		t.Errorf("rejected = %+v, want rejected", rejected)
+	}
+	if _, err := f.store.Users().FindByID(ctx, rejectedID); err != nil {
+		t.Errorf("rejected user lookup = %v, want the user kept", err)
+	}
+	if _, err := svc.RequestDeletion(asUser(rejectedID), &dto.RequestAccountDeletionRequestDto{}); err != nil {
+		t.Errorf("request after a rejection = %v, want a new request", err)
+	}
+
+	_, err = svc.RejectDeletion(admin, toApprove.ID, &dto.ReviewAccountDeletionRequestDto{})
+	assertCode(t, err, apperr.CodeConflict)
+	_, err = svc.ApproveDeletion(admin, "not-a-uuid", &dto.ReviewAccountDeletionRequestDto{})
+	assertCode(t, err, apperr.CodeBadRequest)
+	_, err = svc.ApproveDeletion(admin, uuid.NewString(), &dto.ReviewAccountDeletionRequestDto{})
+	assertCode(t, err, apperr.CodeNotFound)
+	_, err = svc.ApproveDeletion(context.Background(), toReject.ID, &dto.ReviewAccountDeletionRequestDto{})
+	assertCode(t, err, apperr.CodeUnauthorized)
+}
+
+func TestAnonymizeExpired(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	userID := f.register(t, "HN0001", ptr(testIDCard))
+	keptID := f.register(t, "HN0002", nil)
+	adminID := uuid.NewString()
+	admin := asRole(adminID, string(models.AdminRole))
+	svc := service.NewAccountService(f.store, f.store.AccountDeletions(), 0)
+	before, err := f.store.Users().FindByID(ctx, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request, err := svc.RequestDeletion(asUser(userID), &dto.RequestAccountDeletionRequestDto{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.RequestDeletion(asUser(keptID), &dto.RequestAccountDeletionRequestDto{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.ApproveDeletion(admin, request.ID, &dto.ReviewAccountDeletionRequestDto{}); err != nil {
+		t.Fatal(err)
+	}
+
+	count, err := svc.AnonymizeExpired(ctx)
+	if err != nil || count != 1 {
+		t.Fatalf("anonymize = %d (%v), want 1 account", count, err)
+	}
+	user, err := f.store.Users().FindByIDUnscoped(ctx, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.FirstName != "Deleted" || user.PhoneNumber != "" || user.Password == before.Password {
+		t.Errorf("user = %+v, want the personal data and password replaced", user)
+	}
+	history, _, err := f.store.ProfileChanges().FindByUserID(ctx, userID, 0, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	erased := map[string]bool{}
+	for _, change := range history {
+		if change.OldValue != nil || change.NewValue != nil || change.ActorRole != models.AdminRole || change.ActorID == nil || change.ActorID.String() != adminID {
+			t.Errorf("history entry %+v, want an erasure by %s without values", change, adminID)
+		}
+		erased[change.Field] = true
+	}
+	if !erased["first_name"] || !erased["id_card_number"] || len(erased) != len(history) {
+		t.Errorf("history = %d entries for %v, want one per anonymized field", len(history), erased)
+	}
+	kept, err := f.store.Users().FindByID(ctx, keptID)
+	if err != nil || kept.FirstName != "Somchai" {
+		t.Errorf("pending user = %+v (%v), want it untouched", kept, err)
+	}
+
+	page, err := svc.ListDeletionRequests(ctx, string(models.DeletionRequestApproved), &dto.PageQueryDto{})
+	if err != nil || len(page.Items) != 1 || page.Items[0].AnonymizedAt == nil {
+		t.Errorf("approved requests = %+v (%v), want one anonymized", page, err)
+	}
+	if count, err := svc.AnonymizeExpired(ctx); err != nil || count != 0 {
+		t.Errorf("second run = %d (%v), want nothing left", count, err)
+	}
+}
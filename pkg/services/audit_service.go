@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+	"user-service/pkg/apperr"
+	"user-service/pkg/dto"
+	"user-service/pkg/models"
+	"user-service/pkg/repository"
+
+	"github.com/google/uuid"
+)
+
+// auditBatchSize is the number of entries read per query when exporting or
+// verifying the whole log.
+const auditBatchSize = 500
+
+// errChainBroken stops Verify at the first entry that fails to verify.
+var errChainBroken = errors.New("audit chain broken")
+
+// AuditService records access to patient data in the hash-chained audit log
+// and lets admins query, export and verify it.
+type AuditService struct {
+	auditRepository repository.AuditRepository
+}
+
+func NewAuditService(auditRepo repository.AuditRepository) *AuditService {
+	return &AuditService{
+		auditRepository: auditRepo,
+	}
+}
+
+// Record appends entry to the log, stamping it with the current time.
+func (s *AuditService) Record(ctx context.Context, entry *models.AuditEntry) error {
+	entry.OccurredAt = time.Now().UTC().Truncate(time.Microsecond)
+	if err := s.auditRepository.Append(ctx, entry); err != nil {
+		return apperr.Wrap(err, apperr.CodeInternal, "append audit entry failed")
+	}
+	return nil
+}
+
+// pendingAudit is the audit entry of a request that has not been written
+// yet. Services that change patient data append a copy in the transaction
+// that makes the change; see auditedDo.
+type pendingAudit struct {
+	entry    *models.AuditEntry
+	recorded bool
+}
+
+type pendingAuditKey struct{}
+
+// WithPendingAudit returns a copy of ctx carrying entry, the audit entry of
+// the request ctx belongs to. Outcome and OccurredAt are filled in when the
+// entry is written.
+func WithPendingAudit(ctx context.Context, entry *models.AuditEntry) context.Context {
+	return context.WithValue(ctx, pendingAuditKey{}, &pendingAudit{entry: entry})
+}
+
+// AuditRecorded reports whether the pending audit entry of ctx was written
+// together with the changes the request made.
+func AuditRecorded(ctx context.Context) bool {
+	pending, _ := ctx.Value(pendingAuditKey{}).(*pendingAudit)
+	return pending != nil && pending.recorded
+}
+
+// setAuditTargets sets the patients the pending audit entry of ctx targets,
+// for the writes that follow.
+func setAuditTargets(ctx context.Context, ids ...string) {
+	if pending, ok := ctx.Value(pendingAuditKey{}).(*pendingAudit); ok {
+		pending.entry.TargetIDs = append(models.IDList{}, ids...)
+	}
+}
+
+// auditedDo is uow.Do for a request that changes patient data: once fn
+// succeeds, a copy of the request's pending audit entry is appended in the
+// same transaction, so the change is never committed without its record.
+// Outside a request it is uow.Do.
+func auditedDo(ctx context.Context, uow repository.UnitOfWork, fn func(tx repository.UnitOfWork) error) error {
+	pending, ok := ctx.Value(pendingAuditKey{}).(*pendingAudit)
+	if !ok {
+		return uow.Do(ctx, fn)
+	}
+	err := uow.Do(ctx, func(tx repository.UnitOfWork) error {
+		if err := fn(tx); err != nil {
+			return err
+		}
+		entry := *pending.entry
+		entry.TargetIDs = append(models.IDList{}, pending.entry.TargetIDs...)
+		entry.Outcome = models.AuditSuccess
+		entry.OccurredAt = time.Now().UTC().Truncate(time.Microsecond)
+		if err := tx.AuditLog().Append(ctx, &entry); err != nil {
+			return apperr.Wrap(err, apperr.CodeInternal, "append audit entry failed")
+		}
+		return nil
+	})
+	if err == nil {
+		pending.recorded = true
+	}
+	return err
+}
+
+func (s *AuditService) List(ctx context.Context, query *dto.AuditLogQueryDto) (*dto.PageDto[*dto.AuditEntryResponseDto], error) {
+	filter, err := auditFilter(query)
+	if err != nil {
+		return nil, err
+	}
+	page, pageSize, offset := normalizePage(&dto.PageQueryDto{Page: query.Page, PageSize: query.PageSize})
+	entries, total, err := s.auditRepository.Find(ctx, filter, offset, pageSize)
+	if err != nil {
+		return nil, apperr.Wrap(err, apperr.CodeInternal, "failed to find audit entries")
+	}
+	items := make([]*dto.AuditEntryResponseDto, 0, len(entries))
+	for _, entry := range entries {
+		items = append(items, toAuditEntryDto(entry))
+	}
+	return &dto.PageDto[*dto.AuditEntryResponseDto]{Items: items, Page: page, PageSize: pageSize, Total: total}, nil
+}
+
+// Export writes every matching entry, oldest first, to w as JSON lines. The
+// hashes are included so the export can be checked independently.
+func (s *AuditService) Export(ctx context.Context, query *dto.AuditLogQueryDto, w io.Writer) error {
+	filter, err := auditFilter(query)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	return s.each(ctx, filter, func(entry *models.AuditEntry) error {
+		return enc.Encode(toAuditEntryDto(entry))
+	})
+}
+
+// Verify walks the whole log and checks that every entry links to the one
+// before it and that its content still matches its hash.
+func (s *AuditService) Verify(ctx context.Context) (*dto.AuditVerifyResponseDto, error) {
+	res := &dto.AuditVerifyResponseDto{Valid: true}
+	prevHash := ""
+	err := s.each(ctx, repository.AuditFilter{}, func(entry *models.AuditEntry) error {
+		res.Checked++
+		if entry.PrevHash != prevHash || entry.Hash != entry.ComputeHash() {
+			res.Valid = false
+			res.BrokenAt = &entry.ID
+			return errChainBroken
+		}
+		prevHash = entry.Hash
+		return nil
+	})
+	if err != nil && !errors.Is(err, errChainBroken) {
+		return nil, err
+	}
+	return res, nil
+}
+
+// each calls fn for every entry matching filter, oldest first.
+func (s *AuditService) each(ctx context.Context, filter repository.AuditFilter, fn func(*models.AuditEntry) error) error {
+	var after int64
+	for {
+		entries, err := s.auditRepository.FindAfter(ctx, filter, after, auditBatchSize)
+		if err != nil {
+			return apperr.Wrap(err, apperr.CodeInternal, "failed to read audit entries")
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+		for _, entry := range entries {
+			if err := fn(entry); err != nil {
+				return err
+			}
+			after = entry.ID
+		}
+	}
+}
+
+func auditFilter(query *dto.AuditLogQueryDto) (repository.AuditFilter, error) {
+	filter := repository.AuditFilter{
+		ActorID:   query.ActorID,
+		PatientID: query.PatientID,
+		Action:    query.Action,
+	}
+	for _, id := range []string{query.ActorID, query.PatientID} {
+		if id == "" {
+			continue
+		}
+		if _, err := uuid.Parse(id); err != nil {
+			return filter, apperr.New(apperr.CodeBadRequest, "invalid user id "+id, err)
+		}
+	}
+	for _, bound := range []struct {
+		value string
+		dest  **time.Time
+	}{{query.From, &filter.From}, {query.To, &filter.To}} {
+		if bound.value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, bound.value)
+		if err != nil {
+			return filter, apperr.New(apperr.CodeBadRequest, "from and to must be RFC 3339 timestamps", err)
+		}
+		*bound.dest = &t
+	}
+	return filter, nil
+}
+
+func toAuditEntryDto(entry *models.AuditEntry) *dto.AuditEntryResponseDto {
+	res := &dto.AuditEntryResponseDto{
+		ID:         entry.ID,
+		OccurredAt: entry.OccurredAt,
+		ActorRole:  entry.ActorRole,
+		Action:     entry.Action,
+		TargetIDs:  append([]string{}, entry.TargetIDs...),
+		RequestID:  entry.RequestID,
+		IP:         entry.IP,
+		Outcome:    string(entry.Outcome),
+		Status:     entry.Status,
+		PrevHash:   entry.PrevHash,
+		Hash:       entry.Hash,
+	}
+	if entry.ActorID != nil {
+		actorID := entry.ActorID.String()
+		res.ActorID = &actorID
+	}
+	return res
+}
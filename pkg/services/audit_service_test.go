@@ -0,0 +1,146 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"user-service/pkg/apperr"
+	"user-service/pkg/dto"
+	"user-service/pkg/models"
+	"user-service/pkg/repository"
+	"user-service/pkg/repository/memory"
+	service "user-service/pkg/services"
+
+	"github.com/google/uuid"
+)
+
+// tamperedAuditRepository returns entries as if a row had been edited in
+// the database behind the application's back.
+type tamperedAuditRepository struct {
+	repository.AuditRepository
+	id int64
+}
+
+func (r *tamperedAuditRepository) FindAfter(ctx context.Context, filter repository.AuditFilter, afterID int64, limit int) ([]*models.AuditEntry, error) {
+	entries, err := r.AuditRepository.FindAfter(ctx, filter, afterID, limit)
+	for _, entry := range entries {
+		if entry.ID == r.id {
+			entry.TargetIDs = models.IDList{}
+		}
+	}
+	return entries, err
+}
+
+func recordAccess(t *testing.T, svc *service.AuditService, actorID uuid.UUID, action string, targets ...string) {
+	t.Helper()
+	err := svc.Record(context.Background(), &models.AuditEntry{
+		ActorID:   &actorID,
+		ActorRole: "doctor",
+		Action:    action,
+		TargetIDs: targets,
+		Outcome:   models.AuditSuccess,
+		Status:    200,
+	})
+	if err != nil {
+		t.Fatalf("record: %v", err)
+	}
+}
+
+func TestAuditLogChainsEntries(t *testing.T) {
+	store := memory.NewStore()
+	svc := service.NewAuditService(store.AuditLog())
+	doctorID, patientID, otherID := uuid.New(), uuid.NewString(), uuid.NewString()
+
+	recordAccess(t, svc, doctorID, "patient.read_batch", patientID, otherID)
+	recordAccess(t, svc, doctorID, "patient.search", otherID)
+	recordAccess(t, svc, uuid.New(), "patient.read", patientID)
+
+	page, err := svc.List(context.Background(), &dto.AuditLogQueryDto{PatientID: patientID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 2 || page.Items[0].Action != "patient.read" || page.Items[1].Action != "patient.read_batch" {
+		t.Fatalf("entries for patient = %+v, want read then read_batch", page.Items)
+	}
+
+	var export bytes.Buffer
+	if err := svc.Export(context.Background(), &dto.AuditLogQueryDto{ActorID: doctorID.String()}, &export); err != nil {
+		t.Fatal(err)
+	}
+	var entries []dto.AuditEntryResponseDto
+	for dec := json.NewDecoder(&export); dec.More(); {
+		var entry dto.AuditEntryResponseDto
+		if err := dec.Decode(&entry); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) != 2 || entries[0].PrevHash != "" || entries[1].PrevHash != entries[0].Hash {
+		t.Fatalf("exported entries are not chained: %+v", entries)
+	}
+
+	res, err := svc.Verify(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Valid || res.Checked != 3 {
+		t.Errorf("verify = %+v, want 3 valid entries", res)
+	}
+
+	res, err = service.NewAuditService(&tamperedAuditRepository{store.AuditLog(), 2}).Verify(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Valid || res.BrokenAt == nil || *res.BrokenAt != 2 {
+		t.Errorf("verify of tampered log = %+v, want broken at entry 2", res)
+	}
+}
+
+func TestAuditLogRejectsInvalidFilters(t *testing.T) {
+	svc := service.NewAuditService(memory.NewStore().AuditLog())
+	for _, query := range []*dto.AuditLogQueryDto{{ActorID: "nope"}, {From: "yesterday"}} {
+		_, err := svc.List(context.Background(), query)
+		assertCode(t, err, apperr.CodeBadRequest)
+	}
+}
+
+func TestAuditEntryCommitsWithChange(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	userID := f.register(t, "HN0001", nil)
+	svc := service.NewRetentionService(f.store, f.store.Users(), 0)
+	restore := func() (context.Context, error) {
+		adminID := uuid.New()
+		ctx := service.WithPendingAudit(asRole(adminID.String(), string(models.AdminRole)), &models.AuditEntry{
+			ActorID:   &adminID,
+			ActorRole: string(models.AdminRole),
+			Action:    "user.restore",
+			TargetIDs: models.IDList{userID},
+			Status:    200,
+		})
+		_, err := svc.RestoreUser(ctx, userID)
+		return ctx, err
+	}
+
+	failed, err := restore()
+	assertCode(t, err, apperr.CodeConflict)
+	if _, total, _ := f.store.AuditLog().Find(ctx, repository.AuditFilter{}, 0, 10); total != 0 || service.AuditRecorded(failed) {
+		t.Errorf("failed restore wrote %d audit entries, want none", total)
+	}
+
+	if err := f.store.Users().Delete(ctx, userID); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := restore()
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	entries, total, err := f.store.AuditLog().Find(ctx, repository.AuditFilter{}, 0, 10)
+	if err != nil || total != 1 || !service.AuditRecorded(restored) {
+		t.Fatalf("restore wrote %d audit entries (%v), want one", total, err)
+	}
+	if entries[0].Outcome != models.AuditSuccess || entries[0].Action != "user.restore" || len(entries[0].TargetIDs) != 1 || entries[0].TargetIDs[0] != userID || entries[0].OccurredAt.IsZero() {
+		t.Errorf("entry = %+v, want a successful restore of %s", entries[0], userID)
+	}
+}
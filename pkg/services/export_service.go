@@ -0,0 +1,274 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"user-service/pkg/apperr"
+	contextUtils "user-service/pkg/context"
+	"user-service/pkg/dto"
+	"user-service/pkg/models"
+	"user-service/pkg/repository"
+)
+
+// exportPageSize is the number of profile changes read per query when
+// exporting a patient's history.
+const exportPageSize = 100
+
+// ExportService gives patients a copy of the data the service holds about
+// them, as PDPA data portability requires. The service keeps no consent
+// records and does not store sessions (access tokens are stateless JWTs), so
+// an export has neither.
+type ExportService struct {
+	uow repository.UnitOfWork
+}
+
+func NewExportService(uow repository.UnitOfWork) *ExportService {
+	return &ExportService{
+		uow: uow,
+	}
+}
+
+// Export collects the data held about the authenticated patient. It reads
+// from one snapshot, so a change made meanwhile is in every part of the
+// export or in none.
+func (s *ExportService) Export(ctx context.Context) (*dto.DataExportDto, error) {
+	userID := contextUtils.GetUserId(ctx)
+	var res *dto.DataExportDto
+	err := s.uow.Snapshot(ctx, func(tx repository.UnitOfWork) error {
+		var err error
+		res, err = collectExport(ctx, tx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func collectExport(ctx context.Context, tx repository.UnitOfWork, userID string) (*dto.DataExportDto, error) {
+	user, err := tx.Users().FindByID(ctx, userID)
+	if err != nil {
+		return nil, apperr.Wrap(err, apperr.CodeInternal, "failed to find user")
+	}
+	patient, err := tx.Patients().FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, apperr.Wrap(err, apperr.CodeInternal, "failed to find patient")
+	}
+
+	res := &dto.DataExportDto{
+		GeneratedAt: time.Now().UTC().Truncate(time.Second),
+		User: dto.DataExportUserDto{
+			ID:          user.ID.String(),
+			FirstName:   user.FirstName,
+			LastName:    user.LastName,
+			Gender:      user.Gender,
+			PhoneNumber: user.PhoneNumber,
+			Role:        string(user.Role),
+			CreatedAt:   user.CreatedAt,
+			UpdatedAt:   user.UpdatedAt,
+		},
+		Patient: dto.DataExportPatientDto{
+			HospitalID:       patient.HospitalID,
+			BirthDate:        patient.BirthDate,
+			IDCardNumber:     patient.IDCardNumber,
+			Address:          patient.Address,
+			Allergies:        patient.Allergies,
+			EmergencyContact: patient.EmergencyContact,
+			BloodType:        patient.BloodType,
+			CreatedAt:        patient.CreatedAt,
+			UpdatedAt:        patient.UpdatedAt,
+		},
+		HealthcareEntitlements: []string{},
+		ProfileChanges:         []*dto.ProfileChangeResponseDto{},
+		DeletionRequests:       []*dto.AccountDeletionRequestResponseDto{},
+		AuditEntries:           []*dto.DataExportAuditEntryDto{},
+	}
+
+	entitlements, err := tx.Entitlements().FindByPatientID(ctx, userID)
+	if err != nil {
+		return nil, apperr.Wrap(err, apperr.CodeInternal, "failed to find healthcare entitlements")
+	}
+	for _, entitlement := range entitlements {
+		res.HealthcareEntitlements = append(res.HealthcareEntitlements, entitlement.Name)
+	}
+
+	for offset := 0; ; offset += exportPageSize {
+		changes, total, err := tx.ProfileChanges().FindByUserID(ctx, userID, offset, exportPageSize)
+		if err != nil {
+			return nil, apperr.Wrap(err, apperr.CodeInternal, "failed to find profile history")
+		}
+		for _, change := range changes {
+			entry := toProfileChangeDto(change)
+			// As in the audit entries, staff are named by role only.
+			if entry.ActorID != nil && *entry.ActorID != userID {
+				entry.ActorID = nil
+			}
+			res.ProfileChanges = append(res.ProfileChanges, entry)
+		}
+		if len(changes) == 0 || int64(offset+len(changes)) >= total {
+			break
+		}
+	}
+
+	requests, err := tx.AccountDeletions().FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, apperr.Wrap(err, apperr.CodeInternal, "failed to find deletion requests")
+	}
+	for _, request := range requests {
+		entry := toDeletionRequestDto(request)
+		entry.ReviewedBy = nil
+		res.DeletionRequests = append(res.DeletionRequests, entry)
+	}
+
+	if res.AuditEntries, err = exportAuditEntries(ctx, tx, userID); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// exportAuditEntries returns the entries made by the user and those targeting
+// them, oldest first, each once. Other patients an entry targets are left
+// out of it.
+func exportAuditEntries(ctx context.Context, tx repository.UnitOfWork, userID string) ([]*dto.DataExportAuditEntryDto, error) {
+	seen := map[int64]bool{}
+	var found []*models.AuditEntry
+	for _, filter := range []repository.AuditFilter{{ActorID: userID}, {PatientID: userID}} {
+		var after int64
+		for {
+			batch, err := tx.AuditLog().FindAfter(ctx, filter, after, auditBatchSize)
+			if err != nil {
+				return nil, apperr.Wrap(err, apperr.CodeInternal, "failed to read audit entries")
+			}
+			if len(batch) == 0 {
+				break
+			}
+			for _, entry := range batch {
+				after = entry.ID
+				if !seen[entry.ID] {
+					seen[entry.ID] = true
+					found = append(found, entry)
+				}
+			}
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].ID < found[j].ID })
+
+	entries := make([]*dto.DataExportAuditEntryDto, 0, len(found))
+	for _, entry := range found {
+		targetIDs := []string{}
+		for _, id := range entry.TargetIDs {
+			if id == userID {
+				targetIDs = append(targetIDs, id)
+			}
+		}
+		entries = append(entries, &dto.DataExportAuditEntryDto{
+			OccurredAt: entry.OccurredAt,
+			Action:     entry.Action,
+			ActorRole:  entry.ActorRole,
+			Outcome:    string(entry.Outcome),
+			TargetIDs:  targetIDs,
+		})
+	}
+	return entries, nil
+}
+
+// WriteBundle writes export to w as a zip archive holding data.json, the
+// export itself, and summary.txt, the same data for a person to read.
+func (s *ExportService) WriteBundle(w io.Writer, export *dto.DataExportDto) error {
+	archive := zip.NewWriter(w)
+	data, err := archive.Create("data.json")
+	if err != nil {
+		return apperr.New(apperr.CodeInternal, "write export failed", err)
+	}
+	enc := json.NewEncoder(data)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(export); err != nil {
+		return apperr.New(apperr.CodeInternal, "write export failed", err)
+	}
+	summary, err := archive.Create("summary.txt")
+	if err != nil {
+		return apperr.New(apperr.CodeInternal, "write export failed", err)
+	}
+	if _, err := io.WriteString(summary, exportSummary(export)); err != nil {
+		return apperr.New(apperr.CodeInternal, "write export failed", err)
+	}
+	if err := archive.Close(); err != nil {
+		return apperr.New(apperr.CodeInternal, "write export failed", err)
+	}
+	return nil
+}
+
+// exportSummary renders export as plain text.
+func exportSummary(export *dto.DataExportDto) string {
+	const timeLayout = "2006-01-02 15:04 UTC"
+	var b strings.Builder
+	line := func(format string, args ...any) { fmt.Fprintf(&b, format+"\n", args...) }
+	field := func(name string, value *string) {
+		if value == nil || *value == "" {
+			line("  %-20s -", name+":")
+			return
+		}
+		line("  %-20s %s", name+":", *value)
+	}
+	str := func(s string) *string { return &s }
+
+	line("Personal data held by the user service")
+	line("Generated %s for %s %s", export.GeneratedAt.Format(timeLayout), export.User.FirstName, export.User.LastName)
+	line("The same data is in data.json in a machine-readable form.")
+	line("")
+	line("Account")
+	field("First name", str(export.User.FirstName))
+	field("Last name", str(export.User.LastName))
+	field("Gender", str(export.User.Gender))
+	field("Phone number", str(export.User.PhoneNumber))
+	field("Registered", str(export.User.CreatedAt.UTC().Format(timeLayout)))
+	line("")
+	line("Patient record")
+	field("Hospital number", str(export.Patient.HospitalID))
+	var birthDate *string
+	if export.Patient.BirthDate != nil {
+		birthDate = str(export.Patient.BirthDate.Format(birthDateLayout))
+	}
+	field("Birth date", birthDate)
+	field("National ID", export.Patient.IDCardNumber)
+	field("Address", export.Patient.Address)
+	field("Allergies", export.Patient.Allergies)
+	field("Emergency contact", export.Patient.EmergencyContact)
+	field("Blood type", export.Patient.BloodType)
+	line("")
+	line("Healthcare entitlements")
+	if len(export.HealthcareEntitlements) == 0 {
+		line("  none")
+	}
+	for _, name := range export.HealthcareEntitlements {
+		line("  %s", name)
+	}
+	line("")
+	line("Profile changes (%d), newest first", len(export.ProfileChanges))
+	for _, change := range export.ProfileChanges {
+		line("  %s  %s changed by %s", change.ChangedAt.UTC().Format(timeLayout), change.Field, change.ActorRole)
+	}
+	line("")
+	line("Account deletion requests (%d)", len(export.DeletionRequests))
+	for _, request := range export.DeletionRequests {
+		line("  %s  %s", request.RequestedAt.UTC().Format(timeLayout), request.Status)
+	}
+	line("")
+	line("Access log (%d entries), oldest first", len(export.AuditEntries))
+	for _, entry := range export.AuditEntries {
+		actor := entry.ActorRole
+		if actor == "" {
+			actor = "anonymous"
+		}
+		line("  %s  %s by %s, %s", entry.OccurredAt.UTC().Format(timeLayout), entry.Action, actor, entry.Outcome)
+	}
+	line("")
+	line("The service keeps no consent records and does not store sign-in sessions.")
+	return b.String()
+}
@@ -0,0 +1,125 @@
+package service_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"user-service/pkg/dto"
+	"user-service/pkg/models"
+	service "user-service/pkg/services"
+
+	"github.com/google/uuid"
+)
+
+func TestExport(t *testing.T) {
+	f := newFixture(t)
+	f.store.SeedEntitlements("Social Security")
+	userID := f.register(t, "HN0001", ptr(testIDCard))
+	otherID := f.register(t, "HN0002", nil)
+	doctorID := f.addDoctor(t, "dr.ploy")
+	ctx := asUser(userID)
+
+	if err := f.store.Entitlements().AssignToPatient(context.Background(), userID, []string{"Social Security"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.service.UpdateProfileByID(ctx, f.etag(t, ctx), &dto.UpdatePatientProfileRequestDto{Allergies: ptr("Penicillin")}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	accounts := service.NewAccountService(f.store, f.store.AccountDeletions(), 0)
+	request, err := accounts.RequestDeletion(ctx, &dto.RequestAccountDeletionRequestDto{})
+	if err != nil {
+		t.Fatalf("request deletion: %v", err)
+	}
+	adminID := uuid.NewString()
+	if _, err := accounts.RejectDeletion(asRole(adminID, string(models.AdminRole)), request.ID, &dto.ReviewAccountDeletionRequestDto{}); err != nil {
+		t.Fatalf("reject deletion: %v", err)
+	}
+	audit := service.NewAuditService(f.store.AuditLog())
+	recordAccess(t, audit, uuid.MustParse(userID), "patient.read", userID)
+	doctorUUID := uuid.MustParse(doctorID)
+	if err := audit.Record(context.Background(), &models.AuditEntry{
+		ActorID:   &doctorUUID,
+		ActorRole: "doctor",
+		Action:    "patient.read_batch",
+		TargetIDs: []string{userID, otherID},
+		RequestID: "req-doctor-batch",
+		IP:        "203.0.113.7",
+		Outcome:   models.AuditSuccess,
+		Status:    200,
+	}); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	recordAccess(t, audit, doctorUUID, "patient.read", otherID)
+	if err := f.store.ProfileChanges().Create(context.Background(), []*models.ProfileChange{{
+		UserID:    uuid.MustParse(userID),
+		Field:     "blood_type",
+		NewValue:  ptr("O+"),
+		ActorID:   &doctorUUID,
+		ActorRole: models.DoctorRole,
+	}}); err != nil {
+		t.Fatalf("record profile change: %v", err)
+	}
+
+	svc := service.NewExportService(f.store)
+	export, err := svc.Export(ctx)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if export.User.ID != userID || deref(export.Patient.IDCardNumber) != testIDCard || deref(export.Patient.Allergies) != "Penicillin" {
+		t.Errorf("export = %+v / %+v, want the patient's decrypted record", export.User, export.Patient)
+	}
+	if len(export.HealthcareEntitlements) != 1 || len(export.ProfileChanges) != 2 || len(export.DeletionRequests) != 1 {
+		t.Errorf("entitlements/changes/deletion requests = %d/%d/%d, want 1/2/1",
+			len(export.HealthcareEntitlements), len(export.ProfileChanges), len(export.DeletionRequests))
+	}
+	for _, change := range export.ProfileChanges {
+		if own := change.ActorRole == string(models.PatientRole); own != (deref(change.ActorID) == userID) {
+			t.Errorf("profile change %+v, want actor_id only on the patient's own change", change)
+		}
+	}
+	if len(export.AuditEntries) != 2 || export.AuditEntries[0].Action != "patient.read" || export.AuditEntries[1].Action != "patient.read_batch" {
+		t.Errorf("audit entries = %+v, want the patient's own read and the doctor's batch read", export.AuditEntries)
+	} else if targets := export.AuditEntries[1].TargetIDs; len(targets) != 1 || targets[0] != userID {
+		t.Errorf("batch read targets = %v, want only the patient", targets)
+	}
+
+	var buf bytes.Buffer
+	if err := svc.WriteBundle(&buf, export); err != nil {
+		t.Fatalf("write bundle: %v", err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("read bundle: %v", err)
+	}
+	files := map[string]string{}
+	for _, file := range archive.File {
+		r, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(r)
+		files[file.Name] = string(b)
+	}
+	var data dto.DataExportDto
+	if err := json.Unmarshal([]byte(files["data.json"]), &data); err != nil || data.Patient.HospitalID != "HN0001" {
+		t.Errorf("data.json = %q (%v), want the export", files["data.json"], err)
+	}
+	for _, leak := range []string{otherID, doctorID, adminID, "203.0.113.7", "req-doctor-batch", "hash"} {
+		if strings.Contains(files["data.json"], leak) {
+			t.Errorf("data.json contains %q, which is not the patient's data", leak)
+		}
+	}
+	for _, want := range []string{"HN0001", testIDCard, "Social Security", "Access log (2 entries)", "no consent records"} {
+		if !strings.Contains(files["summary.txt"], want) {
+			t.Errorf("summary.txt does not mention %q:\n%s", want, files["summary.txt"])
+		}
+	}
+
+	if _, err := svc.Export(asRole(doctorID, string(models.DoctorRole))); err == nil {
+		t.Error("export for a doctor succeeded, want an error")
+	}
+}
@@ -0,0 +1,435 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"user-service/pkg/apperr"
+	"user-service/pkg/dto"
+	"user-service/pkg/fhir"
+	"user-service/pkg/models"
+	"user-service/pkg/repository"
+
+	"github.com/google/uuid"
+)
+
+// FHIRService serves patients and doctors as FHIR R4 resources. A patient is
+// a Patient; a doctor is a Practitioner and a PractitionerRole with the same
+// ID, which carries the specialty. Allergies and blood type are left out:
+// FHIR records them as AllergyIntolerance and Observation resources, which
+// the service does not serve.
+type FHIRService struct {
+	uow                  repository.UnitOfWork
+	hospitalNumberSystem string
+	practitionerSystem   string
+}
+
+// NewFHIRService returns a FHIRService that identifies hospital numbers and
+// doctor usernames with the given identifier systems.
+func NewFHIRService(uow repository.UnitOfWork, hospitalNumberSystem, practitionerSystem string) *FHIRService {
+	return &FHIRService{
+		uow:                  uow,
+		hospitalNumberSystem: hospitalNumberSystem,
+		practitionerSystem:   practitionerSystem,
+	}
+}
+
+func (s *FHIRService) ReadPatient(ctx context.Context, id string) (*fhir.Patient, error) {
+	ids, ok := fhirIDs(id)
+	if !ok {
+		return nil, apperr.New(apperr.CodeNotFound, "Patient/"+id+" not found", nil)
+	}
+	patients, _, err := s.uow.Patients().FindPage(ctx, repository.PatientFilter{UserIDs: ids}, 0, 1)
+	if err != nil {
+		return nil, apperr.Wrap(err, apperr.CodeInternal, "failed to find patient")
+	}
+	if len(patients) == 0 {
+		return nil, apperr.New(apperr.CodeNotFound, "Patient/"+id+" not found", nil)
+	}
+	return s.toPatient(patients[0]), nil
+}
+
+// SearchPatients returns a page of the patients matching query as a
+// searchset bundle. base is the URL of the FHIR API, for links.
+func (s *FHIRService) SearchPatients(ctx context.Context, base string, query *dto.FHIRSearchQueryDto) (*fhir.Bundle, error) {
+	count, offset := fhirPage(query)
+	filter, ok, err := s.patientFilter(query)
+	if err != nil {
+		return nil, err
+	}
+	var patients []*models.Patient
+	var total int64
+	if ok {
+		patients, total, err = s.uow.Patients().FindPage(ctx, filter, offset, count)
+		if err != nil {
+			return nil, apperr.Wrap(err, apperr.CodeInternal, "failed to search patients")
+		}
+	}
+	entries := make([]fhir.BundleEntry, 0, len(patients))
+	for _, patient := range patients {
+		entries = append(entries, matchEntry(base, "Patient", patient.UserID.String(), s.toPatient(patient)))
+	}
+	return searchBundle(base, "Patient", query, count, offset, total, entries), nil
+}
+
+func (s *FHIRService) ReadPractitioner(ctx context.Context, id string) (*fhir.Practitioner, error) {
+	doctor, err := s.findDoctor(ctx, "Practitioner", id)
+	if err != nil {
+		return nil, err
+	}
+	return s.toPractitioner(doctor), nil
+}
+
+func (s *FHIRService) SearchPractitioners(ctx context.Context, base string, query *dto.FHIRSearchQueryDto) (*fhir.Bundle, error) {
+	count, offset := fhirPage(query)
+	filter, ok, err := s.practitionerFilter(query)
+	if err != nil {
+		return nil, err
+	}
+	doctors, total, err := s.searchDoctors(ctx, filter, ok, count, offset)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]fhir.BundleEntry, 0, len(doctors))
+	for _, doctor := range doctors {
+		entries = append(entries, matchEntry(base, "Practitioner", doctor.UserID.String(), s.toPractitioner(doctor)))
+	}
+	return searchBundle(base, "Practitioner", query, count, offset, total, entries), nil
+}
+
+func (s *FHIRService) ReadPractitionerRole(ctx context.Context, id string) (*fhir.PractitionerRole, error) {
+	doctor, err := s.findDoctor(ctx, "PractitionerRole", id)
+	if err != nil {
+		return nil, err
+	}
+	return toPractitionerRole(doctor), nil
+}
+
+// SearchPractitionerRoles supports _id and practitioner, a Practitioner
+// reference or ID. Roles carry no identifier or name of their own, so those
+// parameters are rejected rather than ignored, which would match every role.
+func (s *FHIRService) SearchPractitionerRoles(ctx context.Context, base string, query *dto.FHIRSearchQueryDto) (*fhir.Bundle, error) {
+	if query.Identifier != "" || query.Name != "" {
+		return nil, apperr.New(apperr.CodeBadRequest, "PractitionerRole search supports _id and practitioner, not identifier or name", nil)
+	}
+	count, offset := fhirPage(query)
+	filter := repository.DoctorFilter{}
+	ids, ok := fhirIDs(query.ID)
+	if ok && query.Practitioner != "" {
+		var refs []string
+		for _, ref := range strings.Split(query.Practitioner, ",") {
+			refs = append(refs, strings.TrimPrefix(strings.TrimSpace(ref), "Practitioner/"))
+		}
+		var practitioners []string
+		if practitioners, ok = fhirIDs(strings.Join(refs, ",")); ok {
+			ids = intersect(ids, practitioners)
+			ok = len(ids) > 0
+		}
+	}
+	filter.UserIDs = ids
+	doctors, total, err := s.searchDoctors(ctx, filter, ok, count, offset)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]fhir.BundleEntry, 0, len(doctors))
+	for _, doctor := range doctors {
+		entries = append(entries, matchEntry(base, "PractitionerRole", doctor.UserID.String(), toPractitionerRole(doctor)))
+	}
+	return searchBundle(base, "PractitionerRole", query, count, offset, total, entries), nil
+}
+
+func (s *FHIRService) findDoctor(ctx context.Context, resourceType, id string) (*models.Doctor, error) {
+	ids, ok := fhirIDs(id)
+	if !ok {
+		return nil, apperr.New(apperr.CodeNotFound, resourceType+"/"+id+" not found", nil)
+	}
+	doctors, _, err := s.uow.Doctors().FindPage(ctx, repository.DoctorFilter{UserIDs: ids}, 0, 1)
+	if err != nil {
+		return nil, apperr.Wrap(err, apperr.CodeInternal, "failed to find doctor")
+	}
+	if len(doctors) == 0 {
+		return nil, apperr.New(apperr.CodeNotFound, resourceType+"/"+id+" not found", nil)
+	}
+	return doctors[0], nil
+}
+
+// searchDoctors runs a doctor search unless the parameters cannot match.
+func (s *FHIRService) searchDoctors(ctx context.Context, filter repository.DoctorFilter, ok bool, count, offset int) ([]*models.Doctor, int64, error) {
+	if !ok {
+		return nil, 0, nil
+	}
+	doctors, total, err := s.uow.Doctors().FindPage(ctx, filter, offset, count)
+	if err != nil {
+		return nil, 0, apperr.Wrap(err, apperr.CodeInternal, "failed to search doctors")
+	}
+	return doctors, total, nil
+}
+
+// patientFilter translates the search parameters. It reports false when they
+// cannot match any patient, such as an identifier from another system.
+func (s *FHIRService) patientFilter(query *dto.FHIRSearchQueryDto) (repository.PatientFilter, bool, error) {
+	filter := repository.PatientFilter{Name: strings.TrimSpace(query.Name)}
+	var ok bool
+	if filter.UserIDs, ok = fhirIDs(query.ID); !ok {
+		return filter, false, nil
+	}
+	if query.Identifier == "" {
+		return filter, true, nil
+	}
+	system, value, hasSystem, err := splitToken(query.Identifier)
+	if err != nil {
+		return filter, false, err
+	}
+	switch {
+	case !hasSystem:
+		filter.Identifier = value
+	case system == fhir.ThaiNationalIDSystem:
+		filter.IDCardNumber = value
+	case system == s.hospitalNumberSystem:
+		filter.HospitalID = value
+	default:
+		return filter, false, nil
+	}
+	return filter, true, nil
+}
+
+func (s *FHIRService) practitionerFilter(query *dto.FHIRSearchQueryDto) (repository.DoctorFilter, bool, error) {
+	filter := repository.DoctorFilter{Name: strings.TrimSpace(query.Name)}
+	var ok bool
+	if filter.UserIDs, ok = fhirIDs(query.ID); !ok {
+		return filter, false, nil
+	}
+	if query.Identifier == "" {
+		return filter, true, nil
+	}
+	system, value, hasSystem, err := splitToken(query.Identifier)
+	if err != nil {
+		return filter, false, err
+	}
+	if hasSystem && system != s.practitionerSystem {
+		return filter, false, nil
+	}
+	filter.Username = value
+	return filter, true, nil
+}
+
+func (s *FHIRService) toPatient(patient *models.Patient) *fhir.Patient {
+	user := &patient.User
+	res := &fhir.Patient{
+		ResourceType: "Patient",
+		ID:           patient.UserID.String(),
+		Meta: &fhir.Meta{
+			VersionID:   strings.Trim(profileETag(user, patient), `"`),
+			LastUpdated: lastUpdated(user.UpdatedAt, patient.UpdatedAt),
+		},
+		Identifier: []fhir.Identifier{{Use: "usual", System: s.hospitalNumberSystem, Value: patient.HospitalID}},
+		Active:     true,
+		Name:       humanName(user),
+		Telecom:    telecom(user),
+		Gender:     fhirGender(user.Gender),
+	}
+	if patient.IDCardNumber != nil && *patient.IDCardNumber != "" {
+		res.Identifier = append(res.Identifier, fhir.Identifier{Use: "official", System: fhir.ThaiNationalIDSystem, Value: *patient.IDCardNumber})
+	}
+	if patient.BirthDate != nil {
+		res.BirthDate = patient.BirthDate.Format(birthDateLayout)
+	}
+	if patient.Address != nil && *patient.Address != "" {
+		res.Address = []fhir.Address{{Text: *patient.Address}}
+	}
+	// The emergency contact is free text, usually a name and a phone
+	// number, so it is kept whole as the contact's name.
+	if patient.EmergencyContact != nil && *patient.EmergencyContact != "" {
+		res.Contact = []fhir.PatientContact{{
+			Relationship: []fhir.CodeableConcept{{
+				Coding: []fhir.Coding{{System: fhir.ContactRoleSystem, Code: "C", Display: "Emergency Contact"}},
+			}},
+			Name: &fhir.HumanName{Text: *patient.EmergencyContact},
+		}}
+	}
+	return res
+}
+
+func (s *FHIRService) toPractitioner(doctor *models.Doctor) *fhir.Practitioner {
+	return &fhir.Practitioner{
+		ResourceType: "Practitioner",
+		ID:           doctor.UserID.String(),
+		Meta:         doctorMeta(doctor),
+		Identifier:   []fhir.Identifier{{Use: "usual", System: s.practitionerSystem, Value: doctor.Username}},
+		Active:       true,
+		Name:         humanName(&doctor.User),
+		Telecom:      telecom(&doctor.User),
+		Gender:       fhirGender(doctor.User.Gender),
+	}
+}
+
+func toPractitionerRole(doctor *models.Doctor) *fhir.PractitionerRole {
+	res := &fhir.PractitionerRole{
+		ResourceType: "PractitionerRole",
+		ID:           doctor.UserID.String(),
+		Meta:         doctorMeta(doctor),
+		Active:       true,
+		Practitioner: fhir.Reference{
+			Reference: "Practitioner/" + doctor.UserID.String(),
+			Display:   doctor.User.FirstName + " " + doctor.User.LastName,
+		},
+		Code: []fhir.CodeableConcept{{
+			Coding: []fhir.Coding{{System: fhir.PractitionerRoleSystem, Code: "doctor", Display: "Doctor"}},
+		}},
+	}
+	if doctor.Specialty != nil && *doctor.Specialty != "" {
+		res.Specialty = []fhir.CodeableConcept{{Text: *doctor.Specialty}}
+	}
+	return res
+}
+
+func doctorMeta(doctor *models.Doctor) *fhir.Meta {
+	return &fhir.Meta{
+		VersionID:   fmt.Sprintf("%d-%d", doctor.User.Version, doctor.Version),
+		LastUpdated: lastUpdated(doctor.User.UpdatedAt, doctor.UpdatedAt),
+	}
+}
+
+func humanName(user *models.User) []fhir.HumanName {
+	return []fhir.HumanName{{
+		Use:    "official",
+		Text:   user.FirstName + " " + user.LastName,
+		Family: user.LastName,
+		Given:  []string{user.FirstName},
+	}}
+}
+
+func telecom(user *models.User) []fhir.ContactPoint {
+	if user.PhoneNumber == "" {
+		return nil
+	}
+	return []fhir.ContactPoint{{System: "phone", Value: user.PhoneNumber}}
+}
+
+func fhirGender(gender string) string {
+	switch gender {
+	case models.Male, models.Female:
+		return gender
+	}
+	return "unknown"
+}
+
+// lastUpdated returns the later of the update times of a resource's rows.
+func lastUpdated(a, b time.Time) *time.Time {
+	t := a
+	if b.After(a) {
+		t = b
+	}
+	t = t.UTC()
+	return &t
+}
+
+// fhirPage returns the page size and offset of a search, from _count and
+// _offset.
+func fhirPage(query *dto.FHIRSearchQueryDto) (count, offset int) {
+	count, offset = query.Count, query.Offset
+	if count < 1 {
+		count = defaultPageSize
+	}
+	if count > maxPageSize {
+		count = maxPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return count, offset
+}
+
+// fhirIDs parses a comma-separated _id parameter. IDs that are not UUIDs
+// cannot match, so it reports false when the parameter is set but holds no
+// valid ID.
+func fhirIDs(param string) ([]string, bool) {
+	if param == "" {
+		return nil, true
+	}
+	var ids []string
+	for _, id := range strings.Split(param, ",") {
+		if parsed, err := uuid.Parse(strings.TrimSpace(id)); err == nil {
+			ids = append(ids, parsed.String())
+		}
+	}
+	return ids, len(ids) > 0
+}
+
+// splitToken splits a token parameter, [system|]value.
+func splitToken(token string) (system, value string, hasSystem bool, err error) {
+	value = token
+	if i := strings.Index(token, "|"); i >= 0 {
+		system, value, hasSystem = token[:i], token[i+1:], true
+	}
+	if value = strings.TrimSpace(value); value == "" {
+		return "", "", false, apperr.New(apperr.CodeBadRequest, "identifier must have a value", nil)
+	}
+	return system, value, hasSystem, nil
+}
+
+func intersect(a, b []string) []string {
+	if a == nil {
+		return b
+	}
+	var out []string
+	for _, id := range a {
+		for _, other := range b {
+			if id == other {
+				out = append(out, id)
+				break
+			}
+		}
+	}
+	return out
+}
+
+func matchEntry(base, resourceType, id string, resource any) fhir.BundleEntry {
+	return fhir.BundleEntry{
+		FullURL:  base + "/" + resourceType + "/" + id,
+		Resource: resource,
+		Search:   &fhir.BundleEntrySearch{Mode: "match"},
+	}
+}
+
+// searchBundle wraps one page of matches with self, first, previous, next
+// and last links that repeat the search parameters.
+func searchBundle(base, resourceType string, query *dto.FHIRSearchQueryDto, count, offset int, total int64, entries []fhir.BundleEntry) *fhir.Bundle {
+	params := url.Values{}
+	for name, value := range map[string]string{
+		"_id":          query.ID,
+		"identifier":   query.Identifier,
+		"name":         query.Name,
+		"practitioner": query.Practitioner,
+	} {
+		if value != "" {
+			params.Set(name, value)
+		}
+	}
+	link := func(relation string, offset int) fhir.BundleLink {
+		params.Set("_count", strconv.Itoa(count))
+		params.Set("_offset", strconv.Itoa(offset))
+		return fhir.BundleLink{Relation: relation, URL: base + "/" + resourceType + "?" + params.Encode()}
+	}
+
+	bundle := &fhir.Bundle{
+		ResourceType: "Bundle",
+		Type:         "searchset",
+		Total:        total,
+		Link:         []fhir.BundleLink{link("self", offset), link("first", 0)},
+		Entry:        entries,
+	}
+	if offset > 0 {
+		bundle.Link = append(bundle.Link, link("previous", max(offset-count, 0)))
+	}
+	if int64(offset+count) < total {
+		bundle.Link = append(bundle.Link, link("next", offset+count))
+	}
+	if total > 0 {
+		bundle.Link = append(bundle.Link, link("last", int((total-1)/int64(count))*count))
+	}
+	return bundle
+}
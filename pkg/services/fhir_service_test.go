@@ -0,0 +1,139 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"user-service/pkg/apperr"
+	"user-service/pkg/dto"
+	"user-service/pkg/fhir"
+	service "user-service/pkg/services"
+)
+
+const (
+	fhirBase                 = "https://api.example/fhir"
+	testHospitalNumberSystem = "urn:oid:1.2.3.4.1"
+	testPractitionerSystem   = "urn:oid:1.2.3.4.2"
+)
+
+func TestFHIRPatient(t *testing.T) {
+	f := newFixture(t)
+	userID := f.register(t, "HN0001", ptr(testIDCard))
+	ctx := asUser(userID)
+	if _, err := f.service.UpdateProfileByID(ctx, f.etag(t, ctx), &dto.UpdatePatientProfileRequestDto{
+		Address:          ptr("99 Rama IV Road, Bangkok"),
+		EmergencyContact: ptr("Malee Jaidee 0898765432"),
+	}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	svc := service.NewFHIRService(f.store, testHospitalNumberSystem, testPractitionerSystem)
+
+	patient, err := svc.ReadPatient(context.Background(), userID)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	want := map[string]string{testHospitalNumberSystem: "HN0001", fhir.ThaiNationalIDSystem: testIDCard}
+	for _, identifier := range patient.Identifier {
+		if want[identifier.System] != identifier.Value {
+			t.Errorf("identifier %+v, want one of %v", identifier, want)
+		}
+		delete(want, identifier.System)
+	}
+	if len(want) != 0 {
+		t.Errorf("missing identifiers %v", want)
+	}
+	if patient.Name[0].Family != "Jaidee" || patient.Gender != "male" || patient.Telecom[0].Value != "0812345678" {
+		t.Errorf("patient = %+v, want the registered name, gender and phone", patient)
+	}
+	if len(patient.Address) != 1 || len(patient.Contact) != 1 || patient.Contact[0].Name.Text != "Malee Jaidee 0898765432" ||
+		patient.Contact[0].Relationship[0].Coding[0].Code != "C" {
+		t.Errorf("address/contact = %+v / %+v, want the address and the emergency contact", patient.Address, patient.Contact)
+	}
+
+	_, err = svc.ReadPatient(context.Background(), "not-a-uuid")
+	assertCode(t, err, apperr.CodeNotFound)
+}
+
+func TestFHIRPatientSearch(t *testing.T) {
+	f := newFixture(t)
+	firstID := f.register(t, "HN0001", ptr(testIDCard))
+	f.register(t, "HN0002", nil)
+	f.register(t, "HN0003", nil)
+	svc := service.NewFHIRService(f.store, testHospitalNumberSystem, testPractitionerSystem)
+
+	for name, tc := range map[string]struct {
+		query dto.FHIRSearchQueryDto
+		total int64
+	}{
+		"all":                   {dto.FHIRSearchQueryDto{}, 3},
+		"_id":                   {dto.FHIRSearchQueryDto{ID: firstID + ",not-a-uuid"}, 1},
+		"invalid _id":           {dto.FHIRSearchQueryDto{ID: "not-a-uuid"}, 0},
+		"hospital number":       {dto.FHIRSearchQueryDto{Identifier: testHospitalNumberSystem + "|HN0002"}, 1},
+		"national ID":           {dto.FHIRSearchQueryDto{Identifier: fhir.ThaiNationalIDSystem + "|" + testIDCard}, 1},
+		"identifier, no system": {dto.FHIRSearchQueryDto{Identifier: testIDCard}, 1},
+		"other system":          {dto.FHIRSearchQueryDto{Identifier: "urn:other|HN0002"}, 0},
+		"name prefix":           {dto.FHIRSearchQueryDto{Name: "jai"}, 3},
+		"name not a prefix":     {dto.FHIRSearchQueryDto{Name: "dee"}, 0},
+	} {
+		bundle, err := svc.SearchPatients(context.Background(), fhirBase, &tc.query)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if bundle.Total != tc.total || len(bundle.Entry) != int(tc.total) {
+			t.Errorf("%s: total = %d with %d entries, want %d", name, bundle.Total, len(bundle.Entry), tc.total)
+		}
+	}
+
+	bundle, err := svc.SearchPatients(context.Background(), fhirBase, &dto.FHIRSearchQueryDto{Name: "Som", Count: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	links := map[string]string{}
+	for _, link := range bundle.Link {
+		links[link.Relation] = link.URL
+	}
+	if len(bundle.Entry) != 2 || links["next"] != fhirBase+"/Patient?_count=2&_offset=2&name=Som" || links["previous"] != "" {
+		t.Errorf("first page = %d entries, links %v; want 2 and a next link", len(bundle.Entry), links)
+	}
+	bundle, _ = svc.SearchPatients(context.Background(), fhirBase, &dto.FHIRSearchQueryDto{Name: "Som", Count: 2, Offset: 2})
+	if len(bundle.Entry) != 1 || bundle.Entry[0].FullURL != fhirBase+"/Patient/"+bundle.Entry[0].Resource.(*fhir.Patient).ID {
+		t.Errorf("second page = %+v, want the last patient", bundle.Entry)
+	}
+
+	_, err = svc.SearchPatients(context.Background(), fhirBase, &dto.FHIRSearchQueryDto{Identifier: testHospitalNumberSystem + "|"})
+	assertCode(t, err, apperr.CodeBadRequest)
+}
+
+func TestFHIRPractitioner(t *testing.T) {
+	f := newFixture(t)
+	doctorID := f.addDoctor(t, "dr.ploy")
+	f.addDoctor(t, "dr.niran")
+	svc := service.NewFHIRService(f.store, testHospitalNumberSystem, testPractitionerSystem)
+
+	practitioner, err := svc.ReadPractitioner(context.Background(), doctorID)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if practitioner.Identifier[0].System != testPractitionerSystem || practitioner.Identifier[0].Value != "dr.ploy" {
+		t.Errorf("identifier = %+v, want the username", practitioner.Identifier)
+	}
+	role, err := svc.ReadPractitionerRole(context.Background(), doctorID)
+	if err != nil {
+		t.Fatalf("read role: %v", err)
+	}
+	if role.Practitioner.Reference != "Practitioner/"+doctorID || role.Specialty[0].Text != "Cardiology" {
+		t.Errorf("role = %+v, want the practitioner's specialty", role)
+	}
+
+	bundle, err := svc.SearchPractitioners(context.Background(), fhirBase, &dto.FHIRSearchQueryDto{Identifier: "dr.niran"})
+	if err != nil || bundle.Total != 1 {
+		t.Errorf("search by username = %+v (%v), want 1 match", bundle, err)
+	}
+	bundle, err = svc.SearchPractitionerRoles(context.Background(), fhirBase, &dto.FHIRSearchQueryDto{Practitioner: "Practitioner/" + doctorID})
+	if err != nil || bundle.Total != 1 || bundle.Entry[0].Resource.(*fhir.PractitionerRole).ID != doctorID {
+		t.Errorf("roles of practitioner = %+v (%v), want the doctor's role", bundle, err)
+	}
+	for _, query := range []*dto.FHIRSearchQueryDto{{Identifier: "dr.ploy"}, {Name: "Ploy"}} {
+		_, err = svc.SearchPractitionerRoles(context.Background(), fhirBase, query)
+		assertCode(t, err, apperr.CodeBadRequest)
+	}
+}
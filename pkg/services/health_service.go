@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+	"user-service/pkg/apperr"
+	"user-service/pkg/buildinfo"
+	"user-service/pkg/dto"
+)
+
+// readinessTimeout bounds each readiness check, so a hung database makes
+// /readyz fail rather than hang the orchestrator's probe.
+const readinessTimeout = 2 * time.Second
+
+// HealthCheck is one condition the service needs to serve traffic.
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// HealthService answers the liveness, readiness and version probes.
+type HealthService struct {
+	checks        []HealthCheck
+	schemaVersion func(ctx context.Context) (int64, error)
+}
+
+// NewHealthService returns a HealthService running checks for readiness.
+// schemaVersion reports the database's migration version; it may be nil
+// when there is no database, as in unit tests.
+func NewHealthService(schemaVersion func(ctx context.Context) (int64, error), checks ...HealthCheck) *HealthService {
+	return &HealthService{checks: checks, schemaVersion: schemaVersion}
+}
+
+// Ready runs every check concurrently. If any fails it returns an
+// unavailable error listing the failed checks; the causes are logged rather
+// than returned, since the endpoint is unauthenticated.
+func (s *HealthService) Ready(ctx context.Context) (*dto.ReadinessResponseDto, error) {
+	errs := make([]error, len(s.checks))
+	var wg sync.WaitGroup
+	for i, check := range s.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, readinessTimeout)
+			defer cancel()
+			errs[i] = check.Check(checkCtx)
+		}()
+	}
+	wg.Wait()
+
+	res := &dto.ReadinessResponseDto{Status: "ready", Checks: make(map[string]string, len(s.checks))}
+	notReady := apperr.New(apperr.CodeUnavailable, "service not ready", nil)
+	failed := false
+	for i, check := range s.checks {
+		if errs[i] != nil {
+			slog.WarnContext(ctx, "readiness check failed", "check", check.Name, "error", errs[i])
+			notReady.WithField(check.Name, "failed")
+			failed = true
+			continue
+		}
+		res.Checks[check.Name] = "ok"
+	}
+	if failed {
+		return nil, notReady
+	}
+	return res, nil
+}
+
+// Version reports the build of the running binary and the schema version of
+// the database, if it can be read.
+func (s *HealthService) Version(ctx context.Context) *dto.VersionResponseDto {
+	info := buildinfo.Get()
+	res := &dto.VersionResponseDto{
+		Version:   info.Version,
+		Commit:    info.Commit,
+		BuildTime: info.BuildTime,
+		GoVersion: info.GoVersion,
+	}
+	if s.schemaVersion != nil {
+		version, err := s.schemaVersion(ctx)
+		if err != nil {
+			slog.WarnContext(ctx, "schema version unavailable", "error", err)
+		} else {
+			res.SchemaVersion = &version
+		}
+	}
+	return res
+}
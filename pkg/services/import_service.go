@@ -0,0 +1,362 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"user-service/pkg/apperr"
+	"user-service/pkg/dto"
+	"user-service/pkg/models"
+	"user-service/pkg/repository"
+	"user-service/pkg/utils"
+	"user-service/pkg/validation"
+
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	defaultImportBatchSize = 100
+	maxImportBatchSize     = 1000
+	// maxImportRows bounds one file; larger registries are split.
+	maxImportRows = 10000
+
+	birthDateLayout = "2006-01-02"
+	// entitlementSeparator separates entitlement names within one cell.
+	entitlementSeparator = ";"
+)
+
+// importColumns are the CSV columns a patient import understands, named like
+// the fields of PatientRegisterPatientRequestDto. Without a password column
+// imported patients get utils.LockedPassword and cannot sign in until an
+// admin sets a password (UserService.SetPatientPassword).
+var importColumns = []string{
+	"hospital_id", "first_name", "last_name", "gender", "phone_number", "birth_date",
+	"id_card_number", "blood_type", "healthcare_entitlements", "address", "allergies",
+	"emergency_contact", "password",
+}
+
+// requiredImportColumns must be in the header of every file.
+var requiredImportColumns = []string{"hospital_id", "first_name", "last_name", "gender"}
+
+// ImportService registers patients in bulk from a hospital's existing
+// registry.
+type ImportService struct {
+	uow      repository.UnitOfWork
+	validate *validator.Validate
+}
+
+func NewImportService(uow repository.UnitOfWork) *ImportService {
+	return &ImportService{
+		uow:      uow,
+		validate: validation.New(),
+	}
+}
+
+// importRow is one parsed CSV row.
+type importRow struct {
+	line         int
+	body         dto.PatientRegisterPatientRequestDto
+	entitlements []string
+	errors       []validation.FieldError
+}
+
+// ImportPatients reads patients from CSV and registers them. Each row is
+// checked with the registration rules; rows that fail are reported and
+// skipped, the rest are written in transactions of query.BatchSize rows. In
+// a dry run every batch is rolled back, so the report shows what an import
+// would do without changing anything.
+func (s *ImportService) ImportPatients(ctx context.Context, r io.Reader, query *dto.PatientImportQueryDto) (*dto.PatientImportResponseDto, error) {
+	batchSize := query.BatchSize
+	if batchSize == 0 {
+		batchSize = defaultImportBatchSize
+	}
+	if batchSize < 1 || batchSize > maxImportBatchSize {
+		return nil, apperr.New(apperr.CodeBadRequest, fmt.Sprintf("batch_size must be between 1 and %d", maxImportBatchSize), nil)
+	}
+
+	rows, err := parseImportCSV(r)
+	if err != nil {
+		return nil, err
+	}
+	catalog, err := s.uow.Entitlements().FindAll(ctx)
+	if err != nil {
+		return nil, apperr.Wrap(err, apperr.CodeInternal, "failed to find healthcare entitlements")
+	}
+	s.checkRows(rows, catalog)
+
+	res := &dto.PatientImportResponseDto{
+		DryRun: query.DryRun,
+		Total:  len(rows),
+		Errors: []dto.PatientImportRowErrorDto{},
+	}
+	valid := make([]*importRow, 0, len(rows))
+	for _, row := range rows {
+		if len(row.errors) > 0 {
+			res.Errors = append(res.Errors, rowError(row))
+			continue
+		}
+		valid = append(valid, row)
+	}
+
+	for start := 0; start < len(valid); start += batchSize {
+		batch := valid[start:min(start+batchSize, len(valid))]
+		var userIDs []string
+		var failed []dto.PatientImportRowErrorDto
+		err := auditedDo(ctx, s.uow, func(tx repository.UnitOfWork) error {
+			for _, row := range batch {
+				userID, err := importPatient(ctx, tx, row)
+				if err != nil {
+					if row.errors = rowFieldErrors(err); row.errors == nil {
+						return fmt.Errorf("line %d: %w", row.line, err)
+					}
+					failed = append(failed, rowError(row))
+					continue
+				}
+				userIDs = append(userIDs, userID)
+			}
+			if query.DryRun {
+				return errDryRun
+			}
+			setAuditTargets(ctx, userIDs...)
+			return nil
+		})
+		if err != nil && !errors.Is(err, errDryRun) {
+			return nil, apperr.New(apperr.CodeInternal,
+				fmt.Sprintf("import stopped after %d patients were imported", res.Imported), err)
+		}
+		res.Imported += len(userIDs)
+		res.Errors = append(res.Errors, failed...)
+		if !query.DryRun {
+			res.UserIDs = append(res.UserIDs, userIDs...)
+		}
+	}
+
+	sort.Slice(res.Errors, func(i, j int) bool { return res.Errors[i].Line < res.Errors[j].Line })
+	res.Failed = len(res.Errors)
+	return res, nil
+}
+
+// parseImportCSV reads the header and rows. Problems with the file as a whole
+// (a missing or unknown column, a malformed line, too many rows) fail the
+// import; problems with a cell are recorded on its row.
+func parseImportCSV(r io.Reader) ([]*importRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, apperr.New(apperr.CodeBadRequest, "the file is empty", nil)
+	}
+	if err != nil {
+		return nil, apperr.New(apperr.CodeBadRequest, "invalid CSV", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !slices.Contains(importColumns, name) {
+			return nil, apperr.New(apperr.CodeBadRequest, "unknown column "+name, nil)
+		}
+		if _, dup := columns[name]; dup {
+			return nil, apperr.New(apperr.CodeBadRequest, "duplicate column "+name, nil)
+		}
+		columns[name] = i
+	}
+	for _, name := range requiredImportColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, apperr.New(apperr.CodeBadRequest, "missing column "+name, nil)
+		}
+	}
+
+	var rows []*importRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, apperr.New(apperr.CodeBadRequest, "invalid CSV", err)
+		}
+		if len(rows) == maxImportRows {
+			return nil, apperr.New(apperr.CodeBadRequest, fmt.Sprintf("the file has more than %d rows", maxImportRows), nil)
+		}
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, newImportRow(line, columns, record))
+	}
+	return rows, nil
+}
+
+func newImportRow(line int, columns map[string]int, record []string) *importRow {
+	cell := func(name string) string {
+		if i, ok := columns[name]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	optional := func(name string) *string {
+		if v := cell(name); v != "" {
+			return &v
+		}
+		return nil
+	}
+
+	row := &importRow{
+		line: line,
+		body: dto.PatientRegisterPatientRequestDto{
+			Password:         cell("password"),
+			FirstName:        cell("first_name"),
+			LastName:         cell("last_name"),
+			Gender:           strings.ToLower(cell("gender")),
+			PhoneNumber:      cell("phone_number"),
+			HospitalID:       cell("hospital_id"),
+			IDCardNumber:     optional("id_card_number"),
+			Address:          optional("address"),
+			Allergies:        optional("allergies"),
+			EmergencyContact: optional("emergency_contact"),
+			BloodType:        optional("blood_type"),
+		},
+	}
+	if v := cell("birth_date"); v != "" {
+		birthDate, err := time.Parse(birthDateLayout, v)
+		if err != nil {
+			row.errors = append(row.errors, validation.NewFieldError("birth_date", "invalid", "", reflect.String))
+		} else {
+			row.body.BirthDate = &birthDate
+		}
+	}
+	for _, name := range strings.Split(cell("healthcare_entitlements"), entitlementSeparator) {
+		if name = strings.TrimSpace(name); name != "" {
+			row.entitlements = append(row.entitlements, name)
+		}
+	}
+	return row
+}
+
+// checkRows applies the registration rules to every row, plus the checks
+// that need the whole file: hospital IDs and ID card numbers must not repeat,
+// and entitlements must be in the catalog.
+func (s *ImportService) checkRows(rows []*importRow, catalog []*models.HealthcareEntitlement) {
+	known := make(map[string]bool, len(catalog))
+	for _, entitlement := range catalog {
+		known[entitlement.Name] = true
+	}
+	hospitalIDs := map[string]bool{}
+	idCards := map[string]bool{}
+
+	for _, row := range rows {
+		var err error
+		if row.body.Password == "" {
+			err = s.validate.StructExcept(&row.body, "Password")
+		} else {
+			err = s.validate.Struct(&row.body)
+		}
+		row.errors = append(row.errors, validation.Describe(err)...)
+
+		if id := row.body.HospitalID; id != "" {
+			if hospitalIDs[id] {
+				row.errors = append(row.errors, validation.NewFieldError("hospital_id", "unique", "", reflect.String))
+			}
+			hospitalIDs[id] = true
+		}
+		if card := row.body.IDCardNumber; card != nil {
+			if idCards[*card] {
+				row.errors = append(row.errors, validation.NewFieldError("id_card_number", "unique", "", reflect.String))
+			}
+			idCards[*card] = true
+		}
+		for _, name := range row.entitlements {
+			if !known[name] {
+				row.errors = append(row.errors, validation.NewFieldError("healthcare_entitlements", "exists", "", reflect.Slice))
+				break
+			}
+		}
+	}
+}
+
+// importPatient creates one patient in a savepoint, so a row the database
+// rejects does not undo the rest of its batch.
+func importPatient(ctx context.Context, tx repository.UnitOfWork, row *importRow) (string, error) {
+	password := utils.LockedPassword
+	if row.body.Password != "" {
+		hashed, err := utils.HashPassword(row.body.Password)
+		if err != nil {
+			return "", apperr.New(apperr.CodeInternal, "hash password failed", err)
+		}
+		password = hashed
+	}
+
+	user := &models.User{
+		ID:          utils.GenerateUUIDv7(),
+		Password:    password,
+		FirstName:   row.body.FirstName,
+		LastName:    row.body.LastName,
+		Gender:      row.body.Gender,
+		PhoneNumber: row.body.PhoneNumber,
+		Role:        models.PatientRole,
+	}
+	patient := &models.Patient{
+		UserID:           user.ID,
+		HospitalID:       row.body.HospitalID,
+		BirthDate:        row.body.BirthDate,
+		IDCardNumber:     row.body.IDCardNumber,
+		Address:          row.body.Address,
+		Allergies:        row.body.Allergies,
+		EmergencyContact: row.body.EmergencyContact,
+		BloodType:        row.body.BloodType,
+	}
+	err := tx.Do(ctx, func(tx repository.UnitOfWork) error {
+		if err := tx.Users().Create(ctx, user); err != nil {
+			return err
+		}
+		if err := tx.Patients().Create(ctx, patient); err != nil {
+			return err
+		}
+		return tx.Entitlements().AssignToPatient(ctx, user.ID.String(), row.entitlements)
+	})
+	if err != nil {
+		return "", err
+	}
+	return user.ID.String(), nil
+}
+
+// rowFieldErrors turns a rejected write into field errors for the report. It
+// returns nil for errors that are not about the row's data, which stop the
+// import.
+func rowFieldErrors(err error) []validation.FieldError {
+	var ae *apperr.Error
+	if !errors.As(err, &ae) || (ae.Code != apperr.CodeBadRequest && ae.Code != apperr.CodeConflict) {
+		return nil
+	}
+	names := make([]string, 0, len(ae.Fields))
+	for name := range ae.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var fields []validation.FieldError
+	for _, name := range names {
+		if fe, ok := ae.Fields[name].(validation.FieldError); ok {
+			fields = append(fields, fe)
+		}
+	}
+	if len(fields) == 0 {
+		fields = append(fields, validation.FieldError{
+			Rule:    "invalid",
+			Message: validation.Message{EN: ae.Msg, TH: ae.Msg},
+		})
+	}
+	return fields
+}
+
+func rowError(row *importRow) dto.PatientImportRowErrorDto {
+	return dto.PatientImportRowErrorDto{
+		Line:       row.line,
+		HospitalID: row.body.HospitalID,
+		Errors:     row.errors,
+	}
+}
@@ -0,0 +1,114 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"user-service/pkg/apperr"
+	"user-service/pkg/dto"
+	"user-service/pkg/repository/memory"
+	service "user-service/pkg/services"
+	"user-service/pkg/utils"
+)
+
+const importHeader = "hospital_id,first_name,last_name,gender,phone_number,birth_date,id_card_number,blood_type,healthcare_entitlements\n"
+
+func newImportService(t *testing.T) (*memory.Store, *service.ImportService) {
+	t.Helper()
+	store := memory.NewStore()
+	store.SeedEntitlements("Social Security", "Universal Coverage")
+	return store, service.NewImportService(store)
+}
+
+func runImport(t *testing.T, svc *service.ImportService, csv string, query dto.PatientImportQueryDto) *dto.PatientImportResponseDto {
+	t.Helper()
+	res, err := svc.ImportPatients(context.Background(), strings.NewReader(csv), &query)
+	if err != nil {
+		t.Fatalf("ImportPatients: %v", err)
+	}
+	return res
+}
+
+func TestImportPatients(t *testing.T) {
+	store, svc := newImportService(t)
+	csv := importHeader +
+		"HN-1,Somchai,Jaidee,male,0812345678,1985-03-15," + testIDCard + ",O+,Social Security; Universal Coverage\n" +
+		"HN-2,,Sriprasert,female,,15/03/1990,,,Gold Card\n" +
+		"HN-1,Nattapong,Wongsawat,male,,,,,\n" +
+		"HN-3,Sukanya,Sriprasert,Female,,,,,\n"
+
+	res := runImport(t, svc, csv, dto.PatientImportQueryDto{BatchSize: 1})
+	if res.Total != 4 || res.Imported != 2 || res.Failed != 2 {
+		t.Fatalf("total/imported/failed = %d/%d/%d, want 4/2/2", res.Total, res.Imported, res.Failed)
+	}
+	if len(res.UserIDs) != 2 {
+		t.Errorf("UserIDs = %v, want the 2 imported patients", res.UserIDs)
+	}
+
+	wantFields := map[int][]string{
+		3: {"birth_date", "first_name", "healthcare_entitlements"},
+		4: {"hospital_id"},
+	}
+	for _, row := range res.Errors {
+		var fields []string
+		for _, fe := range row.Errors {
+			fields = append(fields, fe.Field)
+		}
+		if strings.Join(fields, ",") != strings.Join(wantFields[row.Line], ",") {
+			t.Errorf("line %d errors = %v, want %v", row.Line, fields, wantFields[row.Line])
+		}
+	}
+
+	patient, err := store.Patients().FindByHospitalID(context.Background(), "HN-1")
+	if err != nil {
+		t.Fatalf("imported patient not found: %v", err)
+	}
+	entitlements, _ := store.Entitlements().FindByPatientID(context.Background(), patient.UserID.String())
+	if len(entitlements) != 2 {
+		t.Errorf("patient has %d entitlements, want 2", len(entitlements))
+	}
+	user, _ := store.Users().FindByID(context.Background(), patient.UserID.String())
+	if user.Gender != "male" || user.Password != utils.LockedPassword {
+		t.Errorf("user = %+v, want gender male and a locked password", user)
+	}
+}
+
+func TestImportPatientsDryRunWritesNothing(t *testing.T) {
+	store, svc := newImportService(t)
+	res := runImport(t, svc, importHeader+"HN-1,Somchai,Jaidee,male,,,,,\n", dto.PatientImportQueryDto{DryRun: true})
+	if res.Imported != 1 || len(res.UserIDs) != 0 {
+		t.Errorf("dry run imported = %d, UserIDs = %v; want 1 and none", res.Imported, res.UserIDs)
+	}
+	if _, err := store.Patients().FindByHospitalID(context.Background(), "HN-1"); !apperr.IsCode(err, apperr.CodeNotFound) {
+		t.Errorf("dry run wrote the patient: %v", err)
+	}
+}
+
+func TestImportPatientsReportsExistingPatients(t *testing.T) {
+	_, svc := newImportService(t)
+	csv := importHeader + "HN-1,Somchai,Jaidee,male,,," + testIDCard + ",,\n"
+	runImport(t, svc, csv, dto.PatientImportQueryDto{})
+
+	res := runImport(t, svc, csv+"HN-2,Sukanya,Sriprasert,female,,,,,\n", dto.PatientImportQueryDto{})
+	if res.Imported != 1 || res.Failed != 1 {
+		t.Fatalf("imported/failed = %d/%d, want 1/1", res.Imported, res.Failed)
+	}
+	if row := res.Errors[0]; row.Line != 2 || row.Errors[0].Rule != "unique" {
+		t.Errorf("error = %+v, want a unique violation on line 2", row)
+	}
+}
+
+func TestImportPatientsRejectsBadFiles(t *testing.T) {
+	_, svc := newImportService(t)
+	for name, csv := range map[string]string{
+		"empty":          "",
+		"unknown column": "hospital_id,first_name,last_name,gender,shoe_size\n",
+		"missing column": "hospital_id,first_name,last_name\n",
+		"ragged row":     importHeader + "HN-1,Somchai\n",
+	} {
+		_, err := svc.ImportPatients(context.Background(), strings.NewReader(csv), &dto.PatientImportQueryDto{})
+		if !apperr.IsCode(err, apperr.CodeBadRequest) {
+			t.Errorf("%s: err = %v, want a bad request", name, err)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"user-service/pkg/apperr"
+	"user-service/pkg/dto"
+	"user-service/pkg/encryption"
+	"user-service/pkg/repository"
+)
+
+// KeyRotationService re-encrypts patient data and profile history still
+// stored as plaintext or under a key-encryption key other than the active one.
+type KeyRotationService struct {
+	patientRepository repository.PatientRepository
+	historyRepository repository.ProfileChangeRepository
+	keyring           *encryption.Keyring
+}
+
+func NewKeyRotationService(patientRepo repository.PatientRepository, historyRepo repository.ProfileChangeRepository, keyring *encryption.Keyring) *KeyRotationService {
+	return &KeyRotationService{
+		patientRepository: patientRepo,
+		historyRepository: historyRepo,
+		keyring:           keyring,
+	}
+}
+
+// Rotate re-encrypts patients and then profile history in batches of
+// batchSize rows. The counts in the result cover both.
+func (s *KeyRotationService) Rotate(ctx context.Context, batchSize int) (*dto.KeyRotationResultDto, error) {
+	res := &dto.KeyRotationResultDto{}
+	if err := s.rotatePatients(ctx, batchSize, res); err != nil {
+		return res, err
+	}
+	return res, s.rotateHistory(ctx, batchSize, res)
+}
+
+func (s *KeyRotationService) rotatePatients(ctx context.Context, batchSize int, res *dto.KeyRotationResultDto) error {
+	after := ""
+	for {
+		rows, err := s.patientRepository.FindCiphertexts(ctx, after, batchSize)
+		if err != nil {
+			return apperr.Wrap(err, apperr.CodeInternal, "failed to read patients")
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		for _, row := range rows {
+			res.Scanned++
+			after = row.UserID
+			if !s.needsRotation(row) {
+				continue
+			}
+			if err := s.patientRepository.Reencrypt(ctx, row.UserID); err != nil {
+				return apperr.Wrap(err, apperr.CodeInternal, "re-encrypt patient "+row.UserID+" failed")
+			}
+			res.Rotated++
+		}
+	}
+}
+
+// BackfillBlindIndexes indexes the ID card numbers of patients written
+// before the blind index existed, batchSize rows at a time, so they can be
+// found by ID card number and are covered by its uniqueness. It returns the
+// number of patients indexed. A patient whose ID card number is already
+// indexed for another active patient is logged and left unindexed, so one
+// legacy duplicate does not stop the rest; it is tried again on the next run.
+func (s *KeyRotationService) BackfillBlindIndexes(ctx context.Context, batchSize int) (int, error) {
+	count := 0
+	after := ""
+	for {
+		ids, err := s.patientRepository.FindWithoutBlindIndex(ctx, after, batchSize)
+		if err != nil {
+			return count, apperr.Wrap(err, apperr.CodeInternal, "failed to read patients")
+		}
+		if len(ids) == 0 {
+			return count, nil
+		}
+		for _, id := range ids {
+			after = id
+			err := s.patientRepository.Reencrypt(ctx, id)
+			if apperr.IsCode(err, apperr.CodeConflict) {
+				slog.WarnContext(ctx, "patient ID card number duplicates another patient's, left unindexed", "patient_id", id)
+				continue
+			}
+			if err != nil {
+				return count, apperr.Wrap(err, apperr.CodeInternal, "index patient "+id+" failed")
+			}
+			count++
+		}
+	}
+}
+
+func (s *KeyRotationService) rotateHistory(ctx context.Context, batchSize int, res *dto.KeyRotationResultDto) error {
+	after := ""
+	for {
+		rows, err := s.historyRepository.FindCiphertexts(ctx, after, batchSize)
+		if err != nil {
+			return apperr.Wrap(err, apperr.CodeInternal, "failed to read profile history")
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		for _, row := range rows {
+			res.Scanned++
+			after = row.ID
+			if !s.anyNeedsRotation(row.OldValue, row.NewValue) {
+				continue
+			}
+			if err := s.historyRepository.Reencrypt(ctx, row.ID); err != nil {
+				return apperr.Wrap(err, apperr.CodeInternal, "re-encrypt profile change "+row.ID+" failed")
+			}
+			res.Rotated++
+		}
+	}
+}
+
+func (s *KeyRotationService) needsRotation(row *repository.PatientCiphertexts) bool {
+	if row.IDCardNumber != nil && row.IDCardBlindIndex == nil {
+		return true
+	}
+	return s.anyNeedsRotation(row.IDCardNumber, row.Address, row.Allergies, row.EmergencyContact)
+}
+
+func (s *KeyRotationService) anyNeedsRotation(values ...*string) bool {
+	for _, value := range values {
+		if value != nil && s.keyring.NeedsRotation(*value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,24 @@
+package service
+
+import "user-service/pkg/dto"
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// normalizePage applies defaults and bounds to a page query and returns the
+// resulting page, page size and row offset.
+func normalizePage(query *dto.PageQueryDto) (page, pageSize, offset int) {
+	page, pageSize = query.Page, query.PageSize
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+	return page, pageSize, (page - 1) * pageSize
+}
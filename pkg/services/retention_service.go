@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+	"user-service/pkg/apperr"
+	"user-service/pkg/dto"
+	"user-service/pkg/models"
+	"user-service/pkg/repository"
+
+	"github.com/google/uuid"
+)
+
+// errDryRun rolls back a purge transaction after counting the affected rows.
+var errDryRun = errors.New("dry run")
+
+// RetentionService manages soft-deleted rows: listing and restoring them,
+// and purging those older than the retention window for good.
+type RetentionService struct {
+	uow            repository.UnitOfWork
+	userRepository repository.UserRepository
+	retention      time.Duration
+}
+
+func NewRetentionService(
+	uow repository.UnitOfWork,
+	userRepo repository.UserRepository,
+	retention time.Duration,
+) *RetentionService {
+	return &RetentionService{
+		uow:            uow,
+		userRepository: userRepo,
+		retention:      retention,
+	}
+}
+
+func (s *RetentionService) ListDeletedUsers(ctx context.Context, role string, query *dto.PageQueryDto) (*dto.PageDto[*dto.DeletedUserResponseDto], error) {
+	switch models.Role(role) {
+	case "", models.PatientRole, models.DoctorRole, models.AdminRole:
+	default:
+		return nil, apperr.New(apperr.CodeBadRequest, "invalid role filter", nil)
+	}
+
+	page, pageSize, offset := normalizePage(query)
+	users, total, err := s.userRepository.FindDeleted(ctx, role, offset, pageSize)
+	if err != nil {
+		return nil, apperr.Wrap(err, apperr.CodeInternal, "failed to find deleted users")
+	}
+	items := make([]*dto.DeletedUserResponseDto, 0, len(users))
+	for _, user := range users {
+		items = append(items, &dto.DeletedUserResponseDto{
+			ID:        user.ID.String(),
+			FirstName: user.FirstName,
+			LastName:  user.LastName,
+			Role:      string(user.Role),
+			DeletedAt: user.DeletedAt.Time,
+		})
+	}
+	return &dto.PageDto[*dto.DeletedUserResponseDto]{Items: items, Page: page, PageSize: pageSize, Total: total}, nil
+}
+
+// RestoreUser undeletes a user together with their patient or doctor row.
+// Accounts closed at the patient's request cannot be restored.
+func (s *RetentionService) RestoreUser(ctx context.Context, userID string) (*dto.RestoreUserResponseDto, error) {
+	if _, err := uuid.Parse(userID); err != nil {
+		return nil, apperr.New(apperr.CodeBadRequest, "invalid user id", err)
+	}
+
+	err := auditedDo(ctx, s.uow, func(tx repository.UnitOfWork) error {
+		user, err := tx.Users().FindByIDUnscoped(ctx, userID)
+		if err != nil {
+			return apperr.Wrap(err, apperr.CodeInternal, "failed to find user")
+		}
+		if !user.DeletedAt.Valid {
+			return apperr.New(apperr.CodeConflict, "user is not deleted", nil)
+		}
+
+		request, err := tx.AccountDeletions().FindOpenByUserID(ctx, userID)
+		if err != nil && !apperr.IsCode(err, apperr.CodeNotFound) {
+			return apperr.Wrap(err, apperr.CodeInternal, "failed to find deletion request")
+		}
+		if err == nil && request.Status == models.DeletionRequestApproved {
+			return apperr.New(apperr.CodeConflict, "account was closed at the patient's request", nil)
+		}
+
+		if err := tx.Users().Restore(ctx, userID); err != nil {
+			return apperr.Wrap(err, apperr.CodeInternal, "restore user failed")
+		}
+		switch user.Role {
+		case models.PatientRole:
+			err = tx.Patients().Restore(ctx, userID)
+		case models.DoctorRole:
+			err = tx.Doctors().Restore(ctx, userID)
+		}
+		if err != nil {
+			return apperr.Wrap(err, apperr.CodeInternal, "restore "+string(user.Role)+" failed")
+		}
+		restored := &models.ProfileChange{Field: "deleted_at", OldValue: formatTime(&user.DeletedAt.Time)}
+		if err := tx.ProfileChanges().Create(ctx, stampChanges(ctx, user.ID, []*models.ProfileChange{restored})); err != nil {
+			return apperr.Wrap(err, apperr.CodeInternal, "record profile history failed")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &dto.RestoreUserResponseDto{Message: "User restored successfully"}, nil
+}
+
+// Purge hard-deletes rows soft-deleted longer ago than the retention window.
+// With dryRun the deletes run inside a transaction that is rolled back, so
+// the counts are exact but nothing is removed.
+func (s *RetentionService) Purge(ctx context.Context, dryRun bool) (*dto.PurgeResultDto, error) {
+	cutoff := time.Now().Add(-s.retention)
+	res := &dto.PurgeResultDto{}
+
+	err := s.uow.Do(ctx, func(tx repository.UnitOfWork) error {
+		var err error
+		if res.Patients, err = tx.Patients().PurgeDeleted(ctx, cutoff); err != nil {
+			return apperr.Wrap(err, apperr.CodeInternal, "purge patients failed")
+		}
+		if res.Doctors, err = tx.Doctors().PurgeDeleted(ctx, cutoff); err != nil {
+			return apperr.Wrap(err, apperr.CodeInternal, "purge doctors failed")
+		}
+		if res.Users, err = tx.Users().PurgeDeleted(ctx, cutoff); err != nil {
+			return apperr.Wrap(err, apperr.CodeInternal, "purge users failed")
+		}
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+	return res, nil
+}
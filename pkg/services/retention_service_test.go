@@ -0,0 +1,57 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"user-service/pkg/apperr"
+	"user-service/pkg/dto"
+	"user-service/pkg/models"
+	service "user-service/pkg/services"
+
+	"github.com/google/uuid"
+)
+
+func TestRestoreUser(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	userID := f.register(t, "HN0001", nil)
+	closedID := f.register(t, "HN0002", nil)
+	adminID := uuid.NewString()
+	admin := asRole(adminID, string(models.AdminRole))
+	svc := service.NewRetentionService(f.store, f.store.Users(), 0)
+
+	_, err := svc.RestoreUser(admin, userID)
+	assertCode(t, err, apperr.CodeConflict)
+
+	if err := f.store.Patients().Delete(ctx, userID); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.store.Users().Delete(ctx, userID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.RestoreUser(admin, userID); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if _, err := f.service.GetProfileByID(asUser(userID)); err != nil {
+		t.Errorf("profile after restore: %v", err)
+	}
+	history, _, err := f.store.ProfileChanges().FindByUserID(ctx, userID, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || history[0].Field != "deleted_at" || history[0].OldValue == nil || history[0].NewValue != nil ||
+		history[0].ActorRole != models.AdminRole || history[0].ActorID.String() != adminID {
+		t.Errorf("history = %+v, want the restore by %s", history, adminID)
+	}
+
+	accounts := service.NewAccountService(f.store, f.store.AccountDeletions(), 0)
+	request, err := accounts.RequestDeletion(asUser(closedID), &dto.RequestAccountDeletionRequestDto{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := accounts.ApproveDeletion(admin, request.ID, &dto.ReviewAccountDeletionRequestDto{}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = svc.RestoreUser(admin, closedID)
+	assertCode(t, err, apperr.CodeConflict)
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"user-service/pkg/apperr"
+	"user-service/pkg/db/fixtures"
+	"user-service/pkg/models"
+	"user-service/pkg/repository"
+	"user-service/pkg/utils"
+)
+
+// Seed profiles understood by SeedService.Seed.
+const (
+	// SeedProfileReference loads the reference data every environment needs.
+	SeedProfileReference = "reference"
+	// SeedProfileDemo adds demo accounts with a shared, published password.
+	SeedProfileDemo = "demo"
+)
+
+// seedProfiles lists the fixture files each profile loads, in order.
+var seedProfiles = map[string][]string{
+	SeedProfileReference: {"reference"},
+	SeedProfileDemo:      {"reference", "demo"},
+}
+
+// SeedResult counts the rows a seed run added.
+type SeedResult struct {
+	Entitlements int64
+	Patients     int
+	Doctors      int
+	Admins       int
+}
+
+// SeedService loads fixture data into the database. Seeding is idempotent:
+// entitlements and accounts that already exist are left as they are.
+type SeedService struct {
+	uow      repository.UnitOfWork
+	demoMode bool
+}
+
+// NewSeedService returns a SeedService; the demo profile is refused unless
+// demoMode is set.
+func NewSeedService(uow repository.UnitOfWork, demoMode bool) *SeedService {
+	return &SeedService{
+		uow:      uow,
+		demoMode: demoMode,
+	}
+}
+
+// Seed loads the named profile in one transaction.
+func (s *SeedService) Seed(ctx context.Context, profile string) (*SeedResult, error) {
+	files, ok := seedProfiles[profile]
+	if !ok {
+		return nil, apperr.New(apperr.CodeBadRequest, "unknown seed profile "+profile, nil)
+	}
+	if profile == SeedProfileDemo && !s.demoMode {
+		return nil, apperr.New(apperr.CodeForbidden, "the demo profile is only loaded with DEMO_MODE on", nil)
+	}
+
+	res := &SeedResult{}
+	err := s.uow.Do(ctx, func(tx repository.UnitOfWork) error {
+		for _, file := range files {
+			fixture, err := fixtures.Load(file)
+			if err != nil {
+				return apperr.New(apperr.CodeInternal, "failed to load seed fixture", err)
+			}
+			if err := s.seedFixture(ctx, tx, fixture, res); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, apperr.Wrap(err, apperr.CodeInternal, "seed failed")
+	}
+	return res, nil
+}
+
+func (s *SeedService) seedFixture(ctx context.Context, tx repository.UnitOfWork, fixture *fixtures.Fixture, res *SeedResult) error {
+	added, err := tx.Entitlements().EnsureExists(ctx, fixture.HealthcareEntitlements)
+	if err != nil {
+		return apperr.Wrap(err, apperr.CodeInternal, "failed to seed healthcare entitlements")
+	}
+	res.Entitlements += added
+
+	if len(fixture.Patients)+len(fixture.Doctors)+len(fixture.Admins) == 0 {
+		return nil
+	}
+	hashedPassword, err := utils.HashPassword(fixture.Password)
+	if err != nil {
+		return apperr.New(apperr.CodeInternal, "hash password failed", err)
+	}
+
+	for _, p := range fixture.Patients {
+		created, err := createSeedUser(ctx, tx, p.Account, models.PatientRole, hashedPassword)
+		if err != nil {
+			return err
+		}
+		if !created {
+			continue
+		}
+		patient := &models.Patient{
+			UserID:           p.ID,
+			HospitalID:       p.HospitalID,
+			BirthDate:        p.BirthDate,
+			IDCardNumber:     p.IDCardNumber,
+			Address:          p.Address,
+			Allergies:        p.Allergies,
+			EmergencyContact: p.EmergencyContact,
+			BloodType:        p.BloodType,
+		}
+		if err := tx.Patients().Create(ctx, patient); err != nil {
+			return apperr.Wrap(err, apperr.CodeInternal, "create patient failed")
+		}
+		if err := tx.Entitlements().AssignToPatient(ctx, p.ID.String(), p.HealthcareEntitlements); err != nil {
+			return apperr.Wrap(err, apperr.CodeInternal, "assign healthcare entitlements failed")
+		}
+		res.Patients++
+	}
+	for _, d := range fixture.Doctors {
+		created, err := createSeedUser(ctx, tx, d.Account, models.DoctorRole, hashedPassword)
+		if err != nil {
+			return err
+		}
+		if !created {
+			continue
+		}
+		doctor := &models.Doctor{
+			UserID:          d.ID,
+			Username:        d.Username,
+			Specialty:       d.Specialty,
+			Bio:             d.Bio,
+			YearsExperience: d.YearsExperience,
+		}
+		if err := tx.Doctors().Create(ctx, doctor); err != nil {
+			return apperr.Wrap(err, apperr.CodeInternal, "create doctor failed")
+		}
+		res.Doctors++
+	}
+	for _, a := range fixture.Admins {
+		created, err := createSeedUser(ctx, tx, a.Account, models.AdminRole, hashedPassword)
+		if err != nil {
+			return err
+		}
+		if !created {
+			continue
+		}
+		if err := tx.Admins().Create(ctx, &models.Admin{UserID: a.ID, Username: a.Username}); err != nil {
+			return apperr.Wrap(err, apperr.CodeInternal, "create admin failed")
+		}
+		res.Admins++
+	}
+	return nil
+}
+
+// createSeedUser creates the users row for a fixture account, or reports
+// false if an account with its ID already exists, even a deleted one.
+func createSeedUser(ctx context.Context, tx repository.UnitOfWork, account fixtures.Account, role models.Role, hashedPassword string) (bool, error) {
+	_, err := tx.Users().FindByIDUnscoped(ctx, account.ID.String())
+	if err == nil {
+		return false, nil
+	}
+	if !apperr.IsCode(err, apperr.CodeNotFound) {
+		return false, apperr.Wrap(err, apperr.CodeInternal, "failed to find user")
+	}
+
+	user := &models.User{
+		ID:          account.ID,
+		Password:    hashedPassword,
+		FirstName:   account.FirstName,
+		LastName:    account.LastName,
+		Gender:      account.Gender,
+		PhoneNumber: account.PhoneNumber,
+		Role:        role,
+	}
+	if err := tx.Users().Create(ctx, user); err != nil {
+		return false, apperr.Wrap(err, apperr.CodeInternal, "create user failed")
+	}
+	return true, nil
+}
@@ -3,47 +3,70 @@ package service
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"strings"
+	"time"
+	"unicode/utf8"
 	"user-service/pkg/apperr"
 	"user-service/pkg/clients"
 	"user-service/pkg/constants"
 	contextUtils "user-service/pkg/context"
 	"user-service/pkg/dto"
 	"user-service/pkg/jwt"
+	"user-service/pkg/metrics"
 	"user-service/pkg/models"
 	"user-service/pkg/repository"
+	"user-service/pkg/tracing"
 	"user-service/pkg/utils"
+	"user-service/pkg/validation"
 
-	"gorm.io/gorm"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+	minSearchQueryLen  = 2
 )
 
 type UserService struct {
-	db                *gorm.DB
-	userRepository    *repository.UserRepository
-	patientRepository *repository.PatientRepository
-	doctorRepository  *repository.DoctorRepository
+	uow               repository.UnitOfWork
+	userRepository    repository.UserRepository
+	patientRepository repository.PatientRepository
+	doctorRepository  repository.DoctorRepository
+	adminRepository   repository.AdminRepository
+	historyRepository repository.ProfileChangeRepository
 	userClient        *clients.UserClient
 	jwtService        *jwt.JwtService
 }
 
 func NewUserService(
-	db *gorm.DB,
-	userRepo *repository.UserRepository,
-	patientRepo *repository.PatientRepository,
-	doctorRepo *repository.DoctorRepository,
+	uow repository.UnitOfWork,
+	userRepo repository.UserRepository,
+	patientRepo repository.PatientRepository,
+	doctorRepo repository.DoctorRepository,
+	adminRepo repository.AdminRepository,
+	historyRepo repository.ProfileChangeRepository,
 	userClient *clients.UserClient,
 	jwtService *jwt.JwtService,
 ) *UserService {
 	return &UserService{
-		db:                db,
+		uow:               uow,
 		userRepository:    userRepo,
 		patientRepository: patientRepo,
 		doctorRepository:  doctorRepo,
+		adminRepository:   adminRepo,
+		historyRepository: historyRepo,
 		userClient:        userClient,
 		jwtService:        jwtService,
 	}
 }
 
-func (s *UserService) Register(ctx context.Context, body *dto.PatientRegisterPatientRequestDto) (*dto.PatientRegisterResponseDto, error) {
+func (s *UserService) Register(ctx context.Context, body *dto.PatientRegisterPatientRequestDto) (_ *dto.PatientRegisterResponseDto, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.Register")
+	defer tracing.End(span, &err)
+	defer func() { metrics.ObserveRegistration(constants.RolePatient, err) }()
+
 	user := &models.User{
 		ID:          utils.GenerateUUIDv7(),
 		FirstName:   body.FirstName,
@@ -63,63 +86,58 @@ func (s *UserService) Register(ctx context.Context, body *dto.PatientRegisterPat
 		BloodType:        body.BloodType,
 	}
 
+	if body.IDCardNumber != nil {
+		if err := s.ensureIDCardNumberAvailable(ctx, s.patientRepository, *body.IDCardNumber, ""); err != nil {
+			return &dto.PatientRegisterResponseDto{}, err
+		}
+	}
+
 	hashedPassword, err := utils.HashPassword(body.Password)
 	if err != nil {
 		return &dto.PatientRegisterResponseDto{}, apperr.New(apperr.CodeInternal, "hash password failed", err)
 	}
 	user.Password = hashedPassword
 
-	tx := s.db.Begin()
-	if tx.Error != nil {
-		return &dto.PatientRegisterResponseDto{}, apperr.New(apperr.CodeInternal, "begin transaction failed", tx.Error)
-	}
-
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
+	setAuditTargets(ctx, user.ID.String())
+	err = auditedDo(ctx, s.uow, func(tx repository.UnitOfWork) error {
+		if err := tx.Users().Create(ctx, user); err != nil {
+			return apperr.Wrap(err, apperr.CodeInternal, "create user failed")
 		}
-	}()
-
-	userRepo := repository.NewUserRepository(tx)
-	patientRepo := repository.NewPatientRepository(tx)
-
-	if err := userRepo.Create(ctx, user); err != nil {
-		tx.Rollback()
-		return &dto.PatientRegisterResponseDto{}, apperr.New(apperr.CodeInternal, "create user failed", err)
-	}
-
-	if err := patientRepo.Create(ctx, patient); err != nil {
-		tx.Rollback()
-		return &dto.PatientRegisterResponseDto{}, apperr.New(apperr.CodeInternal, "create patient failed", err)
-	}
-
-	if err := tx.Commit().Error; err != nil {
-		return &dto.PatientRegisterResponseDto{}, apperr.New(apperr.CodeInternal, "commit transaction failed", err)
+		if err := tx.Patients().Create(ctx, patient); err != nil {
+			return apperr.Wrap(err, apperr.CodeInternal, "create patient failed")
+		}
+		return nil
+	})
+	if err != nil {
+		return &dto.PatientRegisterResponseDto{}, apperr.Wrap(err, apperr.CodeInternal, "register patient failed")
 	}
 
-	return &dto.PatientRegisterResponseDto{Message: "User registered successfully"}, nil
+	return &dto.PatientRegisterResponseDto{Message: "User registered successfully", UserID: user.ID.String()}, nil
 }
 
-func (s *UserService) PatientLogin(ctx context.Context, body *dto.PatientLoginRequestDto) (*dto.PatientLoginResponseDto, error) {
+func (s *UserService) PatientLogin(ctx context.Context, body *dto.PatientLoginRequestDto) (_ *dto.PatientLoginResponseDto, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.PatientLogin")
+	defer tracing.End(span, &err)
+	defer func() { metrics.ObserveLogin(constants.RolePatient, err) }()
+
 	patient, err := s.patientRepository.FindByHospitalID(ctx, body.HospitalID)
-	if err != nil {
-		return nil, apperr.New(apperr.CodeInternal, "failed to find patient", err)
-	}
-	if patient == nil {
+	if apperr.IsCode(err, apperr.CodeNotFound) {
 		return nil, apperr.New(apperr.CodeUnauthorized, "invalid credentials", nil)
 	}
-
-	user, err := s.userRepository.FindByID(ctx, patient.UserID.String())
 	if err != nil {
-		return nil, apperr.New(apperr.CodeInternal, "failed to find user", err)
+		return nil, apperr.Wrap(err, apperr.CodeInternal, "failed to find patient")
 	}
-	if user == nil {
+
+	user, err := s.userRepository.FindByID(ctx, patient.UserID.String())
+	if apperr.IsCode(err, apperr.CodeNotFound) {
 		return nil, apperr.New(apperr.CodeUnauthorized, "invalid credentials", nil)
 	}
+	if err != nil {
+		return nil, apperr.Wrap(err, apperr.CodeInternal, "failed to find user")
+	}
 
 	ok, err := utils.VerifyPassword(body.Password, user.Password)
 	if !ok || err != nil {
-		fmt.Println(ok, err)
 		return nil, apperr.New(apperr.CodeUnauthorized, "invalid credentials", err)
 	}
 	// sign token
@@ -132,23 +150,26 @@ func (s *UserService) PatientLogin(ctx context.Context, body *dto.PatientLoginRe
 	}, nil
 }
 
-func (s *UserService) DoctorLogin(ctx context.Context, body *dto.DoctorLoginRequestDto) (*dto.DoctorLoginResponseDto, error) {
+func (s *UserService) DoctorLogin(ctx context.Context, body *dto.DoctorLoginRequestDto) (_ *dto.DoctorLoginResponseDto, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.DoctorLogin")
+	defer tracing.End(span, &err)
+	defer func() { metrics.ObserveLogin(constants.RoleDoctor, err) }()
+
 	doctor, err := s.doctorRepository.FindByUsername(ctx, body.Username)
-	if err != nil {
-		return nil, apperr.New(apperr.CodeInternal, "failed to find doctor", err)
-	}
-	fmt.Println("doctor", doctor)
-	if doctor == nil {
+	if apperr.IsCode(err, apperr.CodeNotFound) {
 		return nil, apperr.New(apperr.CodeUnauthorized, "invalid credentials", nil)
 	}
-
-	user, err := s.userRepository.FindByID(ctx, doctor.UserID.String())
 	if err != nil {
-		return nil, apperr.New(apperr.CodeInternal, "failed to find user", err)
+		return nil, apperr.Wrap(err, apperr.CodeInternal, "failed to find doctor")
 	}
-	if user == nil {
+
+	user, err := s.userRepository.FindByID(ctx, doctor.UserID.String())
+	if apperr.IsCode(err, apperr.CodeNotFound) {
 		return nil, apperr.New(apperr.CodeUnauthorized, "invalid credentials", nil)
 	}
+	if err != nil {
+		return nil, apperr.Wrap(err, apperr.CodeInternal, "failed to find user")
+	}
 
 	ok, err := utils.VerifyPassword(body.Password, user.Password)
 	if !ok || err != nil {
@@ -165,16 +186,54 @@ func (s *UserService) DoctorLogin(ctx context.Context, body *dto.DoctorLoginRequ
 
 }
 
-func (s *UserService) GetProfileByID(ctx context.Context) (*dto.GetProfileResponseDto, error) {
+func (s *UserService) AdminLogin(ctx context.Context, body *dto.AdminLoginRequestDto) (_ *dto.AdminLoginResponseDto, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.AdminLogin")
+	defer tracing.End(span, &err)
+	defer func() { metrics.ObserveLogin(constants.RoleAdmin, err) }()
+
+	admin, err := s.adminRepository.FindByUsername(ctx, body.Username)
+	if apperr.IsCode(err, apperr.CodeNotFound) {
+		return nil, apperr.New(apperr.CodeUnauthorized, "invalid credentials", nil)
+	}
+	if err != nil {
+		return nil, apperr.Wrap(err, apperr.CodeInternal, "failed to find admin")
+	}
+
+	user, err := s.userRepository.FindByID(ctx, admin.UserID.String())
+	if apperr.IsCode(err, apperr.CodeNotFound) {
+		return nil, apperr.New(apperr.CodeUnauthorized, "invalid credentials", nil)
+	}
+	if err != nil {
+		return nil, apperr.Wrap(err, apperr.CodeInternal, "failed to find user")
+	}
+
+	ok, err := utils.VerifyPassword(body.Password, user.Password)
+	if !ok || err != nil {
+		return nil, apperr.New(apperr.CodeUnauthorized, "invalid credentials", err)
+	}
+	// sign token
+	token, err := s.jwtService.GenerateToken(user.ID.String(), constants.RoleAdmin)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to generate token", err)
+	}
+	return &dto.AdminLoginResponseDto{
+		AccessToken: token,
+	}, nil
+}
+
+func (s *UserService) GetProfileByID(ctx context.Context) (_ *dto.GetProfileResponseDto, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetProfileByID")
+	defer tracing.End(span, &err)
+
 	userID := contextUtils.GetUserId(ctx)
 	user, err := s.userRepository.FindByID(ctx, userID)
 	if err != nil {
-		return nil, apperr.New(apperr.CodeNotFound, "user not found", err)
+		return nil, apperr.Wrap(err, apperr.CodeInternal, "failed to find user")
 	}
 
 	patient, err := s.patientRepository.FindByUserID(ctx, userID)
 	if err != nil {
-		return nil, apperr.New(apperr.CodeNotFound, "patient profile not found", err)
+		return nil, apperr.Wrap(err, apperr.CodeInternal, "failed to find patient")
 	}
 
 	res := &dto.GetProfileResponseDto{
@@ -190,22 +249,23 @@ func (s *UserService) GetProfileByID(ctx context.Context) (*dto.GetProfileRespon
 		Allergies:        patient.Allergies,
 		EmergencyContact: patient.EmergencyContact,
 		BloodType:        patient.BloodType,
+		ETag:             profileETag(user, patient),
 	}
 	return res, nil
 }
 
-func (s *UserService) GetPatientByID(ctx context.Context, patientID string) (*dto.GetProfileResponseDto, error) {
+func (s *UserService) GetPatientByID(ctx context.Context, patientID string) (_ *dto.GetProfileResponseDto, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetPatientByID")
+	defer tracing.End(span, &err)
+
 	user, err := s.userRepository.FindByID(ctx, patientID)
 	if err != nil {
-		return nil, apperr.New(apperr.CodeNotFound, "user not found", err)
+		return nil, apperr.Wrap(err, apperr.CodeInternal, "failed to find user")
 	}
 
 	patient, err := s.patientRepository.FindByUserID(ctx, patientID)
 	if err != nil {
-		return nil, apperr.New(apperr.CodeNotFound, "patient not found", err)
-	}
-	if patient == nil {
-		return nil, apperr.New(apperr.CodeNotFound, "patient data not found", nil)
+		return nil, apperr.Wrap(err, apperr.CodeInternal, "failed to find patient")
 	}
 
 	res := &dto.GetProfileResponseDto{
@@ -225,18 +285,53 @@ func (s *UserService) GetPatientByID(ctx context.Context, patientID string) (*dt
 	return res, nil
 }
 
-func (s *UserService) GetDoctorByID(ctx context.Context, doctorID string) (*dto.GetDoctorProfileResponseDto, error) {
+// SetPatientPassword replaces a patient's password, which gives patients
+// imported without one a way to sign in. Only the patient's users row
+// changes; the old password is not needed.
+func (s *UserService) SetPatientPassword(ctx context.Context, patientID string, body *dto.SetPatientPasswordRequestDto) (_ *dto.SetPatientPasswordResponseDto, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.SetPatientPassword")
+	defer tracing.End(span, &err)
+
+	if _, err := uuid.Parse(patientID); err != nil {
+		return nil, apperr.New(apperr.CodeBadRequest, "invalid patient id", err)
+	}
+	hashedPassword, err := utils.HashPassword(body.Password)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "hash password failed", err)
+	}
+
+	err = auditedDo(ctx, s.uow, func(tx repository.UnitOfWork) error {
+		user, err := tx.Users().FindByID(ctx, patientID)
+		if err != nil {
+			return apperr.Wrap(err, apperr.CodeInternal, "failed to find user")
+		}
+		if user.Role != models.PatientRole {
+			return apperr.New(apperr.CodeNotFound, "patient not found", nil)
+		}
+		user.Password = hashedPassword
+		if err := tx.Users().Update(ctx, user, "password"); err != nil {
+			return apperr.Wrap(err, apperr.CodeInternal, "update password failed")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &dto.SetPatientPasswordResponseDto{Message: "Password set successfully"}, nil
+}
+
+func (s *UserService) GetDoctorByID(ctx context.Context, doctorID string) (_ *dto.GetDoctorProfileResponseDto, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetDoctorByID")
+	defer tracing.End(span, &err)
+
 	user, err := s.userRepository.FindByID(ctx, doctorID)
 	if err != nil {
-		return nil, apperr.New(apperr.CodeNotFound, "user not found", err)
+		return nil, apperr.Wrap(err, apperr.CodeInternal, "failed to find user")
 	}
 
 	doctor, err := s.doctorRepository.FindByUserID(ctx, doctorID)
 	if err != nil {
-		return nil, apperr.New(apperr.CodeNotFound, "doctor not found", err)
-	}
-	if doctor == nil {
-		return nil, apperr.New(apperr.CodeNotFound, "doctor data not found", nil)
+		return nil, apperr.Wrap(err, apperr.CodeInternal, "failed to find doctor")
 	}
 
 	res := &dto.GetDoctorProfileResponseDto{
@@ -253,94 +348,235 @@ func (s *UserService) GetDoctorByID(ctx context.Context, doctorID string) (*dto.
 	return res, nil
 }
 
-func (s *UserService) UpdateProfileByID(ctx context.Context, body *dto.UpdatePatientProfileRequestDto) (*dto.UpdatePatientProfileResponseDto, error) {
-	userID := contextUtils.GetUserId(ctx)
+// UpdateProfileByID applies the provided fields to the caller's profile.
+// ifMatch must hold the ETag the client last read: updates without one are
+// refused, and updates based on an older version of the profile fail rather
+// than overwrite the newer one. Both rows are locked before the tag is
+// compared, so a write that changes only the row this update leaves alone
+// cannot land unnoticed in between. Only columns whose value changes are
+// written, and each of them is recorded in the profile history.
+func (s *UserService) UpdateProfileByID(ctx context.Context, ifMatch string, body *dto.UpdatePatientProfileRequestDto) (_ *dto.UpdatePatientProfileResponseDto, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.UpdateProfileByID")
+	defer tracing.End(span, &err)
 
-	tx := s.db.Begin()
-	if tx.Error != nil {
-		return nil, apperr.New(apperr.CodeInternal, "begin transaction failed", tx.Error)
+	if ifMatch == "" {
+		return nil, apperr.New(apperr.CodePreconditionRequired, "If-Match header is required", nil)
 	}
+	userID := contextUtils.GetUserId(ctx)
 
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
+	var etag string
+	err = auditedDo(ctx, s.uow, func(tx repository.UnitOfWork) error {
+		user, err := tx.Users().FindByIDForUpdate(ctx, userID)
+		if err != nil {
+			return apperr.Wrap(err, apperr.CodeInternal, "failed to find user")
 		}
-	}()
 
-	userRepo := repository.NewUserRepository(tx)
-	patientRepo := repository.NewPatientRepository(tx)
+		patient, err := tx.Patients().FindByUserIDForUpdate(ctx, userID)
+		if err != nil {
+			return apperr.Wrap(err, apperr.CodeInternal, "failed to find patient")
+		}
 
-	user, err := userRepo.FindByID(ctx, userID)
-	if err == gorm.ErrRecordNotFound {
-		tx.Rollback()
-		return nil, apperr.New(apperr.CodeNotFound, "user not found", err)
-	}
+		if !etagMatches(ifMatch, profileETag(user, patient)) {
+			return apperr.New(apperr.CodePreconditionFailed, "profile was modified since it was read", nil)
+		}
+
+		var userDiff profileDiff
+		userDiff.setField("first_name", &user.FirstName, body.FirstName)
+		userDiff.setField("last_name", &user.LastName, body.LastName)
+		userDiff.setField("phone_number", &user.PhoneNumber, body.PhoneNumber)
+
+		var patientDiff profileDiff
+		patientDiff.setTime("birth_date", &patient.BirthDate, body.BirthDate)
+		if patientDiff.setOptionalField("id_card_number", &patient.IDCardNumber, body.IDCardNumber) {
+			if err := s.ensureIDCardNumberAvailable(ctx, tx.Patients(), *body.IDCardNumber, userID); err != nil {
+				return err
+			}
+		}
+		patientDiff.setOptionalField("address", &patient.Address, body.Address)
+		patientDiff.setOptionalField("allergies", &patient.Allergies, body.Allergies)
+		patientDiff.setOptionalField("emergency_contact", &patient.EmergencyContact, body.EmergencyContact)
+		patientDiff.setOptionalField("blood_type", &patient.BloodType, body.BloodType)
+
+		if err := tx.Users().Update(ctx, user, userDiff.columns...); err != nil {
+			return apperr.Wrap(err, apperr.CodeInternal, "update user failed")
+		}
+		if err := tx.Patients().Update(ctx, patient, patientDiff.columns...); err != nil {
+			return apperr.Wrap(err, apperr.CodeInternal, "update patient failed")
+		}
+		changes := append(userDiff.changes, patientDiff.changes...)
+		if err := tx.ProfileChanges().Create(ctx, stampChanges(ctx, user.ID, changes)); err != nil {
+			return apperr.Wrap(err, apperr.CodeInternal, "record profile history failed")
+		}
+		etag = profileETag(user, patient)
+		return nil
+	})
 	if err != nil {
-		tx.Rollback()
-		return nil, apperr.New(apperr.CodeInternal, "failed to find user", err)
+		return nil, apperr.Wrap(err, apperr.CodeInternal, "update profile failed")
 	}
 
-	patient, err := patientRepo.FindByUserID(ctx, userID)
-	if err != nil {
-		tx.Rollback()
-		return nil, apperr.New(apperr.CodeInternal, "failed to find patient", err)
+	return &dto.UpdatePatientProfileResponseDto{Message: "Profile updated successfully", ETag: etag}, nil
+}
+
+// profileETag is the strong entity tag of a patient profile, which spans the
+// user and patient rows and so changes whenever either version does.
+func profileETag(user *models.User, patient *models.Patient) string {
+	return fmt.Sprintf(`"%d-%d"`, user.Version, patient.Version)
+}
+
+// etagMatches reports whether an If-Match header value, a comma-separated
+// list of entity tags or "*", matches etag. Weak tags never match, as
+// If-Match requires strong comparison.
+func etagMatches(ifMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag {
+			return true
+		}
 	}
+	return false
+}
+
+// profileDiff collects what an update changes on one row: the columns to
+// write and a history entry for each of them.
+type profileDiff struct {
+	columns []string
+	changes []*models.ProfileChange
+}
+
+func (d *profileDiff) record(column string, oldValue, newValue *string) {
+	d.columns = append(d.columns, column)
+	d.changes = append(d.changes, &models.ProfileChange{Field: column, OldValue: oldValue, NewValue: newValue})
+}
 
-	// Update user fields if provided
-	if body.FirstName != nil {
-		user.FirstName = *body.FirstName
+// setField assigns value to *field when it is provided and differs, and
+// reports whether it did.
+func (d *profileDiff) setField(column string, field *string, value *string) bool {
+	if value == nil || *value == *field {
+		return false
 	}
-	if body.LastName != nil {
-		user.LastName = *body.LastName
+	oldValue := *field
+	*field = *value
+	d.record(column, &oldValue, value)
+	return true
+}
+
+// setOptionalField is setField for nullable columns.
+func (d *profileDiff) setOptionalField(column string, field **string, value *string) bool {
+	if value == nil || (*field != nil && **field == *value) {
+		return false
 	}
-	if body.PhoneNumber != nil {
-		user.PhoneNumber = *body.PhoneNumber
+	d.record(column, *field, value)
+	*field = value
+	return true
+}
+
+// setTime is setOptionalField for timestamps, which are recorded in RFC 3339.
+func (d *profileDiff) setTime(column string, field **time.Time, value *time.Time) bool {
+	if value == nil || (*field != nil && (*field).Equal(*value)) {
+		return false
 	}
+	d.record(column, formatTime(*field), formatTime(value))
+	*field = value
+	return true
+}
 
-	// Update patient fields if provided
-	if body.BirthDate != nil {
-		patient.BirthDate = body.BirthDate
+func formatTime(t *time.Time) *string {
+	if t == nil {
+		return nil
 	}
-	if body.IDCardNumber != nil {
-		patient.IDCardNumber = body.IDCardNumber
+	s := t.Format(time.RFC3339)
+	return &s
+}
+
+// stampChanges sets the identity, subject and actor of history entries
+// recorded for userID by the caller in ctx.
+func stampChanges(ctx context.Context, userID uuid.UUID, changes []*models.ProfileChange) []*models.ProfileChange {
+	now := time.Now()
+	var actorID *uuid.UUID
+	if id, err := uuid.Parse(contextUtils.GetUserId(ctx)); err == nil {
+		actorID = &id
+	}
+	for _, change := range changes {
+		change.ID = utils.GenerateUUIDv7()
+		change.UserID = userID
+		change.ActorID = actorID
+		change.ActorRole = models.Role(contextUtils.GetRole(ctx))
+		change.ChangedAt = now
+	}
+	return changes
+}
+
+// GetProfileHistory lists a page of the profile changes of a patient, newest
+// first. Patients may only read their own history.
+func (s *UserService) GetProfileHistory(ctx context.Context, patientID string, query *dto.PageQueryDto) (_ *dto.PageDto[*dto.ProfileChangeResponseDto], err error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetProfileHistory")
+	defer tracing.End(span, &err)
+
+	if _, err := uuid.Parse(patientID); err != nil {
+		return nil, apperr.New(apperr.CodeBadRequest, "invalid patient id", err)
 	}
-	if body.Address != nil {
-		patient.Address = body.Address
+	if contextUtils.GetRole(ctx) == constants.RolePatient && contextUtils.GetUserId(ctx) != patientID {
+		return nil, apperr.New(apperr.CodeForbidden, "patients may only read their own history", nil)
 	}
-	if body.Allergies != nil {
-		patient.Allergies = body.Allergies
+	if _, err := s.patientRepository.FindByUserID(ctx, patientID); err != nil {
+		return nil, apperr.Wrap(err, apperr.CodeInternal, "failed to find patient")
 	}
-	if body.EmergencyContact != nil {
-		patient.EmergencyContact = body.EmergencyContact
+
+	page, pageSize, offset := normalizePage(query)
+	changes, total, err := s.historyRepository.FindByUserID(ctx, patientID, offset, pageSize)
+	if err != nil {
+		return nil, apperr.Wrap(err, apperr.CodeInternal, "failed to find profile history")
 	}
-	if body.BloodType != nil {
-		patient.BloodType = body.BloodType
+	items := make([]*dto.ProfileChangeResponseDto, 0, len(changes))
+	for _, change := range changes {
+		items = append(items, toProfileChangeDto(change))
 	}
+	return &dto.PageDto[*dto.ProfileChangeResponseDto]{Items: items, Page: page, PageSize: pageSize, Total: total}, nil
+}
 
-	// Save user and patient
-	if err := userRepo.Update(ctx, user); err != nil {
-		tx.Rollback()
-		return nil, apperr.New(apperr.CodeInternal, "update user failed", err)
+func toProfileChangeDto(change *models.ProfileChange) *dto.ProfileChangeResponseDto {
+	res := &dto.ProfileChangeResponseDto{
+		ID:        change.ID.String(),
+		Field:     change.Field,
+		OldValue:  change.OldValue,
+		NewValue:  change.NewValue,
+		ActorRole: string(change.ActorRole),
+		ChangedAt: change.ChangedAt,
 	}
-	if err := patientRepo.Update(ctx, patient); err != nil {
-		tx.Rollback()
-		return nil, apperr.New(apperr.CodeInternal, "update patient failed", err)
+	if change.ActorID != nil {
+		actorID := change.ActorID.String()
+		res.ActorID = &actorID
 	}
+	return res
+}
 
-	if err := tx.Commit().Error; err != nil {
-		return nil, apperr.New(apperr.CodeInternal, "commit transaction failed", err)
+// ensureIDCardNumberAvailable returns a conflict if another active patient
+// than ownerID already holds the ID card number.
+func (s *UserService) ensureIDCardNumberAvailable(ctx context.Context, patientRepo repository.PatientRepository, idCardNumber, ownerID string) error {
+	existing, err := patientRepo.FindByIDCardNumber(ctx, idCardNumber)
+	if apperr.IsCode(err, apperr.CodeNotFound) {
+		return nil
 	}
-
-	return &dto.UpdatePatientProfileResponseDto{Message: "Profile updated successfully"}, nil
+	if err != nil {
+		return apperr.Wrap(err, apperr.CodeInternal, "failed to check id card number")
+	}
+	if existing.UserID.String() == ownerID {
+		return nil
+	}
+	return apperr.New(apperr.CodeConflict, "id card number already registered", nil).
+		WithField("id_card_number", validation.NewFieldError("id_card_number", "unique", "", reflect.String))
 }
 
-func (s *UserService) GetDoctorsByIDs(ctx context.Context, doctorIDs []string) ([]*dto.GetDoctorProfileResponseDto, error) {
+func (s *UserService) GetDoctorsByIDs(ctx context.Context, doctorIDs []string) (_ []*dto.GetDoctorProfileResponseDto, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetDoctorsByIDs")
+	defer tracing.End(span, &err)
+
 	if len(doctorIDs) == 0 {
 		return []*dto.GetDoctorProfileResponseDto{}, nil
 	}
 	doctors, err := s.userRepository.FindManyDoctorsByIDs(ctx, doctorIDs)
 	if err != nil {
-		return nil, apperr.New(apperr.CodeInternal, "failed to find doctors", err)
+		return nil, apperr.Wrap(err, apperr.CodeInternal, "failed to find doctors")
 	}
 	var result []*dto.GetDoctorProfileResponseDto
 	for _, user := range doctors {
@@ -363,13 +599,16 @@ func (s *UserService) GetDoctorsByIDs(ctx context.Context, doctorIDs []string) (
 	return result, nil
 }
 
-func (s *UserService) GetPatientsByIDs(ctx context.Context, patientIDs []string) ([]*dto.GetProfileResponseDto, error) {
+func (s *UserService) GetPatientsByIDs(ctx context.Context, patientIDs []string) (_ []*dto.GetProfileResponseDto, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetPatientsByIDs")
+	defer tracing.End(span, &err)
+
 	if len(patientIDs) == 0 {
 		return []*dto.GetProfileResponseDto{}, nil
 	}
 	patients, err := s.userRepository.FindManyPatientsByIDs(ctx, patientIDs)
 	if err != nil {
-		return nil, apperr.New(apperr.CodeInternal, "failed to find patients", err)
+		return nil, apperr.Wrap(err, apperr.CodeInternal, "failed to find patients")
 	}
 	var result []*dto.GetProfileResponseDto
 	for _, user := range patients {
@@ -395,10 +634,13 @@ func (s *UserService) GetPatientsByIDs(ctx context.Context, patientIDs []string)
 	return result, nil
 }
 
-func (s *UserService) GetAllDoctors(ctx context.Context) ([]*dto.GetDoctorProfileResponseDto, error) {
+func (s *UserService) GetAllDoctors(ctx context.Context) (_ []*dto.GetDoctorProfileResponseDto, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetAllDoctors")
+	defer tracing.End(span, &err)
+
 	doctors, err := s.doctorRepository.FindAll(ctx)
 	if err != nil {
-		return nil, apperr.New(apperr.CodeInternal, "failed to find doctors", err)
+		return nil, apperr.Wrap(err, apperr.CodeInternal, "failed to find doctors")
 	}
 	var result []*dto.GetDoctorProfileResponseDto
 	for _, doctor := range doctors {
@@ -417,3 +659,79 @@ func (s *UserService) GetAllDoctors(ctx context.Context) ([]*dto.GetDoctorProfil
 	}
 	return result, nil
 }
+
+func normalizeSearch(query *dto.SearchRequestDto) (string, int, error) {
+	term := strings.TrimSpace(query.Query)
+	if utf8.RuneCountInString(term) < minSearchQueryLen {
+		return "", 0, apperr.New(apperr.CodeBadRequest, fmt.Sprintf("search query must be at least %d characters", minSearchQueryLen), nil)
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+	return term, limit, nil
+}
+
+func (s *UserService) SearchDoctors(ctx context.Context, query *dto.SearchRequestDto) (_ []*dto.GetDoctorProfileResponseDto, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.SearchDoctors")
+	defer tracing.End(span, &err)
+
+	term, limit, err := normalizeSearch(query)
+	if err != nil {
+		return nil, err
+	}
+	doctors, err := s.doctorRepository.Search(ctx, term, limit)
+	if err != nil {
+		return nil, apperr.Wrap(err, apperr.CodeInternal, "failed to search doctors")
+	}
+	result := make([]*dto.GetDoctorProfileResponseDto, 0, len(doctors))
+	for _, doctor := range doctors {
+		result = append(result, &dto.GetDoctorProfileResponseDto{
+			ID:              doctor.User.ID.String(),
+			FirstName:       doctor.User.FirstName,
+			LastName:        doctor.User.LastName,
+			Gender:          doctor.User.Gender,
+			PhoneNumber:     doctor.User.PhoneNumber,
+			Username:        doctor.Username,
+			Specialty:       doctor.Specialty,
+			Bio:             doctor.Bio,
+			YearsExperience: doctor.YearsExperience,
+		})
+	}
+	return result, nil
+}
+
+func (s *UserService) SearchPatients(ctx context.Context, query *dto.SearchRequestDto) (_ []*dto.GetProfileResponseDto, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.SearchPatients")
+	defer tracing.End(span, &err)
+
+	term, limit, err := normalizeSearch(query)
+	if err != nil {
+		return nil, err
+	}
+	patients, err := s.patientRepository.Search(ctx, term, limit)
+	if err != nil {
+		return nil, apperr.Wrap(err, apperr.CodeInternal, "failed to search patients")
+	}
+	result := make([]*dto.GetProfileResponseDto, 0, len(patients))
+	for _, patient := range patients {
+		result = append(result, &dto.GetProfileResponseDto{
+			ID:               patient.User.ID.String(),
+			FirstName:        patient.User.FirstName,
+			LastName:         patient.User.LastName,
+			Gender:           patient.User.Gender,
+			PhoneNumber:      patient.User.PhoneNumber,
+			HospitalID:       patient.HospitalID,
+			BirthDate:        patient.BirthDate,
+			IDCardNumber:     patient.IDCardNumber,
+			Address:          patient.Address,
+			Allergies:        patient.Allergies,
+			EmergencyContact: patient.EmergencyContact,
+			BloodType:        patient.BloodType,
+		})
+	}
+	return result, nil
+}
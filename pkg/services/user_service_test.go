@@ -0,0 +1,439 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"user-service/pkg/apperr"
+	"user-service/pkg/constants"
+	contextUtils "user-service/pkg/context"
+	"user-service/pkg/dto"
+	"user-service/pkg/jwt"
+	"user-service/pkg/models"
+	"user-service/pkg/repository/memory"
+	service "user-service/pkg/services"
+	"user-service/pkg/utils"
+
+	"github.com/google/uuid"
+)
+
+const (
+	testPassword = "correct-horse-battery"
+	testIDCard   = "1101700203450"
+	otherIDCard  = "3101001234565"
+)
+
+type fixture struct {
+	store   *memory.Store
+	service *service.UserService
+	jwt     *jwt.JwtService
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	store := memory.NewStore()
+	jwtService := jwt.NewJwtService("test-secret", 3600)
+	svc := service.NewUserService(store, store.Users(), store.Patients(), store.Doctors(), store.Admins(), store.ProfileChanges(), nil, jwtService)
+	return &fixture{store: store, service: svc, jwt: jwtService}
+}
+
+func (f *fixture) register(t *testing.T, hospitalID string, idCard *string) string {
+	t.Helper()
+	_, err := f.service.Register(context.Background(), &dto.PatientRegisterPatientRequestDto{
+		Password:     testPassword,
+		FirstName:    "Somchai",
+		LastName:     "Jaidee",
+		Gender:       models.Male,
+		PhoneNumber:  "0812345678",
+		HospitalID:   hospitalID,
+		IDCardNumber: idCard,
+	})
+	if err != nil {
+		t.Fatalf("register %s: %v", hospitalID, err)
+	}
+	patient, err := f.store.Patients().FindByHospitalID(context.Background(), hospitalID)
+	if err != nil {
+		t.Fatalf("find registered patient: %v", err)
+	}
+	return patient.UserID.String()
+}
+
+func (f *fixture) addDoctor(t *testing.T, username string) string {
+	t.Helper()
+	ctx := context.Background()
+	hash, err := utils.HashPassword(testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &models.User{
+		ID:        utils.GenerateUUIDv7(),
+		Password:  hash,
+		FirstName: "Ploy",
+		LastName:  "Srisuk",
+		Gender:    models.Female,
+		Role:      models.DoctorRole,
+	}
+	if err := f.store.Users().Create(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	specialty := "Cardiology"
+	if err := f.store.Doctors().Create(ctx, &models.Doctor{UserID: user.ID, Username: username, Specialty: &specialty}); err != nil {
+		t.Fatal(err)
+	}
+	return user.ID.String()
+}
+
+func asUser(userID string) context.Context {
+	return asRole(userID, constants.RolePatient)
+}
+
+func asRole(userID, role string) context.Context {
+	ctx := context.WithValue(context.Background(), contextUtils.ContextKeyUserID, userID)
+	return context.WithValue(ctx, contextUtils.ContextKeyRole, role)
+}
+
+func assertCode(t *testing.T, err error, code apperr.Code) {
+	t.Helper()
+	if !apperr.IsCode(err, code) {
+		t.Fatalf("expected %s error, got %v", code, err)
+	}
+}
+
+func ptr(s string) *string { return &s }
+
+func TestRegister(t *testing.T) {
+	f := newFixture(t)
+	userID := f.register(t, "HN0001", ptr(testIDCard))
+
+	user, err := f.store.Users().FindByID(context.Background(), userID)
+	if err != nil {
+		t.Fatalf("find user: %v", err)
+	}
+	if user.Role != constants.RolePatient {
+		t.Errorf("role = %q, want %q", user.Role, constants.RolePatient)
+	}
+	if user.Password == testPassword {
+		t.Error("password stored in plaintext")
+	}
+}
+
+func TestRegisterRejectsDuplicates(t *testing.T) {
+	f := newFixture(t)
+	f.register(t, "HN0001", ptr(testIDCard))
+
+	tests := []struct {
+		name       string
+		hospitalID string
+		idCard     *string
+		field      string
+	}{
+		{"hospital id", "HN0001", nil, "hospital_id"},
+		{"id card number", "HN0002", ptr(testIDCard), "id_card_number"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.service.Register(context.Background(), &dto.PatientRegisterPatientRequestDto{
+				Password:     testPassword,
+				FirstName:    "Somsri",
+				LastName:     "Jaidee",
+				Gender:       models.Female,
+				HospitalID:   tt.hospitalID,
+				IDCardNumber: tt.idCard,
+			})
+			assertCode(t, err, apperr.CodeConflict)
+			if _, ok := err.(*apperr.Error).Fields[tt.field]; !ok {
+				t.Errorf("expected error on field %s, got %v", tt.field, err.(*apperr.Error).Fields)
+			}
+		})
+	}
+}
+
+func TestPatientLogin(t *testing.T) {
+	f := newFixture(t)
+	userID := f.register(t, "HN0001", nil)
+
+	res, err := f.service.PatientLogin(context.Background(), &dto.PatientLoginRequestDto{HospitalID: "HN0001", Password: testPassword})
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	claims, err := f.jwt.Parse(res.AccessToken)
+	if err != nil {
+		t.Fatalf("parse token: %v", err)
+	}
+	if claims.UserID != userID || claims.Role != constants.RolePatient {
+		t.Errorf("claims = %+v, want user %s with role patient", claims, userID)
+	}
+
+	_, err = f.service.PatientLogin(context.Background(), &dto.PatientLoginRequestDto{HospitalID: "HN0001", Password: "wrong-password"})
+	assertCode(t, err, apperr.CodeUnauthorized)
+	_, err = f.service.PatientLogin(context.Background(), &dto.PatientLoginRequestDto{HospitalID: "HN9999", Password: testPassword})
+	assertCode(t, err, apperr.CodeUnauthorized)
+}
+
+func TestSetPatientPassword(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	res := runImport(t, service.NewImportService(f.store), importHeader+"HN-1,Somchai,Jaidee,male,,,,,\n", dto.PatientImportQueryDto{})
+	admin := asRole(uuid.NewString(), constants.RoleAdmin)
+	login := &dto.PatientLoginRequestDto{HospitalID: "HN-1", Password: testPassword}
+
+	_, err := f.service.PatientLogin(ctx, login)
+	assertCode(t, err, apperr.CodeUnauthorized)
+
+	if _, err := f.service.SetPatientPassword(admin, res.UserIDs[0], &dto.SetPatientPasswordRequestDto{Password: testPassword}); err != nil {
+		t.Fatalf("set password: %v", err)
+	}
+	if _, err := f.service.PatientLogin(ctx, login); err != nil {
+		t.Errorf("login with the new password: %v", err)
+	}
+
+	_, err = f.service.SetPatientPassword(admin, f.addDoctor(t, "dr.ploy"), &dto.SetPatientPasswordRequestDto{Password: testPassword})
+	assertCode(t, err, apperr.CodeNotFound)
+	_, err = f.service.SetPatientPassword(admin, "not-a-uuid", &dto.SetPatientPasswordRequestDto{Password: testPassword})
+	assertCode(t, err, apperr.CodeBadRequest)
+}
+
+func TestDoctorLogin(t *testing.T) {
+	f := newFixture(t)
+	userID := f.addDoctor(t, "dr.ploy")
+
+	res, err := f.service.DoctorLogin(context.Background(), &dto.DoctorLoginRequestDto{Username: "dr.ploy", Password: testPassword})
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	claims, err := f.jwt.Parse(res.AccessToken)
+	if err != nil {
+		t.Fatalf("parse token: %v", err)
+	}
+	if claims.UserID != userID || claims.Role != constants.RoleDoctor {
+		t.Errorf("claims = %+v, want user %s with role doctor", claims, userID)
+	}
+
+	_, err = f.service.DoctorLogin(context.Background(), &dto.DoctorLoginRequestDto{Username: "dr.ploy", Password: "wrong-password"})
+	assertCode(t, err, apperr.CodeUnauthorized)
+	_, err = f.service.DoctorLogin(context.Background(), &dto.DoctorLoginRequestDto{Username: "dr.nobody", Password: testPassword})
+	assertCode(t, err, apperr.CodeUnauthorized)
+}
+
+func TestGetProfileByID(t *testing.T) {
+	f := newFixture(t)
+	userID := f.register(t, "HN0001", ptr(testIDCard))
+
+	profile, err := f.service.GetProfileByID(asUser(userID))
+	if err != nil {
+		t.Fatalf("get profile: %v", err)
+	}
+	if profile.ID != userID || profile.HospitalID != "HN0001" || profile.FirstName != "Somchai" {
+		t.Errorf("unexpected profile %+v", profile)
+	}
+	if profile.IDCardNumber == nil || *profile.IDCardNumber != testIDCard {
+		t.Errorf("id card number = %v, want %s", profile.IDCardNumber, testIDCard)
+	}
+
+	_, err = f.service.GetProfileByID(asUser(uuid.NewString()))
+	assertCode(t, err, apperr.CodeNotFound)
+}
+
+func (f *fixture) etag(t *testing.T, ctx context.Context) string {
+	t.Helper()
+	profile, err := f.service.GetProfileByID(ctx)
+	if err != nil {
+		t.Fatalf("get profile: %v", err)
+	}
+	return profile.ETag
+}
+
+func TestUpdateProfileByID(t *testing.T) {
+	f := newFixture(t)
+	userID := f.register(t, "HN0001", nil)
+	f.register(t, "HN0002", ptr(otherIDCard))
+	ctx := asUser(userID)
+
+	res, err := f.service.UpdateProfileByID(ctx, f.etag(t, ctx), &dto.UpdatePatientProfileRequestDto{
+		FirstName:    ptr("Somying"),
+		Address:      ptr("Bangkok"),
+		IDCardNumber: ptr(testIDCard),
+	})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	profile, err := f.service.GetProfileByID(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.FirstName != "Somying" || profile.LastName != "Jaidee" {
+		t.Errorf("name = %s %s, want Somying Jaidee", profile.FirstName, profile.LastName)
+	}
+	if profile.Address == nil || *profile.Address != "Bangkok" {
+		t.Errorf("address = %v, want Bangkok", profile.Address)
+	}
+	if profile.ETag != res.ETag {
+		t.Errorf("etag after update = %s, profile etag = %s", res.ETag, profile.ETag)
+	}
+
+	_, err = f.service.UpdateProfileByID(ctx, profile.ETag, &dto.UpdatePatientProfileRequestDto{
+		FirstName:    ptr("Changed"),
+		IDCardNumber: ptr(otherIDCard),
+	})
+	assertCode(t, err, apperr.CodeConflict)
+	profile, err = f.service.GetProfileByID(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.FirstName != "Somying" {
+		t.Errorf("rejected update was applied: first name %s", profile.FirstName)
+	}
+}
+
+func TestUpdateProfileByIDPreconditions(t *testing.T) {
+	f := newFixture(t)
+	ctx := asUser(f.register(t, "HN0001", nil))
+	stale := f.etag(t, ctx)
+
+	_, err := f.service.UpdateProfileByID(ctx, "", &dto.UpdatePatientProfileRequestDto{FirstName: ptr("Somying")})
+	assertCode(t, err, apperr.CodePreconditionRequired)
+
+	if _, err := f.service.UpdateProfileByID(ctx, stale, &dto.UpdatePatientProfileRequestDto{FirstName: ptr("Somying")}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	_, err = f.service.UpdateProfileByID(ctx, stale, &dto.UpdatePatientProfileRequestDto{LastName: ptr("Overwritten")})
+	assertCode(t, err, apperr.CodePreconditionFailed)
+
+	current := f.etag(t, ctx)
+	res, err := f.service.UpdateProfileByID(ctx, `W/"0-0", `+current, &dto.UpdatePatientProfileRequestDto{FirstName: ptr("Somying")})
+	if err != nil {
+		t.Fatalf("update with matching etag in list: %v", err)
+	}
+	if res.ETag != current {
+		t.Errorf("unchanged update moved etag from %s to %s", current, res.ETag)
+	}
+}
+
+func TestProfileHistory(t *testing.T) {
+	f := newFixture(t)
+	userID := f.register(t, "HN0001", nil)
+	otherID := f.register(t, "HN0002", nil)
+	doctorID := f.addDoctor(t, "dr.ploy")
+	ctx := asUser(userID)
+
+	if _, err := f.service.UpdateProfileByID(ctx, f.etag(t, ctx), &dto.UpdatePatientProfileRequestDto{
+		FirstName: ptr("Somchai"),
+		Allergies: ptr("Penicillin"),
+	}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if _, err := f.service.UpdateProfileByID(ctx, f.etag(t, ctx), &dto.UpdatePatientProfileRequestDto{
+		Allergies: ptr("Penicillin, latex"),
+		BloodType: ptr("O+"),
+	}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	history, err := f.service.GetProfileHistory(asRole(doctorID, constants.RoleDoctor), userID, &dto.PageQueryDto{})
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	if history.Total != 3 {
+		t.Fatalf("total = %d, want 3 (unchanged first name not recorded)", history.Total)
+	}
+	allergies := map[string][2]*string{}
+	for _, change := range history.Items {
+		if change.ActorID == nil || *change.ActorID != userID || change.ActorRole != constants.RolePatient {
+			t.Errorf("change %s has actor %v/%s, want the patient", change.Field, change.ActorID, change.ActorRole)
+		}
+		if change.Field == "allergies" {
+			allergies[deref(change.NewValue)] = [2]*string{change.OldValue, change.NewValue}
+		}
+	}
+	if first, ok := allergies["Penicillin"]; !ok || first[0] != nil {
+		t.Errorf("first allergies change = %v, want nil -> Penicillin", first)
+	}
+	if second, ok := allergies["Penicillin, latex"]; !ok || deref(second[0]) != "Penicillin" {
+		t.Errorf("second allergies change = %v, want Penicillin -> Penicillin, latex", second)
+	}
+
+	if _, err := f.service.GetProfileHistory(ctx, userID, &dto.PageQueryDto{}); err != nil {
+		t.Errorf("own history: %v", err)
+	}
+	_, err = f.service.GetProfileHistory(asUser(otherID), userID, &dto.PageQueryDto{})
+	assertCode(t, err, apperr.CodeForbidden)
+	_, err = f.service.GetProfileHistory(asRole(doctorID, constants.RoleDoctor), uuid.NewString(), &dto.PageQueryDto{})
+	assertCode(t, err, apperr.CodeNotFound)
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return "<nil>"
+	}
+	return *s
+}
+
+func TestBatchLookups(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	patientID := f.register(t, "HN0001", nil)
+	deletedPatientID := f.register(t, "HN0002", nil)
+	doctorID := f.addDoctor(t, "dr.ploy")
+	if err := f.store.Users().Delete(ctx, deletedPatientID); err != nil {
+		t.Fatal(err)
+	}
+
+	ids := []string{patientID, deletedPatientID, doctorID, uuid.NewString()}
+
+	patients, err := f.service.GetPatientsByIDs(ctx, ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(patients) != 1 || patients[0].ID != patientID || patients[0].HospitalID != "HN0001" {
+		t.Errorf("patients = %+v, want only %s", patients, patientID)
+	}
+
+	doctors, err := f.service.GetDoctorsByIDs(ctx, ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doctors) != 1 || doctors[0].ID != doctorID || doctors[0].Username != "dr.ploy" {
+		t.Errorf("doctors = %+v, want only %s", doctors, doctorID)
+	}
+
+	empty, err := f.service.GetPatientsByIDs(ctx, nil)
+	if err != nil || len(empty) != 0 {
+		t.Errorf("empty lookup = %v, %v", empty, err)
+	}
+}
+
+func TestSearchPatients(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	firstID := f.register(t, "HN0001", nil)
+	f.register(t, "HN0002", nil)
+	deletedID := f.register(t, "HN0003", nil)
+	if err := f.store.Users().Delete(ctx, deletedID); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, tc := range map[string]struct {
+		query dto.SearchRequestDto
+		want  int
+	}{
+		"hospital ID":        {dto.SearchRequestDto{Query: "HN0001"}, 1},
+		"hospital ID prefix": {dto.SearchRequestDto{Query: "hn00"}, 2},
+		"name":               {dto.SearchRequestDto{Query: "somchai"}, 2},
+		"limit":              {dto.SearchRequestDto{Query: "Jaidee", Limit: 1}, 1},
+		"no match":           {dto.SearchRequestDto{Query: "Kanya"}, 0},
+	} {
+		patients, err := f.service.SearchPatients(ctx, &tc.query)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(patients) != tc.want {
+			t.Errorf("%s: %d patients, want %d", name, len(patients), tc.want)
+		}
+	}
+
+	patients, err := f.service.SearchPatients(ctx, &dto.SearchRequestDto{Query: "HN0001"})
+	if err != nil || len(patients) != 1 || patients[0].ID != firstID {
+		t.Errorf("search = %+v (%v), want %s", patients, err, firstID)
+	}
+	_, err = f.service.SearchPatients(ctx, &dto.SearchRequestDto{Query: " H "})
+	assertCode(t, err, apperr.CodeBadRequest)
+}
@@ -0,0 +1,97 @@
+// Package tracing sets up OpenTelemetry tracing: the exporter, the global
+// tracer provider and W3C trace-context propagation.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"user-service/pkg/logging"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "user-service"
+
+type Config struct {
+	// Exporter is none, stdout or otlp. The stdout exporter writes to
+	// stderr, so spans do not interleave with the JSON logs on stdout. The
+	// OTLP exporter is configured through the standard OTEL_EXPORTER_OTLP_*
+	// variables and sampling through OTEL_TRACES_SAMPLER.
+	Exporter    string
+	ServiceName string
+}
+
+// Setup installs the global tracer provider and propagator. With the none
+// exporter spans are not recorded, but incoming trace context is still
+// passed on to outbound requests. Call the returned function on shutdown to
+// flush buffered spans.
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch strings.ToLower(cfg.Exporter) {
+	case "none", "":
+		return func(context.Context) error { return nil }, nil
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stderr))
+	case "otlp":
+		exporter, err = otlptracehttp.New(ctx)
+	default:
+		return nil, fmt.Errorf("invalid tracing exporter %q", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create %s exporter: %w", cfg.Exporter, err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("build resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Tracer returns the service's tracer from the global provider.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Start starts a span named name as a child of the span in ctx.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, opts...)
+}
+
+// End marks span as failed if err is set and ends it. The error message is
+// redacted like log output, since errors can quote user input. Defer it with
+// a pointer to the named error result:
+//
+//	ctx, span := tracing.Start(ctx, "UserService.Register")
+//	defer tracing.End(span, &err)
+func End(span trace.Span, err *error) {
+	if err != nil && *err != nil {
+		span.SetStatus(codes.Error, logging.RedactString((*err).Error()))
+	}
+	span.End()
+}
@@ -0,0 +1,53 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"user-service/pkg/tracing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestEndRedactsError(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	func() (err error) {
+		_, span := tracing.Start(context.Background(), "fails")
+		defer tracing.End(span, &err)
+		return errors.New("id card 1101700203450 already registered")
+	}()
+	func() (err error) {
+		_, span := tracing.Start(context.Background(), "succeeds")
+		defer tracing.End(span, &err)
+		return nil
+	}()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("ended %d spans, want 2", len(spans))
+	}
+	if status := spans[0].Status(); status.Code != codes.Error || status.Description != "id card [REDACTED] already registered" {
+		t.Errorf("failed span status = %+v, want an error with the ID card redacted", status)
+	}
+	if status := spans[1].Status(); status.Code != codes.Unset {
+		t.Errorf("succeeded span status = %+v, want unset", status)
+	}
+}
+
+func TestSetupRejectsUnknownExporter(t *testing.T) {
+	if _, err := tracing.Setup(context.Background(), tracing.Config{Exporter: "jaeger"}); err == nil {
+		t.Error("setup with an unknown exporter succeeded")
+	}
+	shutdown, err := tracing.Setup(context.Background(), tracing.Config{Exporter: "none"})
+	if err != nil {
+		t.Fatalf("setup without an exporter: %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown: %v", err)
+	}
+}
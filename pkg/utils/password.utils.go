@@ -17,6 +17,10 @@ const (
 	KeyLength   = 32
 )
 
+// LockedPassword is stored for accounts that have no password yet, such as
+// imported patients. It is not a valid hash, so it never verifies.
+const LockedPassword = "!"
+
 func GenerateRandomByte(n uint) ([]byte, error) {
 	b := make([]byte, n)
 	_, err := rand.Read(b)
@@ -42,8 +46,11 @@ func HashPassword(password string) (string, error) {
 }
 
 func VerifyPassword(password, encodedHash string) (bool, error) {
-	dbPasswordHash := strings.Split(encodedHash, "$")[5]
 	vals := strings.Split(encodedHash, "$")
+	if len(vals) != 6 {
+		return false, fmt.Errorf("malformed password hash")
+	}
+	dbPasswordHash := vals[5]
 	decodedSalt, err := base64.RawStdEncoding.DecodeString(vals[4])
 	if err != nil {
 		return false, err
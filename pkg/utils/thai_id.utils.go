@@ -0,0 +1,23 @@
+package utils
+
+// IsValidThaiIDCardNumber reports whether s is a 13-digit Thai national ID
+// number with a correct mod-11 check digit: each of the first 12 digits is
+// weighted 13 down to 2, and the last digit equals (11 - sum%11) % 10.
+func IsValidThaiIDCardNumber(s string) bool {
+	if len(s) != 13 {
+		return false
+	}
+	sum := 0
+	for i := 0; i < 12; i++ {
+		d := s[i] - '0'
+		if d > 9 {
+			return false
+		}
+		sum += int(d) * (13 - i)
+	}
+	check := s[12] - '0'
+	if check > 9 {
+		return false
+	}
+	return (11-sum%11)%10 == int(check)
+}
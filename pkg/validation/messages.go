@@ -0,0 +1,62 @@
+package validation
+
+import (
+	"reflect"
+	"strings"
+)
+
+type template struct {
+	en, th string
+}
+
+// messages maps validation rules to message templates; {field} and {param}
+// are substituted. Length rules have separate wording for collections.
+var messages = map[string]template{
+	"required":    {"{field} is required", "ต้องระบุ {field}"},
+	"len":         {"{field} must be exactly {param} characters long", "{field} ต้องมีความยาว {param} ตัวอักษร"},
+	"min":         {"{field} must be at least {param} characters long", "{field} ต้องมีอย่างน้อย {param} ตัวอักษร"},
+	"max":         {"{field} must be at most {param} characters long", "{field} ต้องมีไม่เกิน {param} ตัวอักษร"},
+	"numeric":     {"{field} must contain only digits", "{field} ต้องเป็นตัวเลขเท่านั้น"},
+	"oneof":       {"{field} must be one of: {param}", "{field} ต้องเป็นค่าใดค่าหนึ่งต่อไปนี้: {param}"},
+	"uuid":        {"{field} must be a valid UUID", "{field} ต้องเป็น UUID ที่ถูกต้อง"},
+	"email":       {"{field} must be a valid email address", "{field} ต้องเป็นอีเมลที่ถูกต้อง"},
+	"thai_id":     {"{field} must be a valid Thai national ID number", "{field} ไม่ใช่เลขประจำตัวประชาชนที่ถูกต้อง"},
+	"unique":      {"{field} is already registered", "{field} นี้ถูกใช้งานแล้ว"},
+	"exists":      {"{field} refers to a record that does not exist", "ไม่พบข้อมูลที่ {field} อ้างถึง"},
+	"type":        {"{field} must be of type {param}", "{field} ต้องเป็นชนิดข้อมูล {param}"},
+	"unknown":     {"{field} is not an allowed field", "ไม่อนุญาตให้ส่งฟิลด์ {field}"},
+	"min_items":   {"{field} must contain at least {param} items", "{field} ต้องมีอย่างน้อย {param} รายการ"},
+	"max_items":   {"{field} must contain at most {param} items", "{field} ต้องมีไม่เกิน {param} รายการ"},
+	"len_items":   {"{field} must contain exactly {param} items", "{field} ต้องมี {param} รายการพอดี"},
+	"invalid":     {"{field} is invalid", "{field} ไม่ถูกต้อง"},
+	"numeric_min": {"{field} must be at least {param}", "{field} ต้องมีค่าอย่างน้อย {param}"},
+	"numeric_max": {"{field} must be at most {param}", "{field} ต้องมีค่าไม่เกิน {param}"},
+	"numeric_len": {"{field} must equal {param}", "{field} ต้องมีค่าเท่ากับ {param}"},
+}
+
+func message(field, rule, param string, kind reflect.Kind) Message {
+	t, ok := messages[variant(rule, kind)]
+	if !ok {
+		t = messages["invalid"]
+	}
+	r := strings.NewReplacer("{field}", field, "{param}", param)
+	return Message{EN: r.Replace(t.en), TH: r.Replace(t.th)}
+}
+
+// variant picks the collection or numeric wording for length rules.
+func variant(rule string, kind reflect.Kind) string {
+	switch rule {
+	case "min", "max", "len":
+	default:
+		return rule
+	}
+	switch kind {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return rule + "_items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "numeric_" + rule
+	}
+	return rule
+}
@@ -0,0 +1,81 @@
+// Package validation builds the request validator shared by the JSON decoder
+// and bulk imports, and turns its errors into per-field descriptions.
+package validation
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"user-service/pkg/utils"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError describes why one request field was rejected.
+type FieldError struct {
+	Field   string  `json:"field"`
+	Rule    string  `json:"rule"`
+	Param   string  `json:"param,omitempty"`
+	Message Message `json:"message"`
+}
+
+// Message is a human readable explanation in English and Thai.
+type Message struct {
+	EN string `json:"en"`
+	TH string `json:"th"`
+}
+
+// New returns a validator that reports fields by their JSON names and knows
+// the custom rules used by the DTOs (thai_id).
+func New() *validator.Validate {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return f.Name
+		}
+		return name
+	})
+	_ = validate.RegisterValidation("thai_id", func(fl validator.FieldLevel) bool {
+		return utils.IsValidThaiIDCardNumber(fl.Field().String())
+	})
+	return validate
+}
+
+// Describe converts validator errors into field errors. It returns nil if
+// err does not come from the validator.
+func Describe(err error) []FieldError {
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return nil
+	}
+	fields := make([]FieldError, 0, len(verrs))
+	for _, fe := range verrs {
+		fields = append(fields, NewFieldError(fieldPath(fe), fe.Tag(), fe.Param(), fe.Kind()))
+	}
+	return fields
+}
+
+// NewFieldError builds a field error for rule, with messages from the
+// catalog. kind selects wording for length rules (characters vs. items).
+func NewFieldError(field, rule, param string, kind reflect.Kind) FieldError {
+	return FieldError{
+		Field:   field,
+		Rule:    rule,
+		Param:   param,
+		Message: message(field, rule, param, kind),
+	}
+}
+
+// fieldPath strips the top-level struct name from the namespace, so nested
+// and slice fields read like "doctor_ids[0]".
+func fieldPath(fe validator.FieldError) string {
+	_, path, found := strings.Cut(fe.Namespace(), ".")
+	if !found {
+		return fe.Field()
+	}
+	return path
+}
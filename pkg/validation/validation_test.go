@@ -0,0 +1,39 @@
+package validation_test
+
+import (
+	"testing"
+	"user-service/pkg/validation"
+)
+
+type idCardRequest struct {
+	IDCardNumber string `json:"id_card_number" validate:"thai_id"`
+}
+
+func TestThaiID(t *testing.T) {
+	validate := validation.New()
+	for idCard, valid := range map[string]bool{
+		"1101700203450":  true,
+		"3101001234565":  true,
+		"1234567890121":  true,
+		"0000000000001":  true,
+		"1101700203451":  false, // wrong check digit
+		"3101001234560":  false,
+		"1234567890123":  false,
+		"0000000000000":  false,
+		"110170020345":   false, // 12 digits
+		"11017002034500": false, // 14 digits
+		"110170020345a":  false,
+		"":               false,
+	} {
+		err := validate.Struct(idCardRequest{IDCardNumber: idCard})
+		if valid && err != nil {
+			t.Errorf("%q: %v, want valid", idCard, err)
+		}
+		if !valid {
+			fields := validation.Describe(err)
+			if len(fields) != 1 || fields[0].Field != "id_card_number" || fields[0].Rule != "thai_id" {
+				t.Errorf("%q: errors = %+v, want a thai_id error on id_card_number", idCard, fields)
+			}
+		}
+	}
+}